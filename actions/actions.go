@@ -0,0 +1,289 @@
+package actions
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/caddyserver/caddy"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// ParamType is the type of the value of a parameter.
+type ParamType string
+
+const (
+	ParamString  ParamType = "string"
+	ParamNumber  ParamType = "number"
+	ParamBoolean ParamType = "boolean"
+	ParamChoice  ParamType = "choice"
+)
+
+// Placeholders of the templates that are replaced by the file an action
+// is run on: its full path, its name, its name without extension and
+// the full path of its directory.
+const (
+	PlaceholderFile = "file"
+	PlaceholderName = "name"
+	PlaceholderStem = "stem"
+	PlaceholderDir  = "dir"
+)
+
+var (
+	placeholderRe = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+	paramNameRe   = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Action is a command predefined by the administrators which the users
+// can run on their files. Command is a template, such as
+// "ffmpeg -i {file} -crf {crf} {dir}/{stem}.mp4", whose placeholders are
+// replaced by the file and by the values of the parameters. It is split
+// in arguments before the replacements, which are never interpreted by
+// a shell. An action without groups can be run by all the users allowed
+// to execute commands.
+type Action struct {
+	ID          uint    `storm:"id,increment" json:"id"`
+	Name        string  `storm:"unique" json:"name"`
+	Description string  `json:"description"`
+	Command     string  `json:"command"`
+	Params      []Param `json:"params"`
+	Groups      []uint  `json:"groups"`
+}
+
+// Param is a parameter of an action. The values of the string parameters
+// must match Pattern, if set, and the ones of the choice parameters must
+// be one of Choices. Without a pattern, the values of the string
+// parameters can't start with a dash, as the commands would take them
+// as options, unless AllowOptions is set.
+type Param struct {
+	Name         string    `json:"name"`
+	Label        string    `json:"label"`
+	Type         ParamType `json:"type"`
+	Default      string    `json:"default"`
+	Required     bool      `json:"required"`
+	Choices      []string  `json:"choices"`
+	Pattern      string    `json:"pattern"`
+	AllowOptions bool      `json:"allowOptions"`
+}
+
+// Clean verifies if the action is alright to be saved.
+func (a *Action) Clean() error {
+	if a.Name == "" {
+		return errors.ErrEmptyName
+	}
+
+	args, err := a.split()
+	if err != nil {
+		return err
+	}
+
+	if placeholderRe.MatchString(args[0]) {
+		return fmt.Errorf("%w: the command can't be a placeholder", errors.ErrInvalidAction)
+	}
+
+	if a.Params == nil {
+		a.Params = []Param{}
+	}
+
+	if a.Groups == nil {
+		a.Groups = []uint{}
+	}
+
+	known := map[string]bool{
+		PlaceholderFile: true,
+		PlaceholderName: true,
+		PlaceholderStem: true,
+		PlaceholderDir:  true,
+	}
+
+	for i := range a.Params {
+		p := &a.Params[i]
+		if err := p.clean(); err != nil { //nolint:shadow
+			return err
+		}
+
+		if known[p.Name] {
+			return fmt.Errorf("%w: parameter %q is defined twice or reserved", errors.ErrInvalidAction, p.Name)
+		}
+		known[p.Name] = true
+	}
+
+	for _, arg := range args {
+		for _, m := range placeholderRe.FindAllStringSubmatch(arg, -1) {
+			if !known[m[1]] {
+				return fmt.Errorf("%w: unknown placeholder {%s}", errors.ErrInvalidAction, m[1])
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a *Action) split() ([]string, error) {
+	name, args, err := caddy.SplitCommandAndArgs(a.Command)
+	if err != nil || name == "" {
+		return nil, fmt.Errorf("%w: invalid command", errors.ErrInvalidAction)
+	}
+
+	return append([]string{name}, args...), nil
+}
+
+func (p *Param) clean() error {
+	if !paramNameRe.MatchString(p.Name) {
+		return fmt.Errorf("%w: invalid parameter name %q", errors.ErrInvalidAction, p.Name)
+	}
+
+	if p.Type == "" {
+		p.Type = ParamString
+	}
+
+	switch p.Type {
+	case ParamString, ParamNumber, ParamBoolean:
+	case ParamChoice:
+		if len(p.Choices) == 0 {
+			return fmt.Errorf("%w: parameter %q has no choices", errors.ErrInvalidAction, p.Name)
+		}
+	default:
+		return fmt.Errorf("%w: parameter %q has an unknown type", errors.ErrInvalidAction, p.Name)
+	}
+
+	if p.Pattern != "" {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			return fmt.Errorf("%w: parameter %q has an invalid pattern", errors.ErrInvalidAction, p.Name)
+		}
+	}
+
+	if p.Choices == nil {
+		p.Choices = []string{}
+	}
+
+	if p.Default != "" {
+		if err := p.check(p.Default); err != nil {
+			return fmt.Errorf("%w: invalid default value of parameter %q", errors.ErrInvalidAction, p.Name)
+		}
+	}
+
+	return nil
+}
+
+// check verifies that a value suits the parameter.
+func (p *Param) check(value string) error {
+	valid := true
+	switch p.Type {
+	case ParamNumber:
+		_, err := strconv.ParseFloat(value, 64)
+		valid = err == nil
+	case ParamBoolean:
+		valid = value == "true" || value == "false"
+	case ParamChoice:
+		valid = false
+		for _, c := range p.Choices {
+			valid = valid || c == value
+		}
+	case ParamString:
+		if p.Pattern != "" {
+			valid = regexp.MustCompile(p.Pattern).MatchString(value)
+		} else {
+			valid = p.AllowOptions || !strings.HasPrefix(value, "-")
+		}
+	}
+
+	if !valid {
+		return fmt.Errorf("%w: invalid value of parameter %q", errors.ErrInvalidActionParam, p.Name)
+	}
+
+	return nil
+}
+
+// Allowed checks if a user can run the action.
+func (a *Action) Allowed(u *users.User) bool {
+	if !u.Perm.Execute {
+		return false
+	}
+
+	if len(a.Groups) == 0 {
+		return true
+	}
+
+	for _, id := range a.Groups {
+		if u.InGroup(id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Resolve verifies the values given to the parameters of the action,
+// defaulting the missing ones, and returns the values of all of them.
+func (a *Action) Resolve(given map[string]string) (map[string]string, error) {
+	values := map[string]string{}
+	for name := range given {
+		if a.param(name) == nil {
+			return nil, fmt.Errorf("%w: unknown parameter %q", errors.ErrInvalidActionParam, name)
+		}
+	}
+
+	for i := range a.Params {
+		p := &a.Params[i]
+		value, ok := given[p.Name]
+		if !ok || value == "" {
+			if p.Required && p.Default == "" {
+				return nil, fmt.Errorf("%w: parameter %q is required", errors.ErrInvalidActionParam, p.Name)
+			}
+
+			values[p.Name] = p.Default
+			continue
+		}
+
+		if err := p.check(value); err != nil {
+			return nil, err
+		}
+		values[p.Name] = value
+	}
+
+	return values, nil
+}
+
+func (a *Action) param(name string) *Param {
+	for i := range a.Params {
+		if a.Params[i].Name == name {
+			return &a.Params[i]
+		}
+	}
+
+	return nil
+}
+
+// Args builds the arguments of the command of the action for a file,
+// given by its full path, with the resolved values of the parameters.
+func (a *Action) Args(file string, values map[string]string) ([]string, error) {
+	args, err := a.split()
+	if err != nil {
+		return nil, err
+	}
+
+	name := filepath.Base(file)
+	vars := map[string]string{
+		PlaceholderFile: file,
+		PlaceholderName: name,
+		PlaceholderStem: strings.TrimSuffix(name, filepath.Ext(name)),
+		PlaceholderDir:  filepath.Dir(file),
+	}
+
+	for k, v := range values {
+		vars[k] = v
+	}
+
+	for i, arg := range args {
+		args[i] = placeholderRe.ReplaceAllStringFunc(arg, func(m string) string {
+			return vars[m[1:len(m)-1]]
+		})
+	}
+
+	return args, nil
+}
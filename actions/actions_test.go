@@ -0,0 +1,61 @@
+package actions
+
+import (
+	"errors"
+	"testing"
+
+	libErrors "github.com/filebrowser/filebrowser/v2/errors"
+)
+
+func TestResolve(t *testing.T) {
+	a := &Action{
+		Name:    "convert",
+		Command: "convert {file} {args} {format} {crf} {label} {dir}/{stem}.out",
+		Params: []Param{
+			{Name: "args", AllowOptions: true},
+			{Name: "format"},
+			{Name: "crf", Type: ParamNumber, Default: "23"},
+			{Name: "label", Pattern: "^-?[a-z]+$"},
+		},
+	}
+
+	if err := a.Clean(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		params map[string]string
+		valid  bool
+	}{
+		{map[string]string{"format": "mp4"}, true},
+		{map[string]string{"format": "-o/etc/passwd"}, false},
+		{map[string]string{"format": "--output=x"}, false},
+		{map[string]string{"args": "-v"}, true},
+		{map[string]string{"label": "-abc"}, true},
+		{map[string]string{"crf": "-1"}, true},
+		{map[string]string{"unknown": "x"}, false},
+	}
+
+	for _, tt := range tests {
+		_, err := a.Resolve(tt.params)
+		if tt.valid && err != nil {
+			t.Errorf("%v: unexpected error %v", tt.params, err)
+		}
+
+		if !tt.valid && !errors.Is(err, libErrors.ErrInvalidActionParam) {
+			t.Errorf("%v: got error %v, want %v", tt.params, err, libErrors.ErrInvalidActionParam)
+		}
+	}
+}
+
+func TestCleanRejectsOptionDefault(t *testing.T) {
+	a := &Action{
+		Name:    "convert",
+		Command: "convert {file} {format}",
+		Params:  []Param{{Name: "format", Default: "-x"}},
+	}
+
+	if err := a.Clean(); !errors.Is(err, libErrors.ErrInvalidAction) {
+		t.Errorf("got error %v, want %v", err, libErrors.ErrInvalidAction)
+	}
+}
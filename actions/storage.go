@@ -0,0 +1,67 @@
+package actions
+
+import (
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// StorageBackend is the interface to implement for an actions storage.
+type StorageBackend interface {
+	GetBy(interface{}) (*Action, error)
+	Gets() ([]*Action, error)
+	Save(a *Action) error
+	DeleteByID(uint) error
+}
+
+// Storage is an actions storage.
+type Storage struct {
+	back StorageBackend
+}
+
+// NewStorage creates an actions storage from a backend.
+func NewStorage(back StorageBackend) *Storage {
+	return &Storage{back: back}
+}
+
+// Get allows you to get an action by its name or its ID. The provided id
+// must be a string for name lookup or a uint for id lookup. If id is
+// neither, a ErrInvalidDataType will be returned.
+func (s *Storage) Get(id interface{}) (*Action, error) {
+	return s.back.GetBy(id)
+}
+
+// Gets gets all the actions.
+func (s *Storage) Gets() ([]*Action, error) {
+	return s.back.Gets()
+}
+
+// ForUser gets the actions the user can run.
+func (s *Storage) ForUser(u *users.User) ([]*Action, error) {
+	all, err := s.back.Gets()
+	if err != nil && err != errors.ErrNotExist {
+		return nil, err
+	}
+
+	list := []*Action{}
+	for _, a := range all {
+		if a.Allowed(u) {
+			list = append(list, a)
+		}
+	}
+
+	return list, nil
+}
+
+// Save saves the action in a storage.
+func (s *Storage) Save(a *Action) error {
+	if err := a.Clean(); err != nil {
+		return err
+	}
+
+	return s.back.Save(a)
+}
+
+// Delete deletes an action by its ID.
+func (s *Storage) Delete(id uint) error {
+	return s.back.DeleteByID(id)
+}
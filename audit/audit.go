@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"strings"
+)
+
+// Actions recorded in the audit log. The actions on the same kind of
+// object share the prefix before the dot.
+const (
+	ActionLogin = "login"
+
+	ActionFileCreate   = "file.create"
+	ActionFileModify   = "file.modify"
+	ActionFileDelete   = "file.delete"
+	ActionFileRename   = "file.rename"
+	ActionFileCopy     = "file.copy"
+	ActionFileDownload = "file.download"
+	ActionFileLock     = "file.lock"
+	ActionFileUnlock   = "file.unlock"
+	ActionFileTail     = "file.tail"
+
+	ActionShareCreate = "share.create"
+	ActionShareDelete = "share.delete"
+
+	ActionCommandRun = "command.run"
+
+	ActionUserCreate   = "user.create"
+	ActionUserUpdate   = "user.update"
+	ActionUserDelete   = "user.delete"
+	ActionUserPassword = "user.password"
+
+	ActionGroupCreate = "group.create"
+	ActionGroupUpdate = "group.update"
+	ActionGroupDelete = "group.delete"
+
+	ActionTokenCreate = "token.create"
+	ActionTokenDelete = "token.delete"
+
+	ActionWebhookCreate = "webhook.create"
+	ActionWebhookUpdate = "webhook.update"
+	ActionWebhookDelete = "webhook.delete"
+
+	ActionActionCreate = "action.create"
+	ActionActionUpdate = "action.update"
+	ActionActionDelete = "action.delete"
+	ActionActionRun    = "action.run"
+
+	ActionSettingsUpdate = "settings.update"
+)
+
+// Results of the recorded actions.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultDenied  = "denied"
+)
+
+// Entry is a record of the audit log. Source and Destination are the
+// paths, relative to the scope of the user, or the names of the objects
+// the action was done on. Bytes is the number of bytes of the files that
+// were uploaded or downloaded.
+type Entry struct {
+	ID          uint   `json:"id" storm:"id,increment"`
+	Time        int64  `json:"time" storm:"index"`
+	Username    string `json:"username" storm:"index"`
+	IP          string `json:"ip"`
+	Action      string `json:"action" storm:"index"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Bytes       int64  `json:"bytes,omitempty"`
+	Result      string `json:"result"`
+	Details     string `json:"details,omitempty"`
+}
+
+// Query filters the entries of the audit log. The empty fields don't
+// filter anything. Action matches either an action or, if it has no
+// dot, all the actions of the kind. Path matches the entries whose
+// source or destination contains it.
+type Query struct {
+	Username string
+	Action   string
+	Result   string
+	Path     string
+	Since    int64
+	Until    int64
+	Limit    int
+	Offset   int
+}
+
+// Matches checks if an entry matches the query, its limit and
+// offset apart.
+func (q *Query) Matches(e *Entry) bool {
+	if q.Username != "" && e.Username != q.Username {
+		return false
+	}
+
+	if q.Action != "" && e.Action != q.Action &&
+		(strings.Contains(q.Action, ".") || !strings.HasPrefix(e.Action, q.Action+".")) {
+		return false
+	}
+
+	if q.Result != "" && e.Result != q.Result {
+		return false
+	}
+
+	if q.Path != "" && !strings.Contains(e.Source, q.Path) && !strings.Contains(e.Destination, q.Path) {
+		return false
+	}
+
+	if q.Since != 0 && e.Time < q.Since {
+		return false
+	}
+
+	if q.Until != 0 && e.Time > q.Until {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"time"
+)
+
+// StorageBackend is the interface to implement for an audit log storage.
+type StorageBackend interface {
+	Save(e *Entry) error
+	Find(q *Query) ([]*Entry, error)
+	DeleteBefore(time int64) (int, error)
+}
+
+// Storage is an audit log storage.
+type Storage struct {
+	back StorageBackend
+}
+
+// NewStorage creates an audit log storage from a backend.
+func NewStorage(back StorageBackend) *Storage {
+	return &Storage{back: back}
+}
+
+// Record saves an entry in the audit log, timestamping it
+// if it has no time.
+func (s *Storage) Record(e *Entry) error {
+	if e.Time == 0 {
+		e.Time = time.Now().Unix()
+	}
+
+	return s.back.Save(e)
+}
+
+// Find gets the entries matching the query, the newest first.
+func (s *Storage) Find(q *Query) ([]*Entry, error) {
+	return s.back.Find(q)
+}
+
+// Prune deletes the entries older than the given unix time and
+// returns how many were deleted.
+func (s *Storage) Prune(before int64) (int, error) {
+	return s.back.DeleteBefore(before)
+}
@@ -3,13 +3,21 @@ package auth
 import (
 	"net/http"
 
+	"github.com/filebrowser/filebrowser/v2/settings"
 	"github.com/filebrowser/filebrowser/v2/users"
 )
 
 // Auther is the authentication interface.
 type Auther interface {
 	// Auth is called to authenticate a request.
-	Auth(r *http.Request, s *users.Storage, root string) (*users.User, error)
+	Auth(r *http.Request, usr *users.Storage, stg *settings.Settings, srv *settings.Server) (*users.User, error)
 	// LoginPage indicates if this auther needs a login page.
 	LoginPage() bool
 }
+
+// Redirecter is implemented by the authers that delegate the login
+// to an external provider. The browser is sent to the URL returned by
+// LoginURL and comes back to the callback endpoint, where Auth is called.
+type Redirecter interface {
+	LoginURL(w http.ResponseWriter, r *http.Request, stg *settings.Settings, srv *settings.Server) (string, error)
+}
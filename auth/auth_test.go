@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// memUsers is an in memory users.StorageBackend for the tests.
+type memUsers struct {
+	list []users.User
+}
+
+func newUsersStorage() *users.Storage {
+	return users.NewStorage(&memUsers{})
+}
+
+func (m *memUsers) GetBy(i interface{}) (*users.User, error) {
+	for _, u := range m.list {
+		if id, ok := i.(uint); ok && u.ID == id {
+			return &u, nil
+		}
+
+		if username, ok := i.(string); ok && u.Username == username {
+			return &u, nil
+		}
+	}
+
+	return nil, errors.ErrNotExist
+}
+
+func (m *memUsers) Gets() ([]*users.User, error) {
+	list := make([]*users.User, 0, len(m.list))
+	for _, u := range m.list {
+		u := u
+		list = append(list, &u)
+	}
+
+	return list, nil
+}
+
+func (m *memUsers) Save(u *users.User) error {
+	for i, v := range m.list {
+		if v.Username == u.Username && v.ID != u.ID {
+			return errors.ErrExist
+		}
+
+		if u.ID != 0 && v.ID == u.ID {
+			m.list[i] = *u
+			return nil
+		}
+	}
+
+	u.ID = uint(len(m.list) + 1)
+	m.list = append(m.list, *u)
+	return nil
+}
+
+func (m *memUsers) Update(u *users.User, _ ...string) error {
+	return m.Save(u)
+}
+
+func (m *memUsers) DeleteByID(id uint) error {
+	for i, u := range m.list {
+		if u.ID == id {
+			m.list = append(m.list[:i], m.list[i+1:]...)
+			return nil
+		}
+	}
+
+	return errors.ErrNotExist
+}
+
+func (m *memUsers) DeleteByUsername(username string) error {
+	u, err := m.GetBy(username)
+	if err != nil {
+		return err
+	}
+
+	return m.DeleteByID(u.ID)
+}
@@ -6,7 +6,9 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 
+	"github.com/filebrowser/filebrowser/v2/errors"
 	"github.com/filebrowser/filebrowser/v2/settings"
 	"github.com/filebrowser/filebrowser/v2/users"
 )
@@ -14,10 +16,15 @@ import (
 // MethodJSONAuth is used to identify json auth.
 const MethodJSONAuth settings.AuthMethod = "json"
 
+// secondFactorMux serializes the checks of the second factor codes,
+// which can only be used once.
+var secondFactorMux sync.Mutex
+
 type jsonCred struct {
 	Password  string `json:"password"`
 	Username  string `json:"username"`
 	ReCaptcha string `json:"recaptcha"`
+	OTP       string `json:"otp"`
 }
 
 // JSONAuth is a json implementation of an Auther.
@@ -25,8 +32,10 @@ type JSONAuth struct {
 	ReCaptcha *ReCaptcha `json:"recaptcha" yaml:"recaptcha"`
 }
 
-// Auth authenticates the user via a json in content body.
-func (a JSONAuth) Auth(r *http.Request, sto *users.Storage, root string) (*users.User, error) {
+// Auth authenticates the user via a json in content body. If the user
+// has enabled two-factor authentication, the body must also contain a
+// TOTP or a recovery code, otherwise errors.ErrTOTPRequired is returned.
+func (a JSONAuth) Auth(r *http.Request, sto *users.Storage, _ *settings.Settings, srv *settings.Server) (*users.User, error) {
 	var cred jsonCred
 
 	if r.Body == nil {
@@ -51,11 +60,45 @@ func (a JSONAuth) Auth(r *http.Request, sto *users.Storage, root string) (*users
 		}
 	}
 
-	u, err := sto.Get(root, cred.Username)
+	u, err := sto.Get(srv.Root, cred.Username)
 	if err != nil || !users.CheckPwd(cred.Password, u.Password) {
 		return nil, os.ErrPermission
 	}
 
+	if !u.TOTPEnabled {
+		return u, nil
+	}
+
+	if cred.OTP == "" {
+		return nil, errors.ErrTOTPRequired
+	}
+
+	// The user is read again under the lock so that a code can't be
+	// used by two logins at once.
+	secondFactorMux.Lock()
+	defer secondFactorMux.Unlock()
+
+	u, err = sto.Get(srv.Root, u.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, recovery := u.CheckSecondFactor(cred.OTP)
+	if !ok {
+		return nil, os.ErrPermission
+	}
+
+	// The codes can only be used once.
+	if recovery {
+		err = sto.Update(u, "RecoveryCodes")
+	} else {
+		err = sto.Update(u, "TOTPLastStep")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
 	return u, nil
 }
 
@@ -0,0 +1,322 @@
+package auth
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/settings"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// MethodLDAPAuth is used to identify LDAP auth.
+const MethodLDAPAuth settings.AuthMethod = "ldap"
+
+const (
+	ldapTimeout = 10 * time.Second
+
+	// DefaultLDAPGroupAttribute is the attribute holding the groups of
+	// an user when none is configured.
+	DefaultLDAPGroupAttribute = "memberOf"
+)
+
+// LDAPAuth is an LDAP implementation of an Auther. The user is found either
+// by replacing {username} in UserDN or, if UserFilter is set, by searching
+// BaseDN with the service account, and then authenticated with a bind.
+type LDAPAuth struct {
+	URL                string      `json:"url" yaml:"url"`
+	StartTLS           bool        `json:"startTLS" yaml:"startTLS"`
+	InsecureSkipVerify bool        `json:"insecureSkipVerify" yaml:"insecureSkipVerify"`
+	UserDN             string      `json:"userDN" yaml:"userDN"`
+	BindDN             string      `json:"bindDN" yaml:"bindDN"`
+	BindPassword       string      `json:"bindPassword" yaml:"bindPassword"`
+	BaseDN             string      `json:"baseDN" yaml:"baseDN"`
+	UserFilter         string      `json:"userFilter" yaml:"userFilter"`
+	GroupAttribute     string      `json:"groupAttribute" yaml:"groupAttribute"`
+	CreateUser         bool        `json:"createUser" yaml:"createUser"`
+	Groups             []LDAPGroup `json:"groups" yaml:"groups"`
+}
+
+// LDAPGroup maps the members of an LDAP group to a set of
+// permissions and, optionally, to a scope.
+type LDAPGroup struct {
+	DN    string            `json:"dn" yaml:"dn"`
+	Perm  users.Permissions `json:"perm" yaml:"perm"`
+	Scope string            `json:"scope" yaml:"scope"`
+}
+
+// Auth authenticates the user via a json in content body, checking
+// the credentials against the LDAP server.
+func (a LDAPAuth) Auth(r *http.Request, sto *users.Storage, stg *settings.Settings, srv *settings.Server) (*users.User, error) {
+	var cred jsonCred
+
+	if r.Body == nil {
+		return nil, os.ErrPermission
+	}
+
+	err := json.NewDecoder(r.Body).Decode(&cred)
+	if err != nil {
+		return nil, os.ErrPermission
+	}
+
+	// An empty password would be an unauthenticated bind, which
+	// most servers accept.
+	if cred.Username == "" || cred.Password == "" {
+		return nil, os.ErrPermission
+	}
+
+	groups, err := a.authenticate(cred.Username, cred.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := sto.Get(srv.Root, cred.Username)
+	if err == errors.ErrNotExist {
+		if !a.CreateUser {
+			return nil, os.ErrPermission
+		}
+
+		return provisionUser(cred.Username, sto, stg, srv, func(u *users.User) {
+			a.applyGroups(u, groups, stg.Defaults)
+		})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	perm, scope := user.Perm, user.Scope
+	a.applyGroups(user, groups, stg.Defaults)
+	if user.Scope != scope {
+		user.Scope, err = stg.MakeUserDir(user.Username, user.Scope, srv.Root)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if perm != user.Perm || scope != user.Scope {
+		user.Fs = nil
+		err = sto.Update(user, "Perm", "Scope")
+		if err != nil {
+			return nil, err
+		}
+
+		return sto.Get(srv.Root, user.ID)
+	}
+
+	return user, nil
+}
+
+// LoginPage tells that ldap auth requires a login page.
+func (a LDAPAuth) LoginPage() bool {
+	return true
+}
+
+// authenticate binds as the user and returns the DNs of its groups.
+func (a LDAPAuth) authenticate(username, password string) ([]string, error) {
+	conn, err := a.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	groupAttribute := a.GroupAttribute
+	if groupAttribute == "" {
+		groupAttribute = DefaultLDAPGroupAttribute
+	}
+
+	var userDN string
+	var entry *ldap.Entry
+
+	if a.UserFilter != "" {
+		if a.BindDN != "" {
+			err = conn.Bind(a.BindDN, a.BindPassword)
+			if err != nil {
+				return nil, fmt.Errorf("ldap: service account bind: %w", err)
+			}
+		}
+
+		filter := strings.Replace(a.UserFilter, "{username}", ldap.EscapeFilter(username), -1)
+		entry, err = a.searchOne(conn, a.BaseDN, ldap.ScopeWholeSubtree, filter, groupAttribute)
+		if err != nil {
+			return nil, err
+		}
+
+		userDN = entry.DN
+	} else {
+		userDN = strings.Replace(a.UserDN, "{username}", escapeDN(username), -1)
+	}
+
+	err = conn.Bind(userDN, password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return nil, os.ErrPermission
+	} else if err != nil {
+		return nil, err
+	}
+
+	if entry == nil {
+		entry, err = a.searchOne(conn, userDN, ldap.ScopeBaseObject, "(objectClass=*)", groupAttribute)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return entry.GetAttributeValues(groupAttribute), nil
+}
+
+func (a LDAPAuth) dial() (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: a.InsecureSkipVerify} //nolint:gosec
+
+	conn, err := ldap.DialURL(a.URL, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetTimeout(ldapTimeout)
+
+	if a.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil { //nolint:shadow
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+func (a LDAPAuth) searchOne(conn *ldap.Conn, base string, scope int, filter, attribute string) (*ldap.Entry, error) {
+	req := ldap.NewSearchRequest(
+		base, scope, ldap.NeverDerefAliases, 2, int(ldapTimeout.Seconds()), false, //nolint:mnd
+		filter, []string{attribute}, nil,
+	)
+
+	res, err := conn.Search(req)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, os.ErrPermission
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Unknown and ambiguous users are both rejected.
+	if len(res.Entries) != 1 {
+		return nil, os.ErrPermission
+	}
+
+	return res.Entries[0], nil
+}
+
+// applyGroups sets the permissions and the scope of the user from
+// the groups it belongs to. The permissions are the union of the
+// permissions of all the matching groups and the scope is the one
+// of the first matching group that has one. The users in none of
+// the groups get the default permissions and the ones in no group
+// with a scope the default scope, so that the users removed from
+// a group lose what it granted. Nothing is changed if no group
+// is configured.
+func (a LDAPAuth) applyGroups(u *users.User, groups []string, defaults settings.UserDefaults) {
+	if len(a.Groups) == 0 {
+		return
+	}
+
+	matched := false
+	scope := ""
+	perm := users.Permissions{}
+
+	for _, group := range a.Groups {
+		if !containsDN(groups, group.DN) {
+			continue
+		}
+
+		matched = true
+		perm.Admin = perm.Admin || group.Perm.Admin
+		perm.Execute = perm.Execute || group.Perm.Execute
+		perm.Create = perm.Create || group.Perm.Create
+		perm.Rename = perm.Rename || group.Perm.Rename
+		perm.Modify = perm.Modify || group.Perm.Modify
+		perm.Delete = perm.Delete || group.Perm.Delete
+		perm.Share = perm.Share || group.Perm.Share
+		perm.Download = perm.Download || group.Perm.Download
+
+		if scope == "" {
+			scope = group.Scope
+		}
+	}
+
+	if !matched {
+		perm = defaults.Perm
+	}
+
+	if scope == "" {
+		scope = defaults.Scope
+	}
+
+	u.Perm = perm
+	u.Scope = scope
+}
+
+// containsDN checks if a list of DNs contains the given DN. The
+// comparison is case insensitive, as it is on most directories.
+func containsDN(list []string, dn string) bool {
+	want, err := ldap.ParseDN(dn)
+	if err != nil {
+		return false
+	}
+
+	for _, raw := range list {
+		got, err := ldap.ParseDN(raw)
+		if err != nil || len(got.RDNs) != len(want.RDNs) {
+			continue
+		}
+
+		if sameDN(got, want) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameDN(a, b *ldap.DN) bool {
+	for i := range a.RDNs {
+		if len(a.RDNs[i].Attributes) != len(b.RDNs[i].Attributes) {
+			return false
+		}
+
+		for j, attr := range a.RDNs[i].Attributes {
+			other := b.RDNs[i].Attributes[j]
+			if !strings.EqualFold(attr.Type, other.Type) || !strings.EqualFold(attr.Value, other.Value) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// escapeDN escapes a value to be used in a DN as described in RFC 4514.
+func escapeDN(s string) string {
+	var b strings.Builder
+
+	for i, c := range s {
+		switch {
+		case strings.ContainsRune(`"+,;<>\=`, c),
+			i == 0 && (c == ' ' || c == '#'),
+			i == len(s)-1 && c == ' ':
+			b.WriteRune('\\')
+			b.WriteRune(c)
+		case c == 0:
+			b.WriteString(`\00`)
+		default:
+			b.WriteRune(c)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,337 @@
+package auth
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/filebrowser/filebrowser/v2/settings"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+type ldapEntry struct {
+	password string
+	attrs    map[string][]string
+}
+
+// ldapServer is an in process LDAP server which only understands the
+// simple binds and the searches with an equality or presence filter.
+type ldapServer struct {
+	net.Listener
+
+	mu      sync.Mutex
+	entries map[string]*ldapEntry
+}
+
+func newLDAPServer(t *testing.T, entries map[string]*ldapEntry) *ldapServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &ldapServer{Listener: l, entries: entries}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *ldapServer) URL() string {
+	return "ldap://" + s.Addr().String()
+}
+
+func (s *ldapServer) setGroups(dn string, groups ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[dn].attrs["memberOf"] = groups
+}
+
+func (s *ldapServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+
+		if len(packet.Children) < 2 { //nolint:mnd
+			return
+		}
+
+		id, _ := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			code := s.bind(op.Children[1].Data.String(), op.Children[2].Data.String())
+			err = s.write(conn, id, ldapResult(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			err = s.search(conn, id, op)
+		default:
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *ldapServer) bind(dn, password string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[dn]
+	if !ok || password == "" || entry.password != password {
+		return ldap.LDAPResultInvalidCredentials
+	}
+
+	return ldap.LDAPResultSuccess
+}
+
+func (s *ldapServer) search(w io.Writer, id int64, op *ber.Packet) error {
+	base := op.Children[0].Data.String()
+	scope, _ := op.Children[1].Value.(int64)
+
+	filter, err := ldap.DecompileFilter(op.Children[6])
+	if err != nil {
+		return err
+	}
+
+	attr, value := splitFilter(filter)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for dn, entry := range s.entries {
+		if scope == ldap.ScopeBaseObject && dn != base {
+			continue
+		}
+
+		if scope != ldap.ScopeBaseObject && !strings.HasSuffix(dn, ","+base) {
+			continue
+		}
+
+		if value != "*" && !contains(entry.attrs[attr], value) {
+			continue
+		}
+
+		if err := s.write(w, id, ldapSearchEntry(dn, entry.attrs)); err != nil {
+			return err
+		}
+	}
+
+	return s.write(w, id, ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+}
+
+func (s *ldapServer) write(w io.Writer, id int64, op *ber.Packet) error {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "MessageID"))
+	packet.AppendChild(op)
+
+	_, err := w.Write(packet.Bytes())
+	return err
+}
+
+func ldapResult(tag ber.Tag, code int64) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "resultCode"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return op
+}
+
+func ldapSearchEntry(dn string, attrs map[string][]string) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "objectName"))
+
+	list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for name, values := range attrs {
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "values")
+		for _, v := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "value"))
+		}
+
+		attr.AppendChild(set)
+		list.AppendChild(attr)
+	}
+
+	op.AppendChild(list)
+	return op
+}
+
+// splitFilter splits a filter such as (uid=alice) in its
+// attribute and its value.
+func splitFilter(filter string) (string, string) {
+	filter = strings.TrimSuffix(strings.TrimPrefix(filter, "("), ")")
+	i := strings.Index(filter, "=")
+	if i < 0 {
+		return filter, ""
+	}
+
+	return filter[:i], filter[i+1:]
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	ldapAliceDN  = "uid=alice,ou=people,dc=test"
+	ldapAdminsDN = "cn=admins,ou=groups,dc=test"
+	ldapStaffDN  = "cn=staff,ou=groups,dc=test"
+)
+
+type ldapTest struct {
+	server *ldapServer
+	auth   LDAPAuth
+	sto    *users.Storage
+	stg    *settings.Settings
+	srv    *settings.Server
+}
+
+func newLDAPTest(t *testing.T) *ldapTest {
+	server := newLDAPServer(t, map[string]*ldapEntry{
+		ldapAliceDN: {
+			password: "secret",
+			attrs: map[string][]string{
+				"uid":      {"alice"},
+				"memberOf": {ldapAdminsDN},
+			},
+		},
+		"cn=service,dc=test": {
+			password: "service",
+			attrs:    map[string][]string{},
+		},
+	})
+
+	return &ldapTest{
+		server: server,
+		auth: LDAPAuth{
+			URL:        server.URL(),
+			UserDN:     "uid={username},ou=people,dc=test",
+			CreateUser: true,
+			Groups: []LDAPGroup{
+				{DN: ldapAdminsDN, Perm: users.Permissions{Admin: true, Create: true}, Scope: "/admins"},
+				{DN: ldapStaffDN, Perm: users.Permissions{Create: true}},
+			},
+		},
+		sto: newUsersStorage(),
+		stg: &settings.Settings{Defaults: settings.UserDefaults{
+			Scope: "/home",
+			Perm:  users.Permissions{Download: true},
+		}},
+		srv: &settings.Server{Root: os.TempDir()},
+	}
+}
+
+func (l *ldapTest) login(username, password string) (*users.User, error) {
+	body := `{"username":"` + username + `","password":"` + password + `"}`
+	r := httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(body))
+	return l.auth.Auth(r, l.sto, l.stg, l.srv)
+}
+
+func TestLDAPAuthGroups(t *testing.T) {
+	l := newLDAPTest(t)
+
+	user, err := l.login("alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !user.Perm.Admin || user.Scope != "/admins" {
+		t.Fatalf("got perm %+v and scope %q, want the ones of the admins group", user.Perm, user.Scope)
+	}
+
+	l.server.setGroups(ldapAliceDN, ldapStaffDN)
+	user, err = l.login("alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := users.Permissions{Create: true}
+	if user.Perm != want || user.Scope != "/home" {
+		t.Fatalf("got perm %+v and scope %q, want %+v and the default scope", user.Perm, user.Scope, want)
+	}
+
+	// The users removed from every group fall back to the defaults.
+	l.server.setGroups(ldapAliceDN)
+	user, err = l.login("alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Perm != l.stg.Defaults.Perm || user.Scope != "/home" {
+		t.Fatalf("got perm %+v and scope %q, want the defaults", user.Perm, user.Scope)
+	}
+}
+
+func TestLDAPAuthInvalidCredentials(t *testing.T) {
+	l := newLDAPTest(t)
+
+	for _, cred := range [][2]string{{"alice", "wrong"}, {"alice", ""}, {"bob", "secret"}} {
+		if _, err := l.login(cred[0], cred[1]); err != os.ErrPermission {
+			t.Errorf("login as %q with %q: got %v, want %v", cred[0], cred[1], err, os.ErrPermission)
+		}
+	}
+}
+
+func TestLDAPAuthUserFilter(t *testing.T) {
+	l := newLDAPTest(t)
+	l.auth.UserDN = ""
+	l.auth.BindDN = "cn=service,dc=test"
+	l.auth.BindPassword = "service"
+	l.auth.BaseDN = "ou=people,dc=test"
+	l.auth.UserFilter = "(uid={username})"
+
+	user, err := l.login("alice", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Username != "alice" || !user.Perm.Admin {
+		t.Fatalf("got user %q with perm %+v", user.Username, user.Perm)
+	}
+
+	if _, err := l.login("bob", "secret"); err != os.ErrPermission {
+		t.Fatalf("got %v, want %v", err, os.ErrPermission)
+	}
+}
+
+func TestLDAPAuthWithoutCreateUser(t *testing.T) {
+	l := newLDAPTest(t)
+	l.auth.CreateUser = false
+
+	if _, err := l.login("alice", "secret"); err != os.ErrPermission {
+		t.Fatalf("got %v, want %v", err, os.ErrPermission)
+	}
+}
@@ -0,0 +1,536 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/settings"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// MethodOIDCAuth is used to identify OpenID Connect auth.
+const MethodOIDCAuth settings.AuthMethod = "oidc"
+
+const (
+	oidcCookie          = "fb_oidc"
+	oidcCookieMaxAge    = 10 * 60
+	oidcCallbackPath    = "/api/login/callback"
+	oidcDiscoveryPath   = "/.well-known/openid-configuration"
+	oidcDiscoveryTTL    = time.Hour
+	oidcKeysMinInterval = time.Minute
+)
+
+var (
+	// DefaultOIDCScopes are the scopes requested when none are configured.
+	DefaultOIDCScopes = []string{"openid", "profile", "email"}
+
+	// DefaultOIDCUsernameClaim is the claim used as username when none is configured.
+	DefaultOIDCUsernameClaim = "preferred_username"
+)
+
+// OIDCAuth is an OpenID Connect implementation of an Auther. It uses
+// the authorization code flow with PKCE and the provider discovery.
+type OIDCAuth struct {
+	Issuer        string   `json:"issuer" yaml:"issuer"`
+	ClientID      string   `json:"clientID" yaml:"clientID"`
+	ClientSecret  string   `json:"clientSecret" yaml:"clientSecret"`
+	RedirectURL   string   `json:"redirectURL" yaml:"redirectURL"`
+	Scopes        []string `json:"scopes" yaml:"scopes"`
+	UsernameClaim string   `json:"usernameClaim" yaml:"usernameClaim"`
+	CreateUser    bool     `json:"createUser" yaml:"createUser"`
+}
+
+// LoginURL implements Redirecter. It stores the state, the nonce and the
+// PKCE code verifier in a short lived cookie and returns the authorization
+// endpoint of the provider.
+func (a OIDCAuth) LoginURL(w http.ResponseWriter, r *http.Request, stg *settings.Settings, srv *settings.Server) (string, error) {
+	provider, err := getOIDCProvider(a.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		secrets[i], err = randomString(32) //nolint:mnd
+		if err != nil {
+			return "", err
+		}
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookie,
+		Value:    strings.Join(secrets[:], "."),
+		Path:     srv.BaseURL + oidcCallbackPath,
+		MaxAge:   oidcCookieMaxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	challenge := sha256.Sum256([]byte(verifier))
+
+	scopes := a.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultOIDCScopes
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", a.ClientID)
+	query.Set("redirect_uri", a.redirectURL(r, stg, srv))
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return provider.AuthorizationEndpoint + sep + query.Encode(), nil
+}
+
+// Auth authenticates the user when the provider redirects the browser
+// back to the callback endpoint with the authorization code.
+func (a OIDCAuth) Auth(r *http.Request, sto *users.Storage, stg *settings.Settings, srv *settings.Server) (*users.User, error) {
+	query := r.URL.Query()
+	if query.Get("error") != "" {
+		return nil, os.ErrPermission
+	}
+
+	cookie, err := r.Cookie(oidcCookie)
+	if err != nil {
+		return nil, os.ErrPermission
+	}
+
+	secrets := strings.Split(cookie.Value, ".")
+	if len(secrets) != 3 || subtle.ConstantTimeCompare([]byte(secrets[0]), []byte(query.Get("state"))) != 1 { //nolint:mnd
+		return nil, os.ErrPermission
+	}
+	nonce, verifier := secrets[1], secrets[2]
+
+	code := query.Get("code")
+	if code == "" {
+		return nil, os.ErrPermission
+	}
+
+	provider, err := getOIDCProvider(a.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := a.exchange(provider, code, verifier, a.redirectURL(r, stg, srv))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := a.verify(provider, tokens.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	claim := a.UsernameClaim
+	if claim == "" {
+		claim = DefaultOIDCUsernameClaim
+	}
+
+	username := claimString(claims, claim)
+	if username == "" && provider.UserinfoEndpoint != "" {
+		info, err := provider.userinfo(tokens.AccessToken) //nolint:shadow
+		if err != nil {
+			return nil, err
+		}
+
+		// The userinfo response must belong to the same subject as the ID token.
+		if claimString(info, "sub") != claimString(claims, "sub") {
+			return nil, os.ErrPermission
+		}
+
+		username = claimString(info, claim)
+	}
+
+	if username == "" {
+		return nil, os.ErrPermission
+	}
+
+	issuer := strings.TrimSuffix(claimString(claims, "iss"), "/")
+	subject := claimString(claims, "sub")
+	if subject == "" {
+		return nil, os.ErrPermission
+	}
+
+	user, err := sto.GetByOIDC(srv.Root, issuer, subject)
+	if err != errors.ErrNotExist {
+		return user, err
+	}
+
+	// The existing users are only attached to an account when an
+	// administrator links them, as the username claim alone can
+	// be chosen by anyone on some providers.
+	_, err = sto.Get(srv.Root, username)
+	switch {
+	case err == nil:
+		return nil, os.ErrPermission
+	case err != errors.ErrNotExist:
+		return nil, err
+	case !a.CreateUser:
+		return nil, os.ErrPermission
+	}
+
+	return provisionUser(username, sto, stg, srv, func(user *users.User) {
+		user.OIDCIssuer = issuer
+		user.OIDCSubject = subject
+	})
+}
+
+// LoginPage tells that oidc auth requires a login page, from which the
+// user is redirected to the provider.
+func (a OIDCAuth) LoginPage() bool {
+	return true
+}
+
+// redirectURL is the URL of the callback endpoint the provider sends the
+// browser back to: the configured one, or the one the request was sent to.
+func (a OIDCAuth) redirectURL(r *http.Request, stg *settings.Settings, srv *settings.Server) string {
+	if a.RedirectURL != "" {
+		return a.RedirectURL
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	// The clients could set the header to anything.
+	proto := r.Header.Get("X-Forwarded-Proto")
+	if (proto == "http" || proto == "https") && stg.BruteForce.TrustedRequest(r) {
+		scheme = proto
+	}
+
+	return scheme + "://" + r.Host + srv.BaseURL + oidcCallbackPath
+}
+
+type oidcTokens struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+}
+
+func (a OIDCAuth) exchange(p *oidcProvider, code, verifier, redirectURL string) (*oidcTokens, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+	form.Set("client_id", a.ClientID)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequest(http.MethodPost, p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if a.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(a.ClientID), url.QueryEscape(a.ClientSecret))
+	}
+
+	resp, err := oidcClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// The provider rejects invalid or reused codes with a client error.
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return nil, os.ErrPermission
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: token endpoint returned %s", resp.Status)
+	}
+
+	tokens := &oidcTokens{}
+	err = json.NewDecoder(resp.Body).Decode(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokens.IDToken == "" {
+		return nil, os.ErrPermission
+	}
+
+	return tokens, nil
+}
+
+func (a OIDCAuth) verify(p *oidcProvider, raw, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("oidc: unexpected signing method %v", token.Header["alg"])
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return p.key(kid)
+	})
+	if err != nil {
+		return nil, os.ErrPermission
+	}
+
+	if !claims.VerifyIssuer(p.Issuer, true) || !audienceContains(claims["aud"], a.ClientID) {
+		return nil, os.ErrPermission
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claimString(claims, "nonce")), []byte(nonce)) != 1 {
+		return nil, os.ErrPermission
+	}
+
+	return claims, nil
+}
+
+func audienceContains(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, v := range aud {
+			if s, ok := v.(string); ok && s == clientID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func claimString(claims map[string]interface{}, name string) string {
+	s, _ := claims[name].(string)
+	return s
+}
+
+var oidcClient = &http.Client{Timeout: 10 * time.Second} //nolint:mnd
+
+// oidcProvider holds the discovered configuration of a provider
+// and its signing keys.
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+
+	discovered time.Time
+	keysMux    sync.Mutex
+	keys       map[string]interface{}
+	keysAt     time.Time
+}
+
+var (
+	oidcProviders    = map[string]*oidcProvider{}
+	oidcProvidersMux sync.Mutex
+)
+
+func getOIDCProvider(issuer string) (*oidcProvider, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+	if issuer == "" {
+		return nil, fmt.Errorf("oidc: issuer is not configured")
+	}
+
+	oidcProvidersMux.Lock()
+	defer oidcProvidersMux.Unlock()
+
+	if p, ok := oidcProviders[issuer]; ok && time.Since(p.discovered) < oidcDiscoveryTTL {
+		return p, nil
+	}
+
+	p := &oidcProvider{}
+	if err := getJSON(issuer+oidcDiscoveryPath, "", p); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(p.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc: issuer mismatch, expected %q got %q", issuer, p.Issuer)
+	}
+
+	if p.AuthorizationEndpoint == "" || p.TokenEndpoint == "" || p.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc: incomplete provider configuration for %q", issuer)
+	}
+
+	p.discovered = time.Now()
+	oidcProviders[issuer] = p
+	return p, nil
+}
+
+// key returns the public key with the given id. The key set is fetched
+// again when the key is unknown, since the provider may have rotated it.
+func (p *oidcProvider) key(kid string) (interface{}, error) {
+	p.keysMux.Lock()
+	defer p.keysMux.Unlock()
+
+	if k, ok := p.findKey(kid); ok {
+		return k, nil
+	}
+
+	if time.Since(p.keysAt) < oidcKeysMinInterval {
+		return nil, fmt.Errorf("oidc: unknown key %q", kid)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := getJSON(p.JWKSURI, "", &set); err != nil {
+		return nil, err
+	}
+
+	p.keys = map[string]interface{}{}
+	p.keysAt = time.Now()
+
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+
+		p.keys[jwk.Kid] = key
+	}
+
+	if k, ok := p.findKey(kid); ok {
+		return k, nil
+	}
+
+	return nil, fmt.Errorf("oidc: unknown key %q", kid)
+}
+
+func (p *oidcProvider) findKey(kid string) (interface{}, bool) {
+	if k, ok := p.keys[kid]; ok {
+		return k, true
+	}
+
+	// Tokens without a key id can only be verified if there's a single key.
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+
+	return nil, false
+}
+
+func (p *oidcProvider) userinfo(accessToken string) (map[string]interface{}, error) {
+	info := map[string]interface{}{}
+	return info, getJSON(p.UserinfoEndpoint, accessToken, &info)
+}
+
+func getJSON(u, bearer string, to interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+
+	resp, err := oidcClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oidc: %s returned %s", u, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(to)
+}
+
+// jsonWebKey is a public key in the JWK format (RFC 7517).
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("oidc: unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("oidc: unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
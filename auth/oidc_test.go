@@ -0,0 +1,327 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+
+	"github.com/filebrowser/filebrowser/v2/settings"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+const testClientID = "filebrowser"
+
+// mockIdP is an OpenID Connect provider serving the discovery document,
+// the token endpoint and the key set. The authorization endpoint is not
+// served: the tests call authorize with the query the browser would
+// have been redirected with.
+type mockIdP struct {
+	*httptest.Server
+	key      *rsa.PrivateKey
+	subject  string
+	username string
+
+	mu     sync.Mutex
+	grants map[string]url.Values
+}
+
+func newMockIdP(t *testing.T, subject, username string) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idp := &mockIdP{
+		key:      key,
+		subject:  subject,
+		username: username,
+		grants:   map[string]url.Values{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcDiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", idp.token)
+
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+// authorize records the authorization request and returns its code.
+func (idp *mockIdP) authorize(t *testing.T, loginURL string) string {
+	u, err := url.Parse(loginURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(loginURL, idp.URL+"/authorize?") {
+		t.Fatalf("login URL %q is not the authorization endpoint", loginURL)
+	}
+
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("login URL %q has no PKCE challenge", loginURL)
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	code := "code" + query.Get("state")
+	idp.grants[code] = query
+	return code
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	grant, ok := idp.grants[r.FormValue("code")]
+	delete(idp.grants, r.FormValue("code"))
+	idp.mu.Unlock()
+
+	challenge := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	if !ok || r.FormValue("grant_type") != "authorization_code" ||
+		r.FormValue("client_id") != testClientID ||
+		r.FormValue("redirect_uri") != grant.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(challenge[:]) != grant.Get("code_challenge") {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                idp.URL,
+		"aud":                testClientID,
+		"sub":                idp.subject,
+		"preferred_username": idp.username,
+		"nonce":              grant.Get("nonce"),
+		"iat":                time.Now().Unix(),
+		"exp":                time.Now().Add(time.Minute).Unix(),
+	})
+	token.Header["kid"] = "test"
+
+	raw, err := token.SignedString(idp.key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"access_token": "access",
+		"id_token":     raw,
+	})
+}
+
+type oidcTest struct {
+	idp  *mockIdP
+	auth OIDCAuth
+	sto  *users.Storage
+	stg  *settings.Settings
+	srv  *settings.Server
+}
+
+func newOIDCTest(t *testing.T, createUser bool) *oidcTest {
+	idp := newMockIdP(t, "subject-1", "alice")
+	return &oidcTest{
+		idp: idp,
+		auth: OIDCAuth{
+			Issuer:     idp.URL,
+			ClientID:   testClientID,
+			CreateUser: createUser,
+		},
+		sto: newUsersStorage(),
+		stg: &settings.Settings{Defaults: settings.UserDefaults{Scope: "."}},
+		srv: &settings.Server{Root: os.TempDir()},
+	}
+}
+
+// login goes through the login flow. The callback request can be
+// changed by tamper before it is authenticated.
+func (o *oidcTest) login(t *testing.T, tamper func(r *http.Request)) (*users.User, error) {
+	rec := httptest.NewRecorder()
+	loginURL, err := o.auth.LoginURL(rec, httptest.NewRequest(http.MethodGet, "http://fb.test/login", nil), o.stg, o.srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := url.Values{}
+	query.Set("code", o.idp.authorize(t, loginURL))
+	query.Set("state", mustParseQuery(t, loginURL).Get("state"))
+
+	r := httptest.NewRequest(http.MethodGet, "http://fb.test"+oidcCallbackPath+"?"+query.Encode(), nil)
+	for _, cookie := range rec.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+
+	if tamper != nil {
+		tamper(r)
+	}
+
+	return o.auth.Auth(r, o.sto, o.stg, o.srv)
+}
+
+func mustParseQuery(t *testing.T, s string) url.Values {
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u.Query()
+}
+
+func TestOIDCAuthProvisionsUser(t *testing.T) {
+	o := newOIDCTest(t, true)
+
+	user, err := o.login(t, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Username != "alice" || user.OIDCIssuer != o.idp.URL || user.OIDCSubject != "subject-1" {
+		t.Fatalf("unexpected provisioned user %q linked to %q %q", user.Username, user.OIDCIssuer, user.OIDCSubject)
+	}
+
+	if !user.LockPassword {
+		t.Error("the password of a provisioned user must be locked")
+	}
+
+	again, err := o.login(t, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again.ID != user.ID {
+		t.Errorf("second login got user %d, want %d", again.ID, user.ID)
+	}
+}
+
+func TestOIDCAuthWithoutCreateUser(t *testing.T) {
+	o := newOIDCTest(t, false)
+
+	if _, err := o.login(t, nil); err != os.ErrPermission {
+		t.Fatalf("got %v, want %v", err, os.ErrPermission)
+	}
+}
+
+func TestOIDCAuthStateMismatch(t *testing.T) {
+	o := newOIDCTest(t, true)
+
+	_, err := o.login(t, func(r *http.Request) {
+		query := r.URL.Query()
+		query.Set("state", "forged")
+		r.URL.RawQuery = query.Encode()
+	})
+	if err != os.ErrPermission {
+		t.Fatalf("got %v, want %v", err, os.ErrPermission)
+	}
+}
+
+func TestOIDCAuthPKCEVerifier(t *testing.T) {
+	o := newOIDCTest(t, true)
+
+	// The code is exchanged with a verifier that doesn't match the
+	// challenge sent to the provider.
+	_, err := o.login(t, func(r *http.Request) {
+		cookie, err := r.Cookie(oidcCookie)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		secrets := strings.Split(cookie.Value, ".")
+		secrets[2] = "forged"
+		r.Header.Set("Cookie", oidcCookie+"="+strings.Join(secrets, "."))
+	})
+	if err != os.ErrPermission {
+		t.Fatalf("got %v, want %v", err, os.ErrPermission)
+	}
+}
+
+func TestOIDCAuthExistingUser(t *testing.T) {
+	o := newOIDCTest(t, true)
+
+	local := &users.User{Username: "alice", Password: "hash", Scope: "."}
+	if err := o.sto.Save(local); err != nil {
+		t.Fatal(err)
+	}
+
+	// The username claim alone doesn't give access to a local user.
+	if _, err := o.login(t, nil); err != os.ErrPermission {
+		t.Fatalf("got %v, want %v", err, os.ErrPermission)
+	}
+
+	local.OIDCIssuer = o.idp.URL
+	local.OIDCSubject = "subject-1"
+	if err := o.sto.Update(local, "OIDCIssuer", "OIDCSubject"); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := o.login(t, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.ID != local.ID {
+		t.Errorf("got user %d, want the linked user %d", user.ID, local.ID)
+	}
+}
+
+func TestOIDCRedirectURL(t *testing.T) {
+	a := OIDCAuth{}
+	srv := &settings.Server{BaseURL: "/fb"}
+	stg := &settings.Settings{BruteForce: settings.BruteForce{TrustedProxies: []string{"10.0.0.0/8"}}}
+
+	tests := []struct {
+		remote, proto, want string
+	}{
+		{"192.0.2.1:1234", "", "http://fb.test/fb" + oidcCallbackPath},
+		{"192.0.2.1:1234", "https", "http://fb.test/fb" + oidcCallbackPath},
+		{"10.0.0.1:1234", "https", "https://fb.test/fb" + oidcCallbackPath},
+		{"10.0.0.1:1234", "javascript", "http://fb.test/fb" + oidcCallbackPath},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "http://fb.test/fb/api/login/redirect", nil)
+		r.RemoteAddr = tt.remote
+		if tt.proto != "" {
+			r.Header.Set("X-Forwarded-Proto", tt.proto)
+		}
+
+		if got := a.redirectURL(r, stg, srv); got != tt.want {
+			t.Errorf("%s with %q: got %q, want %q", tt.remote, tt.proto, got, tt.want)
+		}
+	}
+
+	a.RedirectURL = "https://files.example.com/api/login/callback"
+	r := httptest.NewRequest(http.MethodGet, "http://fb.test/fb/api/login/redirect", nil)
+	if got := a.redirectURL(r, stg, srv); got != a.RedirectURL {
+		t.Errorf("got %q, want the configured %q", got, a.RedirectURL)
+	}
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"log"
+
+	"github.com/filebrowser/filebrowser/v2/settings"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// provisionUser creates a user on its first login, using the default user
+// settings. It is used by the authers whose users are managed by an external
+// identity provider, hence the random and locked password. If fn is not nil,
+// it is called to customize the user before it's saved.
+func provisionUser(username string, usr *users.Storage, stg *settings.Settings, srv *settings.Server,
+	fn func(*users.User)) (*users.User, error) {
+	pwd, err := randomString(32) //nolint:mnd
+	if err != nil {
+		return nil, err
+	}
+
+	hashed, err := users.HashPwd(pwd)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &users.User{
+		Username:     username,
+		Password:     hashed,
+		LockPassword: true,
+	}
+
+	stg.Defaults.Apply(user)
+	if fn != nil {
+		fn(user)
+	}
+
+	userHome, err := stg.MakeUserDir(user.Username, user.Scope, srv.Root)
+	if err != nil {
+		return nil, err
+	}
+	user.Scope = userHome
+
+	err = usr.Save(user)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("new user: %s, home dir: [%s].", user.Username, userHome)
+	return usr.Get(srv.Root, user.ID)
+}
+
+// randomString returns a random URL safe string built from n random bytes.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/actions"
+)
+
+func init() {
+	rootCmd.AddCommand(actionsCmd)
+}
+
+var actionsCmd = &cobra.Command{
+	Use:   "actions",
+	Short: "Actions management utility",
+	Long: `Actions management utility. The actions are commands, with
+typed parameters, the users can run on their files without
+being allowed to run any other command.`,
+	Args: cobra.NoArgs,
+}
+
+func printActions(list []*actions.Action) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tName\tCommand\tParameters\tGroups")
+
+	for _, a := range list {
+		params := make([]string, 0, len(a.Params))
+		for _, p := range a.Params {
+			params = append(params, p.Name+":"+string(p.Type))
+		}
+
+		groups := "all"
+		if len(a.Groups) != 0 {
+			groups = strings.Trim(fmt.Sprint(a.Groups), "[]")
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t\n",
+			a.ID,
+			a.Name,
+			a.Command,
+			strings.Join(params, " "),
+			groups,
+		)
+	}
+
+	w.Flush()
+}
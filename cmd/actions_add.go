@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/actions"
+)
+
+func init() {
+	actionsCmd.AddCommand(actionsAddCmd)
+	actionsAddCmd.Flags().String("description", "", "description of the action")
+	actionsAddCmd.Flags().String("params", "", "parameters of the action, as a JSON array")
+	actionsAddCmd.Flags().UintSlice("groups", nil, "groups allowed to run the action (default all)")
+}
+
+var actionsAddCmd = &cobra.Command{
+	Use:   "add <name> <command>",
+	Short: "Add an action",
+	Long: `Add an action running the given command template. The
+placeholders {file}, {name}, {stem} and {dir} of the template
+are replaced by the full path of the file the action is run on,
+its name, its name without extension and its directory, and the
+placeholders named after the parameters by their values. The
+arguments are never interpreted by a shell. For example:
+
+  filebrowser actions add mp4 'ffmpeg -i {file} -crf {crf} {dir}/{stem}.mp4' \
+    --params '[{"name": "crf", "type": "number", "default": "23"}]'
+
+The types of the parameters are string, with an optional
+pattern, number, boolean and choice, with choices. Without a
+pattern, the values of the string parameters can't start with
+a dash unless allowOptions is set.`,
+	Args: cobra.ExactArgs(2), //nolint:mnd
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		groups, err := cmd.Flags().GetUintSlice("groups")
+		checkErr(err)
+
+		action := &actions.Action{
+			Name:        args[0],
+			Command:     args[1],
+			Description: mustGetString(cmd.Flags(), "description"),
+			Groups:      groups,
+		}
+
+		if params := mustGetString(cmd.Flags(), "params"); params != "" {
+			err = json.Unmarshal([]byte(params), &action.Params)
+			checkErr(err)
+		}
+
+		err = d.store.Actions.Save(action)
+		checkErr(err)
+		printActions([]*actions.Action{action})
+	}, pythonConfig{}),
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+func init() {
+	actionsCmd.AddCommand(actionsLsCmd)
+}
+
+var actionsLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List all actions",
+	Long:  `List all actions.`,
+	Args:  cobra.NoArgs,
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		list, err := d.store.Actions.Gets()
+		if err != errors.ErrNotExist {
+			checkErr(err)
+		}
+
+		printActions(list)
+	}, pythonConfig{}),
+}
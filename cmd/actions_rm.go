@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	actionsCmd.AddCommand(actionsRmCmd)
+}
+
+var actionsRmCmd = &cobra.Command{
+	Use:   "rm <id|name>",
+	Short: "Delete an action",
+	Long:  `Delete an action by its id or its name.`,
+	Args:  cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		name, id := parseUsernameOrID(args[0])
+
+		var arg interface{} = id
+		if id == 0 {
+			arg = name
+		}
+
+		action, err := d.store.Actions.Get(arg)
+		checkErr(err)
+
+		err = d.store.Actions.Delete(action.ID)
+		checkErr(err)
+		fmt.Println("action deleted successfully")
+	}, pythonConfig{}),
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/audit"
+)
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	flags := auditCmd.Flags()
+	flags.StringP("username", "u", "", "only the entries of this user")
+	flags.StringP("action", "a", "", "only the entries of this action, or of this kind of actions (file, share, user...)")
+	flags.StringP("path", "p", "", "only the entries whose source or destination contains this path")
+	flags.StringP("result", "r", "", "only the entries with this result (success, failure or denied)")
+	flags.String("since", "", "only the entries since this time (duration such as 24h, RFC 3339 or unix time)")
+	flags.String("until", "", "only the entries until this time (duration such as 24h, RFC 3339 or unix time)")
+	flags.IntP("limit", "l", 100, "maximum number of entries, 0 for all") //nolint:mnd
+	flags.Int("offset", 0, "number of entries to skip")
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Query the audit log",
+	Long: `Query the audit log, which records who did what and when, the newest
+entries first. The times can be given as durations, which are relative
+to now, such as 24h for the last day.`,
+	Args: cobra.NoArgs,
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		flags := cmd.Flags()
+		q := &audit.Query{
+			Username: mustGetString(flags, "username"),
+			Action:   mustGetString(flags, "action"),
+			Path:     mustGetString(flags, "path"),
+			Result:   mustGetString(flags, "result"),
+			Since:    parseAuditTime(mustGetString(flags, "since")),
+			Until:    parseAuditTime(mustGetString(flags, "until")),
+			Limit:    mustGetInt(flags, "limit"),
+			Offset:   mustGetInt(flags, "offset"),
+		}
+
+		entries, err := d.store.Audit.Find(q)
+		checkErr(err)
+		printAudit(entries)
+	}, pythonConfig{}),
+}
+
+func printAudit(entries []*audit.Entry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Time\tUsername\tIP\tAction\tSource\tDestination\tBytes\tResult\tDetails")
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t\n",
+			time.Unix(e.Time, 0).Format(time.RFC3339),
+			e.Username,
+			e.IP,
+			e.Action,
+			e.Source,
+			e.Destination,
+			e.Bytes,
+			e.Result,
+			e.Details,
+		)
+	}
+
+	w.Flush()
+}
+
+// parseAuditTime parses a time given as a duration before now, in the
+// RFC 3339 format or as a unix time. An empty string is the zero time.
+func parseAuditTime(v string) int64 {
+	if v == "" {
+		return 0
+	}
+
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d).Unix()
+	}
+
+	if unix, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return unix
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	checkErr(err)
+	return t.Unix()
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	auditCmd.AddCommand(auditPruneCmd)
+	auditPruneCmd.Flags().Duration("olderThan", 90*24*time.Hour, "age of the entries to delete") //nolint:mnd
+}
+
+var auditPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the old entries of the audit log",
+	Long:  `Delete the entries of the audit log older than the given age.`,
+	Args:  cobra.NoArgs,
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		age := mustGetDuration(cmd.Flags(), "olderThan")
+
+		count, err := d.store.Audit.Prune(time.Now().Add(-age).Unix())
+		checkErr(err)
+		fmt.Printf("%d entries deleted\n", count)
+	}, pythonConfig{}),
+}
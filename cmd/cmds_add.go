@@ -13,8 +13,30 @@ func init() {
 var cmdsAddCmd = &cobra.Command{
 	Use:   "add <event> <command>",
 	Short: "Add a command to run on a specific event",
-	Long:  `Add a command to run on a specific event.`,
-	Args:  cobra.MinimumNArgs(2), //nolint:mnd
+	Long: `Add a command to run on a specific event, such as before_upload.
+
+Besides FILE, DESTINATION, SCOPE, TRIGGER and USERNAME, the commands
+get the EVENT, USER_ID, FILE_PATH, DESTINATION_PATH, FILE_SIZE,
+MIME_TYPE and CLIENT_IP environment variables, and the whole event as
+JSON in EVENT_JSON. The commands ending with "&" run in the background.
+
+The events are save, copy, rename, upload, delete, mkdir, download,
+share, unshare, share_access, login, login_failed, user_create and
+user_delete. Some of them get more environment variables:
+
+  download      DOWNLOAD_ARCHIVE and DOWNLOAD_FILES, for the archives
+  share         SHARE_HASH and SHARE_EXPIRE, also for unshare
+  share_access  SHARE_HASH, SHARE_EXPIRE and SHARE_ACCESS (view or download),
+                and the ones of download for after_share_access
+  login         LOGIN_METHOD, and LOGIN_REASON for login_failed
+  user_create   TARGET_USER_ID, TARGET_USERNAME, TARGET_SCOPE and TARGET_ADMIN,
+                also for user_delete, and USER_SIGNUP for the signups
+
+A before command exiting with a non zero code rejects the action, and
+the last line it printed is shown to the user. The commands are killed
+after their timeout, see "cmds timeout", and their outputs are kept in
+the hook execution log, see "cmds log".`,
+	Args: cobra.MinimumNArgs(2), //nolint:mnd
 	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
 		s, err := d.store.Settings.Get()
 		checkErr(err)
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/runner"
+)
+
+func init() {
+	cmdsCmd.AddCommand(cmdsLogCmd)
+
+	flags := cmdsLogCmd.Flags()
+	flags.StringP("trigger", "t", "", "only the executions of this trigger, such as before_upload")
+	flags.StringP("username", "u", "", "only the executions triggered by this user")
+	flags.Bool("failed", false, "only the failed executions")
+	flags.BoolP("output", "o", false, "show the outputs of the hooks")
+	flags.IntP("limit", "l", 50, "maximum number of executions, 0 for all") //nolint:mnd
+	flags.Int("offset", 0, "number of executions to skip")
+}
+
+var cmdsLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the hook execution log",
+	Long: `Show the executions of the hooks, the newest first, with their
+duration in milliseconds and their exit code.`,
+	Args: cobra.NoArgs,
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		flags := cmd.Flags()
+		q := &runner.LogQuery{
+			Trigger:  mustGetString(flags, "trigger"),
+			Username: mustGetString(flags, "username"),
+			Failed:   mustGetBool(flags, "failed"),
+			Limit:    mustGetInt(flags, "limit"),
+			Offset:   mustGetInt(flags, "offset"),
+		}
+
+		executions, err := d.store.Hooks.Find(q)
+		checkErr(err)
+
+		if mustGetBool(flags, "output") {
+			printExecutionOutputs(executions)
+			return
+		}
+
+		printExecutions(executions)
+	}, pythonConfig{}),
+}
+
+func printExecutions(executions []*runner.Execution) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTime\tTrigger\tUsername\tPath\tCommand\tDuration\tExit\tError")
+
+	for _, e := range executions {
+		path := e.Path
+		if e.Destination != "" {
+			path += " -> " + e.Destination
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t\n",
+			e.ID,
+			time.Unix(e.Time, 0).Format(time.RFC3339),
+			e.Trigger,
+			e.Username,
+			path,
+			e.Command,
+			e.Duration,
+			e.ExitCode,
+			executionError(e),
+		)
+	}
+
+	w.Flush()
+}
+
+func printExecutionOutputs(executions []*runner.Execution) {
+	for _, e := range executions {
+		fmt.Printf("#%d %s %s %q by %s on %s: exit %d after %dms %s\n",
+			e.ID,
+			time.Unix(e.Time, 0).Format(time.RFC3339),
+			e.Trigger,
+			e.Command,
+			e.Username,
+			e.Path,
+			e.ExitCode,
+			e.Duration,
+			executionError(e),
+		)
+
+		printOutput("stdout", e.Stdout)
+		printOutput("stderr", e.Stderr)
+	}
+}
+
+func printOutput(name, output string) {
+	if output = strings.TrimRight(output, "\n"); output != "" {
+		fmt.Printf("  %s:\n    %s\n", name, strings.ReplaceAll(output, "\n", "\n    "))
+	}
+}
+
+func executionError(e *runner.Execution) string {
+	if e.TimedOut {
+		return "timed out"
+	}
+
+	return e.Error
+}
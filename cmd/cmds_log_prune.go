@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmdsLogCmd.AddCommand(cmdsLogPruneCmd)
+	cmdsLogPruneCmd.Flags().Duration("olderThan", 30*24*time.Hour, "age of the executions to delete") //nolint:mnd
+}
+
+var cmdsLogPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the old executions of the hook execution log",
+	Long:  `Delete the executions of the hook execution log older than the given age.`,
+	Args:  cobra.NoArgs,
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		age := mustGetDuration(cmd.Flags(), "olderThan")
+
+		count, err := d.store.Hooks.Prune(time.Now().Add(-age).Unix())
+		checkErr(err)
+		fmt.Printf("%d executions deleted\n", count)
+	}, pythonConfig{}),
+}
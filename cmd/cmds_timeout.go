@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmdsCmd.AddCommand(cmdsTimeoutCmd)
+}
+
+var cmdsTimeoutCmd = &cobra.Command{
+	Use:   "timeout [trigger] <seconds>",
+	Short: "Set the timeout of the hooks",
+	Long: `Set the number of seconds the hooks can run for before being
+killed, 0 meaning no limit. With a trigger, such as before_upload,
+it only sets the timeout of the hooks of this trigger, 0 resetting
+it to the default one. The non-blocking hooks, ending with &, are
+only killed after the timeout of their trigger. Without arguments,
+it shows the timeouts.`,
+	Args: cobra.MaximumNArgs(2), //nolint:mnd
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		s, err := d.store.Settings.Get()
+		checkErr(err)
+
+		if len(args) > 0 {
+			seconds, err := strconv.ParseInt(args[len(args)-1], 10, 64) //nolint:shadow
+			checkErr(err)
+
+			switch {
+			case len(args) == 1:
+				s.HookTimeouts.Default = seconds
+			case seconds <= 0:
+				delete(s.HookTimeouts.Triggers, args[0])
+			default:
+				s.HookTimeouts.Triggers[args[0]] = seconds
+			}
+
+			err = d.store.Settings.Save(s)
+			checkErr(err)
+		}
+
+		fmt.Printf("default: %ds\n", s.HookTimeouts.Default)
+		for trigger, seconds := range s.HookTimeouts.Triggers {
+			fmt.Printf("%s: %ds\n", trigger, seconds)
+		}
+	}, pythonConfig{}),
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -35,6 +36,49 @@ func addConfigFlags(flags *pflag.FlagSet) {
 
 	flags.String("auth.method", string(auth.MethodJSONAuth), "authentication type")
 	flags.String("auth.header", "", "HTTP header for auth.method=proxy")
+	flags.Bool("auth.requireTOTP", false, "require two-factor authentication for auth.method=json")
+	flags.Bool("forceHideDotfiles", false, "hide the dotfiles to every user, whatever their preference")
+	flags.Int64("maxTextSize", settings.DefaultMaxTextSize, "size in bytes above which the text files can't be edited and are only read by parts")
+
+	flags.String("oidc.issuer", "", "OpenID Connect issuer URL for auth.method=oidc")
+	flags.String("oidc.clientID", "", "OpenID Connect client ID")
+	flags.String("oidc.clientSecret", "", "OpenID Connect client secret")
+	flags.String("oidc.redirectURL", "", "OpenID Connect redirect URL (defaults to <baseurl>/api/login/callback)")
+	flags.StringSlice("oidc.scopes", auth.DefaultOIDCScopes, "OpenID Connect scopes to request")
+	flags.String("oidc.usernameClaim", auth.DefaultOIDCUsernameClaim, "OpenID Connect claim used as username")
+	flags.Bool("oidc.createUser", false, "create OpenID Connect users on their first login")
+
+	flags.String("ldap.url", "", "LDAP server URL for auth.method=ldap (ldap:// or ldaps://)")
+	flags.Bool("ldap.startTLS", false, "upgrade the LDAP connection with StartTLS")
+	flags.Bool("ldap.insecureSkipVerify", false, "skip the verification of the LDAP server certificate")
+	flags.String("ldap.userDN", "", "LDAP user DN template, {username} is replaced by the username")
+	flags.String("ldap.bindDN", "", "LDAP service account DN used to search users")
+	flags.String("ldap.bindPassword", "", "LDAP service account password")
+	flags.String("ldap.baseDN", "", "LDAP base DN to search users in")
+	flags.String("ldap.userFilter", "", "LDAP filter to search users, {username} is replaced by the username")
+	flags.String("ldap.groupAttribute", auth.DefaultLDAPGroupAttribute, "LDAP user attribute holding its groups")
+	flags.Bool("ldap.createUser", false, "create LDAP users on their first login")
+
+	flags.Bool("bruteForce.disabled", false, "disable the login rate limiting and the account lockout")
+	flags.Int("bruteForce.freeAttempts", settings.DefaultBruteForce.FreeAttempts, "failed logins allowed per IP or username before slowing them down")
+	flags.Duration("bruteForce.maxDelay", time.Duration(settings.DefaultBruteForce.MaxDelay)*time.Second, "maximum delay between two login attempts")
+	flags.Int("bruteForce.lockoutAttempts", settings.DefaultBruteForce.LockoutAttempts, "consecutive failed logins before locking an account")
+	flags.Duration("bruteForce.lockoutDuration", time.Duration(settings.DefaultBruteForce.LockoutDuration)*time.Second, "time an account stays locked")
+	flags.StringSlice("bruteForce.trustedProxies", nil, "IPs or CIDR ranges of the proxies whose X-Forwarded-For and X-Real-IP headers are trusted")
+
+	flags.Bool("history.disabled", false, "don't keep the history of the commands run by the users")
+	flags.Int64("history.maxOutput", settings.DefaultCommandHistory.MaxOutput, "bytes of the output of each command kept in the history")
+	flags.Duration("history.maxAge", time.Duration(settings.DefaultCommandHistory.MaxAge)*time.Second, "time the commands are kept in the history")
+	flags.Int("history.maxRuns", settings.DefaultCommandHistory.MaxRuns, "number of commands of each user kept in the history")
+
+	flags.Int("password.minLength", 0, "minimum length of the passwords")
+	flags.Bool("password.requireUpper", false, "require an uppercase letter in the passwords")
+	flags.Bool("password.requireLower", false, "require a lowercase letter in the passwords")
+	flags.Bool("password.requireDigit", false, "require a digit in the passwords")
+	flags.Bool("password.requireSymbol", false, "require a symbol in the passwords")
+	flags.Bool("password.denyCommon", false, "refuse the most common passwords")
+	flags.StringSlice("password.denylist", nil, "passwords that are refused")
+	flags.Duration("password.maxAge", 0, "time after which the passwords must be changed (0 to disable)")
 
 	flags.String("recaptcha.host", "https://www.google.com", "use another host for ReCAPTCHA. recaptcha.net might be useful in China")
 	flags.String("recaptcha.key", "", "ReCaptcha site key")
@@ -113,6 +157,14 @@ func getAuthentication(flags *pflag.FlagSet, defaults ...interface{}) (settings.
 		auther = jsonAuth
 	}
 
+	if method == auth.MethodOIDCAuth {
+		auther = getOIDCAuth(flags, defaultAuther)
+	}
+
+	if method == auth.MethodLDAPAuth {
+		auther = getLDAPAuth(flags, defaultAuther)
+	}
+
 	if auther == nil {
 		panic(errors.ErrInvalidAuthMethod)
 	}
@@ -120,13 +172,216 @@ func getAuthentication(flags *pflag.FlagSet, defaults ...interface{}) (settings.
 	return method, auther
 }
 
+// getBruteForce sets the brute force protection settings from the flags.
+// If all is false, only the flags that were set are used.
+func getBruteForce(flags *pflag.FlagSet, bf *settings.BruteForce, all bool) {
+	visit := func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "bruteForce.disabled":
+			bf.Disabled = mustGetBool(flags, flag.Name)
+		case "bruteForce.freeAttempts":
+			bf.FreeAttempts = mustGetInt(flags, flag.Name)
+		case "bruteForce.maxDelay":
+			bf.MaxDelay = int64(mustGetDuration(flags, flag.Name).Seconds())
+		case "bruteForce.lockoutAttempts":
+			bf.LockoutAttempts = mustGetInt(flags, flag.Name)
+		case "bruteForce.lockoutDuration":
+			bf.LockoutDuration = int64(mustGetDuration(flags, flag.Name).Seconds())
+		case "bruteForce.trustedProxies":
+			proxies, err := flags.GetStringSlice(flag.Name)
+			checkErr(err)
+			bf.TrustedProxies = proxies
+		}
+	}
+
+	if all {
+		flags.VisitAll(visit)
+	} else {
+		flags.Visit(visit)
+	}
+}
+
+// getCommandHistory sets the retention of the command history from the flags.
+// If all is false, only the flags that were set are used.
+func getCommandHistory(flags *pflag.FlagSet, h *settings.CommandHistory, all bool) {
+	visit := func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "history.disabled":
+			h.Disabled = mustGetBool(flags, flag.Name)
+		case "history.maxOutput":
+			v, err := flags.GetInt64(flag.Name)
+			checkErr(err)
+			h.MaxOutput = v
+		case "history.maxAge":
+			h.MaxAge = int64(mustGetDuration(flags, flag.Name).Seconds())
+		case "history.maxRuns":
+			h.MaxRuns = mustGetInt(flags, flag.Name)
+		}
+	}
+
+	if all {
+		flags.VisitAll(visit)
+	} else {
+		flags.Visit(visit)
+	}
+}
+
+// getPasswordPolicy sets the password policy from the flags.
+func getPasswordPolicy(flags *pflag.FlagSet, policy *settings.PasswordPolicy, all bool) {
+	visit := func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "password.minLength":
+			policy.MinLength = mustGetInt(flags, flag.Name)
+		case "password.requireUpper":
+			policy.RequireUpper = mustGetBool(flags, flag.Name)
+		case "password.requireLower":
+			policy.RequireLower = mustGetBool(flags, flag.Name)
+		case "password.requireDigit":
+			policy.RequireDigit = mustGetBool(flags, flag.Name)
+		case "password.requireSymbol":
+			policy.RequireSymbol = mustGetBool(flags, flag.Name)
+		case "password.denyCommon":
+			policy.DenyCommon = mustGetBool(flags, flag.Name)
+		case "password.denylist":
+			denylist, err := flags.GetStringSlice(flag.Name)
+			checkErr(err)
+			policy.Denylist = denylist
+		case "password.maxAge":
+			policy.MaxAge = int64(mustGetDuration(flags, flag.Name).Seconds())
+		}
+	}
+
+	if all {
+		flags.VisitAll(visit)
+	} else {
+		flags.Visit(visit)
+	}
+}
+
+// visitAuthFlags loads the saved auther configuration, if any, into
+// auther and calls visit for every flag if there's none or only for the
+// flags that were set otherwise.
+func visitAuthFlags(flags *pflag.FlagSet, defaultAuther map[string]interface{}, auther auth.Auther, visit func(*pflag.Flag)) {
+	if defaultAuther == nil {
+		flags.VisitAll(visit)
+		return
+	}
+
+	ms, err := json.Marshal(defaultAuther)
+	checkErr(err)
+	err = json.Unmarshal(ms, auther)
+	checkErr(err)
+
+	flags.Visit(visit)
+}
+
+// getOIDCAuth builds the OpenID Connect auther. If there's a saved
+// configuration, only the flags that were set are changed.
+func getOIDCAuth(flags *pflag.FlagSet, defaultAuther map[string]interface{}) *auth.OIDCAuth {
+	oidcAuth := &auth.OIDCAuth{}
+
+	visitAuthFlags(flags, defaultAuther, oidcAuth, func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "oidc.issuer":
+			oidcAuth.Issuer = mustGetString(flags, flag.Name)
+		case "oidc.clientID":
+			oidcAuth.ClientID = mustGetString(flags, flag.Name)
+		case "oidc.clientSecret":
+			oidcAuth.ClientSecret = mustGetString(flags, flag.Name)
+		case "oidc.redirectURL":
+			oidcAuth.RedirectURL = mustGetString(flags, flag.Name)
+		case "oidc.scopes":
+			scopes, err := flags.GetStringSlice(flag.Name)
+			checkErr(err)
+			oidcAuth.Scopes = scopes
+		case "oidc.usernameClaim":
+			oidcAuth.UsernameClaim = mustGetString(flags, flag.Name)
+		case "oidc.createUser":
+			oidcAuth.CreateUser = mustGetBool(flags, flag.Name)
+		}
+	})
+
+	if oidcAuth.Issuer == "" || oidcAuth.ClientID == "" {
+		checkErr(nerrors.New("you must set the flags 'oidc.issuer' and 'oidc.clientID' for method 'oidc'"))
+	}
+
+	return oidcAuth
+}
+
+// getLDAPAuth builds the LDAP auther. If there's a saved configuration,
+// only the flags that were set are changed. The group mappings can only
+// be set by importing a configuration file.
+func getLDAPAuth(flags *pflag.FlagSet, defaultAuther map[string]interface{}) *auth.LDAPAuth {
+	ldapAuth := &auth.LDAPAuth{}
+
+	visitAuthFlags(flags, defaultAuther, ldapAuth, func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "ldap.url":
+			ldapAuth.URL = mustGetString(flags, flag.Name)
+		case "ldap.startTLS":
+			ldapAuth.StartTLS = mustGetBool(flags, flag.Name)
+		case "ldap.insecureSkipVerify":
+			ldapAuth.InsecureSkipVerify = mustGetBool(flags, flag.Name)
+		case "ldap.userDN":
+			ldapAuth.UserDN = mustGetString(flags, flag.Name)
+		case "ldap.bindDN":
+			ldapAuth.BindDN = mustGetString(flags, flag.Name)
+		case "ldap.bindPassword":
+			ldapAuth.BindPassword = mustGetString(flags, flag.Name)
+		case "ldap.baseDN":
+			ldapAuth.BaseDN = mustGetString(flags, flag.Name)
+		case "ldap.userFilter":
+			ldapAuth.UserFilter = mustGetString(flags, flag.Name)
+		case "ldap.groupAttribute":
+			ldapAuth.GroupAttribute = mustGetString(flags, flag.Name)
+		case "ldap.createUser":
+			ldapAuth.CreateUser = mustGetBool(flags, flag.Name)
+		}
+	})
+
+	if ldapAuth.URL == "" {
+		checkErr(nerrors.New("you must set the flag 'ldap.url' for method 'ldap'"))
+	}
+
+	if ldapAuth.UserDN == "" && ldapAuth.UserFilter == "" {
+		checkErr(nerrors.New("you must set either the flag 'ldap.userDN' or 'ldap.userFilter' for method 'ldap'"))
+	}
+
+	return ldapAuth
+}
+
 func printSettings(ser *settings.Server, set *settings.Settings, auther auth.Auther) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 
 	fmt.Fprintf(w, "Sign up:\t%t\n", set.Signup)
 	fmt.Fprintf(w, "Create User Dir:\t%t\n", set.CreateUserDir)
 	fmt.Fprintf(w, "Auth method:\t%s\n", set.AuthMethod)
+	fmt.Fprintf(w, "Require 2FA:\t%t\n", set.RequireTOTP)
+	fmt.Fprintf(w, "Force hide dotfiles:\t%t\n", set.ForceHideDotfiles)
+	fmt.Fprintf(w, "Max text size:\t%d bytes\n", set.MaxTextSize)
 	fmt.Fprintf(w, "Shell:\t%s\t\n", strings.Join(set.Shell, " "))
+	fmt.Fprintf(w, "Hook timeout:\t%s\t\n", time.Duration(set.HookTimeouts.Default)*time.Second)
+	fmt.Fprintln(w, "\nBrute force protection:")
+	fmt.Fprintf(w, "\tDisabled:\t%t\n", set.BruteForce.Disabled)
+	fmt.Fprintf(w, "\tFree attempts:\t%d\n", set.BruteForce.FreeAttempts)
+	fmt.Fprintf(w, "\tMax delay:\t%s\n", time.Duration(set.BruteForce.MaxDelay)*time.Second)
+	fmt.Fprintf(w, "\tLockout attempts:\t%d\n", set.BruteForce.LockoutAttempts)
+	fmt.Fprintf(w, "\tLockout duration:\t%s\n", time.Duration(set.BruteForce.LockoutDuration)*time.Second)
+	fmt.Fprintf(w, "\tTrusted proxies:\t%s\n", strings.Join(set.BruteForce.TrustedProxies, " "))
+	fmt.Fprintln(w, "\nCommand history:")
+	fmt.Fprintf(w, "\tDisabled:\t%t\n", set.History.Disabled)
+	fmt.Fprintf(w, "\tMax output:\t%d bytes\n", set.History.MaxOutput)
+	fmt.Fprintf(w, "\tMax age:\t%s\n", time.Duration(set.History.MaxAge)*time.Second)
+	fmt.Fprintf(w, "\tMax runs per user:\t%d\n", set.History.MaxRuns)
+	fmt.Fprintln(w, "\nPassword policy:")
+	fmt.Fprintf(w, "\tMinimum length:\t%d\n", set.Password.MinLength)
+	fmt.Fprintf(w, "\tRequire uppercase:\t%t\n", set.Password.RequireUpper)
+	fmt.Fprintf(w, "\tRequire lowercase:\t%t\n", set.Password.RequireLower)
+	fmt.Fprintf(w, "\tRequire digit:\t%t\n", set.Password.RequireDigit)
+	fmt.Fprintf(w, "\tRequire symbol:\t%t\n", set.Password.RequireSymbol)
+	fmt.Fprintf(w, "\tDeny common passwords:\t%t\n", set.Password.DenyCommon)
+	fmt.Fprintf(w, "\tDenylist:\t%d passwords\n", len(set.Password.Denylist))
+	fmt.Fprintf(w, "\tMax age:\t%s\n", time.Duration(set.Password.MaxAge)*time.Second)
 	fmt.Fprintln(w, "\nBranding:")
 	fmt.Fprintf(w, "\tName:\t%s\n", set.Branding.Name)
 	fmt.Fprintf(w, "\tFiles override:\t%s\n", set.Branding.Files)
@@ -144,6 +399,8 @@ func printSettings(ser *settings.Server, set *settings.Settings, auther auth.Aut
 	fmt.Fprintf(w, "\tScope:\t%s\n", set.Defaults.Scope)
 	fmt.Fprintf(w, "\tLocale:\t%s\n", set.Defaults.Locale)
 	fmt.Fprintf(w, "\tView mode:\t%s\n", set.Defaults.ViewMode)
+	fmt.Fprintf(w, "\tHide dotfiles:\t%t\n", set.Defaults.HideDotfiles)
+	fmt.Fprintf(w, "\tCommand timeout:\t%s\n", time.Duration(set.Defaults.CommandTimeout)*time.Second)
 	fmt.Fprintf(w, "\tCommands:\t%s\n", strings.Join(set.Defaults.Commands, " "))
 	fmt.Fprintf(w, "\tSorting:\n")
 	fmt.Fprintf(w, "\t\tBy:\t%s\n", set.Defaults.Sorting.By)
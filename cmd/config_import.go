@@ -70,6 +70,10 @@ The path must be for a json or yaml file.`,
 			auther = getAuther(auth.NoAuth{}, rawAuther).(*auth.NoAuth)
 		case auth.MethodProxyAuth:
 			auther = getAuther(auth.ProxyAuth{}, rawAuther).(*auth.ProxyAuth)
+		case auth.MethodOIDCAuth:
+			auther = getAuther(auth.OIDCAuth{}, rawAuther).(*auth.OIDCAuth)
+		case auth.MethodLDAPAuth:
+			auther = getAuther(auth.LDAPAuth{}, rawAuther).(*auth.LDAPAuth)
 		default:
 			checkErr(errors.New("invalid auth method"))
 		}
@@ -30,11 +30,12 @@ override the options.`,
 		authMethod, auther := getAuthentication(flags)
 
 		s := &settings.Settings{
-			Key:        generateKey(),
-			Signup:     mustGetBool(flags, "signup"),
-			Shell:      strings.Split(strings.TrimSpace(mustGetString(flags, "shell")), " "),
-			AuthMethod: authMethod,
-			Defaults:   defaults,
+			Key:         generateKey(),
+			Signup:      mustGetBool(flags, "signup"),
+			Shell:       strings.Split(strings.TrimSpace(mustGetString(flags, "shell")), " "),
+			AuthMethod:  authMethod,
+			RequireTOTP: mustGetBool(flags, "auth.requireTOTP"),
+			Defaults:    defaults,
 			Branding: settings.Branding{
 				Name:            mustGetString(flags, "branding.name"),
 				DisableExternal: mustGetBool(flags, "branding.disableExternal"),
@@ -42,6 +43,12 @@ override the options.`,
 			},
 		}
 
+		getBruteForce(flags, &s.BruteForce, true)
+		getPasswordPolicy(flags, &s.Password, true)
+		getCommandHistory(flags, &s.History, true)
+		s.ForceHideDotfiles = mustGetBool(flags, "forceHideDotfiles")
+		s.MaxTextSize = mustGetInt64(flags, "maxTextSize")
+
 		ser := &settings.Server{
 			Address: mustGetString(flags, "address"),
 			Socket:  mustGetString(flags, "socket"),
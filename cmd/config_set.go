@@ -49,6 +49,12 @@ you want to change. Other options will remain unchanged.`,
 				set.Signup = mustGetBool(flags, flag.Name)
 			case "auth.method":
 				hasAuth = true
+			case "auth.requireTOTP":
+				set.RequireTOTP = mustGetBool(flags, flag.Name)
+			case "forceHideDotfiles":
+				set.ForceHideDotfiles = mustGetBool(flags, flag.Name)
+			case "maxTextSize":
+				set.MaxTextSize = mustGetInt64(flags, flag.Name)
 			case "shell":
 				set.Shell = strings.Split(strings.TrimSpace(mustGetString(flags, flag.Name)), " ")
 			case "branding.name":
@@ -61,6 +67,9 @@ you want to change. Other options will remain unchanged.`,
 		})
 
 		getUserDefaults(flags, &set.Defaults, false)
+		getBruteForce(flags, &set.BruteForce, false)
+		getPasswordPolicy(flags, &set.Password, false)
+		getCommandHistory(flags, &set.History, false)
 
 		// read the defaults
 		auther, err := d.store.Auth.Get(set.AuthMethod)
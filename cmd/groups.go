@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/filebrowser/filebrowser/v2/groups"
+	"github.com/filebrowser/filebrowser/v2/storage"
+)
+
+func init() {
+	rootCmd.AddCommand(groupsCmd)
+}
+
+var groupsCmd = &cobra.Command{
+	Use:   "groups",
+	Short: "Groups management utility",
+	Long: `Groups management utility. The members of a group get its
+permissions and commands in addition to their own ones, and
+its rules are checked before their own ones.`,
+	Args: cobra.NoArgs,
+}
+
+func printGroups(list []*groups.Group) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tName\tPermissions\tCommands\tRules")
+
+	for _, g := range list {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t\n",
+			g.ID,
+			g.Name,
+			formatPermissions(&g.Perm),
+			strings.Join(g.Commands, " "),
+			len(g.Rules),
+		)
+	}
+
+	w.Flush()
+}
+
+func getGroupByArg(st *storage.Storage, arg string) *groups.Group {
+	name, id := parseUsernameOrID(arg)
+
+	var (
+		err error
+		g   *groups.Group
+	)
+
+	if id != 0 {
+		g, err = st.Groups.Get(id)
+	} else {
+		g, err = st.Groups.Get(name)
+	}
+
+	checkErr(err)
+	return g
+}
+
+func addGroupFlags(flags *pflag.FlagSet) {
+	flags.Bool("perm.admin", false, "admin perm for the members")
+	flags.Bool("perm.execute", false, "execute perm for the members")
+	flags.Bool("perm.create", false, "create perm for the members")
+	flags.Bool("perm.rename", false, "rename perm for the members")
+	flags.Bool("perm.modify", false, "modify perm for the members")
+	flags.Bool("perm.delete", false, "delete perm for the members")
+	flags.Bool("perm.share", false, "share perm for the members")
+	flags.Bool("perm.download", false, "download perm for the members")
+	flags.StringSlice("commands", nil, "a list of the commands the members can execute")
+}
+
+func getGroupFlags(flags *pflag.FlagSet, g *groups.Group) {
+	flags.Visit(func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "perm.admin":
+			g.Perm.Admin = mustGetBool(flags, flag.Name)
+		case "perm.execute":
+			g.Perm.Execute = mustGetBool(flags, flag.Name)
+		case "perm.create":
+			g.Perm.Create = mustGetBool(flags, flag.Name)
+		case "perm.rename":
+			g.Perm.Rename = mustGetBool(flags, flag.Name)
+		case "perm.modify":
+			g.Perm.Modify = mustGetBool(flags, flag.Name)
+		case "perm.delete":
+			g.Perm.Delete = mustGetBool(flags, flag.Name)
+		case "perm.share":
+			g.Perm.Share = mustGetBool(flags, flag.Name)
+		case "perm.download":
+			g.Perm.Download = mustGetBool(flags, flag.Name)
+		case "commands":
+			commands, err := flags.GetStringSlice(flag.Name)
+			checkErr(err)
+			g.Commands = commands
+		}
+	})
+}
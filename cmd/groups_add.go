@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/groups"
+)
+
+func init() {
+	groupsCmd.AddCommand(groupsAddCmd)
+	addGroupFlags(groupsAddCmd.Flags())
+	addSandboxFlags(groupsAddCmd.Flags())
+}
+
+var groupsAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Create a new group",
+	Long:  `Create a new group and add it to the database.`,
+	Args:  cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		g := &groups.Group{Name: args[0]}
+		getGroupFlags(cmd.Flags(), g)
+		g.Sandbox = getSandbox(cmd.Flags(), nil)
+
+		err := d.store.Groups.Save(g)
+		checkErr(err)
+		printGroups([]*groups.Group{g})
+	}, pythonConfig{}),
+}
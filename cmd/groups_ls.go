@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/groups"
+)
+
+func init() {
+	groupsCmd.AddCommand(groupsLsCmd)
+}
+
+var groupsLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List all groups",
+	Long:  `List all groups.`,
+	Args:  cobra.NoArgs,
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		list, err := d.store.Groups.Gets()
+		if err != errors.ErrNotExist {
+			checkErr(err)
+		}
+
+		if list == nil {
+			list = []*groups.Group{}
+		}
+
+		printGroups(list)
+	}, pythonConfig{}),
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+func init() {
+	groupsCmd.AddCommand(groupsJoinCmd)
+	groupsCmd.AddCommand(groupsLeaveCmd)
+	groupsCmd.AddCommand(groupsMembersCmd)
+}
+
+var groupsJoinCmd = &cobra.Command{
+	Use:   "join <group> <user>",
+	Short: "Add a user to a group",
+	Long:  `Add a user to a group, by their names or ids.`,
+	Args:  cobra.ExactArgs(2), //nolint:mnd
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		g := getGroupByArg(d.store, args[0])
+		user := getUserByArg(d.store, args[1])
+
+		if !user.InGroup(g.ID) {
+			user.Groups = append(user.Groups, g.ID)
+			err := d.store.Users.Update(user, "Groups")
+			checkErr(err)
+		}
+
+		fmt.Printf("user %s is member of %s\n", user.Username, g.Name)
+	}, pythonConfig{}),
+}
+
+var groupsLeaveCmd = &cobra.Command{
+	Use:   "leave <group> <user>",
+	Short: "Remove a user from a group",
+	Long:  `Remove a user from a group, by their names or ids.`,
+	Args:  cobra.ExactArgs(2), //nolint:mnd
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		g := getGroupByArg(d.store, args[0])
+		user := getUserByArg(d.store, args[1])
+
+		groups := []uint{}
+		for _, id := range user.Groups {
+			if id != g.ID {
+				groups = append(groups, id)
+			}
+		}
+
+		user.Groups = groups
+		err := d.store.Users.Update(user, "Groups")
+		checkErr(err)
+		fmt.Printf("user %s is no longer member of %s\n", user.Username, g.Name)
+	}, pythonConfig{}),
+}
+
+var groupsMembersCmd = &cobra.Command{
+	Use:   "members <id|name>",
+	Short: "List the members of a group",
+	Long:  `List the members of a group.`,
+	Args:  cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		g := getGroupByArg(d.store, args[0])
+
+		all, err := d.store.Users.Gets("")
+		if err != errors.ErrNotExist {
+			checkErr(err)
+		}
+
+		members := []*users.User{}
+		for _, user := range all {
+			if user.InGroup(g.ID) {
+				members = append(members, user)
+			}
+		}
+
+		printUsers(members)
+	}, pythonConfig{}),
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	groupsCmd.AddCommand(groupsRmCmd)
+}
+
+var groupsRmCmd = &cobra.Command{
+	Use:   "rm <id|name>",
+	Short: "Delete a group by name or id",
+	Long:  `Delete a group by name or id and remove its members from it.`,
+	Args:  cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		g := getGroupByArg(d.store, args[0])
+
+		err := d.store.Groups.Delete(g.ID)
+		checkErr(err)
+		err = d.store.Users.RemoveGroup(g.ID)
+		checkErr(err)
+		fmt.Println("group deleted successfully")
+	}, pythonConfig{}),
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/groups"
+)
+
+func init() {
+	groupsCmd.AddCommand(groupsUpdateCmd)
+
+	groupsUpdateCmd.Flags().StringP("name", "n", "", "new name")
+	addGroupFlags(groupsUpdateCmd.Flags())
+	addSandboxFlags(groupsUpdateCmd.Flags())
+}
+
+var groupsUpdateCmd = &cobra.Command{
+	Use:   "update <id|name>",
+	Short: "Updates an existing group",
+	Long: `Updates an existing group. Set the flags for the
+options you want to change.`,
+	Args: cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		g := getGroupByArg(d.store, args[0])
+		getGroupFlags(cmd.Flags(), g)
+		g.Sandbox = getSandbox(cmd.Flags(), g.Sandbox)
+
+		if name := mustGetString(cmd.Flags(), "name"); name != "" {
+			g.Name = name
+		}
+
+		err := d.store.Groups.Save(g)
+		checkErr(err)
+		printGroups([]*groups.Group{g})
+	}, pythonConfig{}),
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/history"
+)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	flags := historyCmd.Flags()
+	flags.StringP("user", "u", "", "only the commands of this user, by id or username")
+	flags.IntP("limit", "l", 50, "maximum number of commands, 0 for all") //nolint:mnd
+	flags.Int("offset", 0, "number of commands to skip")
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the history of the commands",
+	Long: `Show the commands run by the users, the newest first, with
+their duration in milliseconds and their exit code.`,
+	Args: cobra.NoArgs,
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		flags := cmd.Flags()
+		q := &history.Query{
+			Limit:  mustGetInt(flags, "limit"),
+			Offset: mustGetInt(flags, "offset"),
+		}
+
+		if arg := mustGetString(flags, "user"); arg != "" {
+			q.UserID = getUserByArg(d.store, arg).ID
+		}
+
+		runs, err := d.store.History.Find(q)
+		checkErr(err)
+		printRuns(runs)
+	}, pythonConfig{}),
+}
+
+func printRuns(runs []*history.Run) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tStart\tUsername\tDir\tCommand\tTerminal\tDuration\tExit\tError")
+
+	for _, r := range runs {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%t\t%d\t%d\t%s\t\n",
+			r.ID,
+			time.Unix(0, r.Start*int64(time.Millisecond)).Format(time.RFC3339),
+			r.Username,
+			r.Dir,
+			r.Command,
+			r.Terminal,
+			r.End-r.Start,
+			r.ExitCode,
+			r.Error,
+		)
+	}
+
+	w.Flush()
+}
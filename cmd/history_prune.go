@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/history"
+)
+
+func init() {
+	historyCmd.AddCommand(historyPruneCmd)
+	historyPruneCmd.Flags().Duration("olderThan", 30*24*time.Hour, "age of the commands to delete") //nolint:mnd
+}
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete the old commands of the history",
+	Long: `Delete the commands of the history older than the given age.
+The history is also pruned, according to the retention settings,
+each time a command is run.`,
+	Args: cobra.NoArgs,
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		age := mustGetDuration(cmd.Flags(), "olderThan")
+
+		count, err := d.store.History.Prune(history.Millis(time.Now().Add(-age)))
+		checkErr(err)
+		fmt.Printf("%d commands deleted\n", count)
+	}, pythonConfig{}),
+}
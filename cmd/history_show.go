@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/history"
+)
+
+func init() {
+	historyCmd.AddCommand(historyShowCmd)
+}
+
+var historyShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a command of the history and its output",
+	Long: `Show a command of the history and its output, which is the
+raw output of the terminal for the commands run in one.`,
+	Args: cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		id, err := strconv.ParseUint(args[0], 10, 0)
+		checkErr(err)
+
+		run, err := d.store.History.Get(uint(id))
+		checkErr(err)
+
+		printRuns([]*history.Run{run})
+		fmt.Println()
+		fmt.Print(run.Output)
+		if run.Truncated {
+			fmt.Println("\n[output truncated]")
+		}
+	}, pythonConfig{}),
+}
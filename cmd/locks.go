@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/locks"
+)
+
+func init() {
+	rootCmd.AddCommand(locksCmd)
+}
+
+var locksCmd = &cobra.Command{
+	Use:   "locks",
+	Short: "File locks management utility",
+	Long: `File locks management utility. The users lock the files
+they edit, which keeps the other users from changing them
+until the locks are released or expire.`,
+	Args: cobra.NoArgs,
+}
+
+func printLocks(list []*locks.Lock) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Token\tPath\tUsername\tCreated\tExpires")
+
+	for _, l := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n",
+			l.Token,
+			l.Path,
+			l.Username,
+			formatUnix(l.Created),
+			formatUnix(l.Expire),
+		)
+	}
+
+	w.Flush()
+}
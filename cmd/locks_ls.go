@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	locksCmd.AddCommand(locksLsCmd)
+}
+
+var locksLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the file locks",
+	Long:  `List the file locks that haven't expired.`,
+	Args:  cobra.NoArgs,
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		list, err := d.store.Locks.Gets()
+		checkErr(err)
+
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].Created < list[j].Created
+		})
+
+		printLocks(list)
+	}, pythonConfig{}),
+}
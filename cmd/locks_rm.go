@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/locks"
+)
+
+func init() {
+	locksCmd.AddCommand(locksRmCmd)
+}
+
+var locksRmCmd = &cobra.Command{
+	Use:   "rm <token|path>",
+	Short: "Release a file lock",
+	Long: `Release a file lock, whoever holds it, by its token or by
+the full path of the locked file.`,
+	Args: cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		var (
+			l   *locks.Lock
+			err error
+		)
+
+		if filepath.IsAbs(args[0]) {
+			l, err = d.store.Locks.GetByPath(args[0])
+		} else {
+			l, err = d.store.Locks.Get(args[0])
+		}
+		checkErr(err)
+
+		err = d.store.Locks.Delete(l.Token)
+		checkErr(err)
+		fmt.Println("lock released successfully")
+	}, pythonConfig{}),
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+func init() {
+	usersCmd.AddCommand(usersMountCmd)
+	usersCmd.AddCommand(usersUnmountCmd)
+	groupsCmd.AddCommand(groupsMountCmd)
+	groupsCmd.AddCommand(groupsUnmountCmd)
+
+	usersMountCmd.Flags().Bool("readOnly", false, "mount the folder as read-only")
+	groupsMountCmd.Flags().Bool("readOnly", false, "mount the folder as read-only")
+}
+
+func printMounts(mounts []users.Mount) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tPath\tRead-only")
+
+	for _, m := range mounts {
+		fmt.Fprintf(w, "%s\t%s\t%t\t\n", m.Name, m.Path, m.ReadOnly)
+	}
+
+	w.Flush()
+}
+
+// addMount adds a mount, replacing the one with the same name if any.
+func addMount(mounts []users.Mount, mount users.Mount) []users.Mount {
+	for i, m := range mounts {
+		if m.Name == mount.Name {
+			mounts[i] = mount
+			return mounts
+		}
+	}
+
+	return append(mounts, mount)
+}
+
+func removeMount(mounts []users.Mount, name string) []users.Mount {
+	list := []users.Mount{}
+	for _, m := range mounts {
+		if m.Name != name {
+			list = append(list, m)
+		}
+	}
+
+	return list
+}
+
+var usersMountCmd = &cobra.Command{
+	Use:   "mount <id|username> <name> <path>",
+	Short: "Mount a folder in the scope of a user",
+	Long: `Mount a folder of the server as a top-level folder named <name>
+in the scope of a user. The path can be relative to the root.`,
+	Args: cobra.ExactArgs(3), //nolint:mnd
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		user := getUserByArg(d.store, args[0])
+		user.Mounts = addMount(user.Mounts, users.Mount{
+			Name:     args[1],
+			Path:     args[2],
+			ReadOnly: mustGetBool(cmd.Flags(), "readOnly"),
+		})
+
+		err := d.store.Users.Update(user, "Mounts")
+		checkErr(err)
+		printMounts(user.Mounts)
+	}, pythonConfig{}),
+}
+
+var usersUnmountCmd = &cobra.Command{
+	Use:   "unmount <id|username> <name>",
+	Short: "Remove a mount of a user",
+	Long:  `Remove a mount of a user. The mounted folder isn't deleted.`,
+	Args:  cobra.ExactArgs(2), //nolint:mnd
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		user := getUserByArg(d.store, args[0])
+		user.Mounts = removeMount(user.Mounts, args[1])
+
+		err := d.store.Users.Update(user, "Mounts")
+		checkErr(err)
+		printMounts(user.Mounts)
+	}, pythonConfig{}),
+}
+
+var groupsMountCmd = &cobra.Command{
+	Use:   "mount <id|name> <name> <path>",
+	Short: "Mount a folder in the scope of the members of a group",
+	Long: `Mount a folder of the server as a top-level folder named <name>
+in the scope of the members of a group. The path can be relative
+to the root. The mounts of the users override the ones of their
+groups with the same name.`,
+	Args: cobra.ExactArgs(3), //nolint:mnd
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		g := getGroupByArg(d.store, args[0])
+		g.Mounts = addMount(g.Mounts, users.Mount{
+			Name:     args[1],
+			Path:     args[2],
+			ReadOnly: mustGetBool(cmd.Flags(), "readOnly"),
+		})
+
+		err := d.store.Groups.Save(g)
+		checkErr(err)
+		printMounts(g.Mounts)
+	}, pythonConfig{}),
+}
+
+var groupsUnmountCmd = &cobra.Command{
+	Use:   "unmount <id|name> <name>",
+	Short: "Remove a mount of a group",
+	Long:  `Remove a mount of a group. The mounted folder isn't deleted.`,
+	Args:  cobra.ExactArgs(2), //nolint:mnd
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		g := getGroupByArg(d.store, args[0])
+		g.Mounts = removeMount(g.Mounts, args[1])
+
+		err := d.store.Groups.Save(g)
+		checkErr(err)
+		printMounts(g.Mounts)
+	}, pythonConfig{}),
+}
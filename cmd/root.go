@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"io/ioutil"
@@ -12,6 +13,7 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
@@ -20,16 +22,30 @@ import (
 	lumberjack "gopkg.in/natefinch/lumberjack.v2"
 
 	"github.com/filebrowser/filebrowser/v2/auth"
+	"github.com/filebrowser/filebrowser/v2/expiry"
 	fbhttp "github.com/filebrowser/filebrowser/v2/http"
 	"github.com/filebrowser/filebrowser/v2/settings"
 	"github.com/filebrowser/filebrowser/v2/storage"
 	"github.com/filebrowser/filebrowser/v2/users"
+	"github.com/filebrowser/filebrowser/v2/webhooks"
 )
 
 var (
 	cfgFile string
 )
 
+const (
+	// shareSweepInterval is how often the expired share links are purged.
+	shareSweepInterval = time.Hour
+	// sessionSweepInterval is how often the expired sessions are purged.
+	sessionSweepInterval = time.Hour
+	// lockSweepInterval is how often the expired file locks are purged.
+	lockSweepInterval = time.Hour
+	// webhooksShutdownTimeout is how long the events already dispatched
+	// can take to be sent on shutdown.
+	webhooksShutdownTimeout = 10 * time.Second
+)
+
 func init() {
 	cobra.OnInitialize(initConfig)
 
@@ -128,27 +144,40 @@ user created with the credentials from options "username" and "password".`,
 			checkErr(err)
 		}
 
-		sigc := make(chan os.Signal, 1)
-		signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
-		go cleanupHandler(listener, sigc)
+		stopSweeper := make(chan struct{})
+		defer close(stopSweeper)
+		go expiry.StartSweeper("share", d.store.Share.Sweep, shareSweepInterval, stopSweeper)
+		go expiry.StartSweeper("session", d.store.Sessions.Sweep, sessionSweepInterval, stopSweeper)
+		go expiry.StartSweeper("lock", d.store.Locks.Sweep, lockSweepInterval, stopSweeper)
 
-		handler, err := fbhttp.NewHandler(d.store, server)
+		dispatcher := webhooks.NewDispatcher(d.store.Webhooks)
+		handler, err := fbhttp.NewHandler(d.store, server, dispatcher)
 		checkErr(err)
 
+		srv := &http.Server{Handler: handler}
+		sigc := make(chan os.Signal, 1)
+		signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+		go cleanupHandler(srv, sigc)
+
 		defer listener.Close()
 
 		log.Println("Listening on", listener.Addr().String())
-		if err := http.Serve(listener, handler); err != nil {
+		if err := srv.Serve(listener); err != http.ErrServerClosed {
 			log.Fatal(err)
 		}
+
+		// The events already dispatched are still sent to the webhooks,
+		// for a while.
+		ctx, cancel := context.WithTimeout(context.Background(), webhooksShutdownTimeout)
+		defer cancel()
+		dispatcher.Close(ctx)
 	}, pythonConfig{allowNoDB: true}),
 }
 
-func cleanupHandler(listener net.Listener, c chan os.Signal) { //nolint:interfacer
+func cleanupHandler(srv *http.Server, c chan os.Signal) {
 	sig := <-c
 	log.Printf("Caught signal %s: shutting down.", sig)
-	listener.Close()
-	os.Exit(0)
+	srv.Close()
 }
 
 //nolint:gocyclo
@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
+	"github.com/filebrowser/filebrowser/v2/groups"
 	"github.com/filebrowser/filebrowser/v2/rules"
 	"github.com/filebrowser/filebrowser/v2/settings"
 	"github.com/filebrowser/filebrowser/v2/storage"
@@ -16,20 +18,33 @@ func init() {
 	rootCmd.AddCommand(rulesCmd)
 	rulesCmd.PersistentFlags().StringP("username", "u", "", "username of user to which the rules apply")
 	rulesCmd.PersistentFlags().UintP("id", "i", 0, "id of user to which the rules apply")
+	rulesCmd.PersistentFlags().StringP("group", "g", "", "name or id of group to which the rules apply")
 }
 
 var rulesCmd = &cobra.Command{
 	Use:   "rules",
 	Short: "Rules management utility",
-	Long: `On each subcommand you'll have available at least two flags:
-"username" and "id". You must either set only one of them
-or none. If you set one of them, the command will apply to
-an user, otherwise it will be applied to the global set or
-rules.`,
+	Long: `On each subcommand you'll have available at least three flags:
+"username", "id" and "group". You must either set only one of
+them or none. If you set "username" or "id", the command will
+apply to an user, if you set "group", it will apply to a group,
+otherwise it will be applied to the global set or rules.`,
 	Args: cobra.NoArgs,
 }
 
-func runRules(st *storage.Storage, cmd *cobra.Command, usersFn func(*users.User), globalFn func(*settings.Settings)) {
+func runRules(st *storage.Storage, cmd *cobra.Command, usersFn func(*users.User),
+	groupsFn func(*groups.Group), globalFn func(*settings.Settings)) {
+	if arg := mustGetString(cmd.Flags(), "group"); arg != "" {
+		g := getGroupByArg(st, arg)
+
+		if groupsFn != nil {
+			groupsFn(g)
+		}
+
+		printRules(g.Rules, "group "+g.Name)
+		return
+	}
+
 	id := getUserIdentifier(cmd.Flags())
 	if id != nil {
 		user, err := st.Users.Get("", id)
@@ -39,7 +54,7 @@ func runRules(st *storage.Storage, cmd *cobra.Command, usersFn func(*users.User)
 			usersFn(user)
 		}
 
-		printRules(user.Rules, id)
+		printRules(user.Rules, fmt.Sprintf("user %v", id))
 		return
 	}
 
@@ -50,7 +65,7 @@ func runRules(st *storage.Storage, cmd *cobra.Command, usersFn func(*users.User)
 		globalFn(s)
 	}
 
-	printRules(s.Rules, id)
+	printRules(s.Rules, "")
 }
 
 func getUserIdentifier(flags *pflag.FlagSet) interface{} {
@@ -66,27 +81,46 @@ func getUserIdentifier(flags *pflag.FlagSet) interface{} {
 	return nil
 }
 
-func printRules(rulez []rules.Rule, id interface{}) {
-	if id == nil {
+func printRules(rulez []rules.Rule, owner string) {
+	if owner == "" {
 		fmt.Printf("Global Rules:\n\n")
 	} else {
-		fmt.Printf("Rules for user %v:\n\n", id)
+		fmt.Printf("Rules for %s:\n\n", owner)
 	}
 
-	for id, rule := range rulez {
-		fmt.Printf("(%d) ", id)
-		if rule.Regex {
-			if rule.Allow {
-				fmt.Printf("Allow Regex: \t%s\n", rule.Regexp.Raw)
-			} else {
-				fmt.Printf("Disallow Regex: \t%s\n", rule.Regexp.Raw)
-			}
-		} else {
-			if rule.Allow {
-				fmt.Printf("Allow Path: \t%s\n", rule.Path)
-			} else {
-				fmt.Printf("Disallow Path: \t%s\n", rule.Path)
-			}
-		}
+	for id := range rulez {
+		fmt.Printf("(%d) %s\n", id, formatRule(&rulez[id]))
+	}
+}
+
+func formatRule(rule *rules.Rule) string {
+	kind, exp := "Path", rule.Path
+	switch {
+	case rule.Regex:
+		kind, exp = "Regex", rule.Regexp.Raw
+	case rule.Glob:
+		kind = "Glob"
 	}
+
+	verb := "Disallow"
+	if rule.Allow {
+		verb = "Allow"
+	}
+
+	s := fmt.Sprintf("%s %s: \t%s", verb, kind, exp)
+
+	if rule.IsActionRule() {
+		s += fmt.Sprintf("\t(%s)", formatActions(rule.Actions))
+	}
+
+	return s
+}
+
+func formatActions(actions []rules.Action) string {
+	names := make([]string, len(actions))
+	for i, action := range actions {
+		names[i] = string(action)
+	}
+
+	return strings.Join(names, ", ")
 }
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"regexp"
 
 	"github.com/spf13/cobra"
 
+	"github.com/filebrowser/filebrowser/v2/groups"
 	"github.com/filebrowser/filebrowser/v2/rules"
 	"github.com/filebrowser/filebrowser/v2/settings"
 	"github.com/filebrowser/filebrowser/v2/users"
@@ -14,25 +17,52 @@ func init() {
 	rulesCmd.AddCommand(rulesAddCmd)
 	rulesAddCmd.Flags().BoolP("allow", "a", false, "indicates this is an allow rule")
 	rulesAddCmd.Flags().BoolP("regex", "r", false, "indicates this is a regex rule")
+	rulesAddCmd.Flags().Bool("glob", false, "indicates this is a glob rule (gitignore-style, e.g. **/*.key or /build/)")
+	rulesAddCmd.Flags().StringSlice("actions", nil, "actions the rule applies to instead of the visibility (create, rename, modify, delete, download, share, execute)")
 }
 
 var rulesAddCmd = &cobra.Command{
 	Use:   "add <path|expression>",
-	Short: "Add a global rule or user rule",
-	Long:  `Add a global rule or user rule.`,
-	Args:  cobra.ExactArgs(1),
+	Short: "Add a global rule, user rule or group rule",
+	Long: `Add a global rule, user rule or group rule.
+
+By default, a rule decides whether the matching paths are visible. With
+--actions, the paths stay visible and the rule only decides whether these
+actions can be done on them. For example, a read-only folder:
+
+  filebrowser rules add /contracts --actions create,rename,modify,delete
+
+By default, the rule applies to the paths starting with the given one. With
+--glob, it is a gitignore-style pattern: "*" and "?" don't match "/", "**"
+matches any number of folders, a pattern without a "/" other than a trailing
+one matches at any depth, and a pattern matching a folder matches its content.
+For example, "/secret" matches "/secret" and "/secret/a" but not "/secret-not".`,
+	Args: cobra.ExactArgs(1),
 	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
 		allow := mustGetBool(cmd.Flags(), "allow")
 		regex := mustGetBool(cmd.Flags(), "regex")
+		glob := mustGetBool(cmd.Flags(), "glob")
 		exp := args[0]
 
+		if regex && glob {
+			checkErr(errors.New("a rule can't be both a regex and a glob rule"))
+		}
+
 		if regex {
 			regexp.MustCompile(exp)
 		}
 
+		names, err := cmd.Flags().GetStringSlice("actions")
+		checkErr(err)
+
+		actions, err := parseActions(names)
+		checkErr(err)
+
 		rule := rules.Rule{
-			Allow: allow,
-			Regex: regex,
+			Allow:   allow,
+			Regex:   regex,
+			Glob:    glob,
+			Actions: actions,
 		}
 
 		if regex {
@@ -47,12 +77,33 @@ var rulesAddCmd = &cobra.Command{
 			checkErr(err)
 		}
 
+		group := func(g *groups.Group) {
+			g.Rules = append(g.Rules, rule)
+			err := d.store.Groups.Save(g)
+			checkErr(err)
+		}
+
 		global := func(s *settings.Settings) {
 			s.Rules = append(s.Rules, rule)
 			err := d.store.Settings.Save(s)
 			checkErr(err)
 		}
 
-		runRules(d.store, cmd, user, global)
+		runRules(d.store, cmd, user, group, global)
 	}, pythonConfig{}),
 }
+
+func parseActions(names []string) ([]rules.Action, error) {
+	actions := []rules.Action{}
+
+	for _, name := range names {
+		action, ok := rules.ParseAction(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid action %q", name)
+		}
+
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/groups"
+	"github.com/filebrowser/filebrowser/v2/rules"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+func init() {
+	rulesCmd.AddCommand(rulesTestCmd)
+}
+
+var rulesTestCmd = &cobra.Command{
+	Use:   "test <path>",
+	Short: "Explain which rules apply to a path",
+	Long: `Explain which rules apply to a path, relative to the scope, and
+which one decides whether it is visible and whether each action can be
+done on it.
+
+Without flags, only the global rules are considered. With "group", the
+rules of the group are added and, with "username" or "id", the rules of
+the groups of the user and its own rules as well as its permissions.`,
+	Args: cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		path := args[0]
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+
+		s, err := d.store.Settings.Get()
+		checkErr(err)
+
+		sets := []ruleSet{{owner: "global", rules: s.Rules}}
+		var perm *users.Permissions
+
+		if arg := mustGetString(cmd.Flags(), "group"); arg != "" {
+			g := getGroupByArg(d.store, arg)
+			sets = append(sets, ruleSet{owner: "group " + g.Name, rules: g.Rules})
+		} else if id := getUserIdentifier(cmd.Flags()); id != nil {
+			user, err := d.store.Users.Get("", id) //nolint:shadow
+			checkErr(err)
+
+			gs, err := d.store.Groups.ForUser(user)
+			checkErr(err)
+
+			for _, g := range gs {
+				sets = append(sets, ruleSet{owner: "group " + g.Name, rules: g.Rules})
+			}
+
+			sets = append(sets, ruleSet{owner: "user " + user.Username, rules: user.Rules})
+			groups.Apply(user, gs)
+			perm = &user.Perm
+		}
+
+		fmt.Printf("Matching rules for %s:\n\n", path)
+		matched := false
+		for _, set := range sets {
+			for i := range set.rules {
+				if set.rules[i].Matches(path) {
+					matched = true
+					fmt.Printf("%s rule (%d) \t%s\n", set.owner, i, formatRule(&set.rules[i]))
+				}
+			}
+		}
+
+		if !matched {
+			fmt.Println("none")
+		}
+
+		fmt.Printf("\nVisible: %s\n", explainDecision(sets, path, ""))
+		for _, action := range rules.Actions {
+			if perm != nil && !actionPermission(perm, action) {
+				fmt.Printf("%s: no, the user doesn't have the permission\n", action)
+				continue
+			}
+
+			fmt.Printf("%s: %s\n", action, explainDecision(sets, path, action))
+		}
+	}, pythonConfig{}),
+}
+
+type ruleSet struct {
+	owner string
+	rules []rules.Rule
+}
+
+// explainDecision tells the outcome of the rules for the action on the
+// path, and which rule decided it. As when checking the rules, the last
+// rule deciding wins.
+func explainDecision(sets []ruleSet, path string, action rules.Action) string {
+	decision := "yes, no rule decides it"
+	for _, set := range sets {
+		for i := range set.rules {
+			rule := &set.rules[i]
+			if !rule.Decides(path, action) {
+				continue
+			}
+
+			answer := "no"
+			if rule.Allow {
+				answer = "yes"
+			}
+
+			decision = fmt.Sprintf("%s, decided by %s rule (%d)", answer, set.owner, i)
+		}
+	}
+
+	return decision
+}
+
+func actionPermission(perm *users.Permissions, action rules.Action) bool {
+	switch action {
+	case rules.ActionCreate:
+		return perm.Create
+	case rules.ActionRename:
+		return perm.Rename
+	case rules.ActionModify:
+		return perm.Modify
+	case rules.ActionDelete:
+		return perm.Delete
+	case rules.ActionDownload:
+		return perm.Download
+	case rules.ActionShare:
+		return perm.Share
+	case rules.ActionExecute:
+		return perm.Execute
+	default:
+		return false
+	}
+}
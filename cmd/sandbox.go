@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+func addSandboxFlags(flags *pflag.FlagSet) {
+	flags.Bool("sandbox", false, "run the commands in a sandbox")
+	flags.Uint("sandbox.uid", 0, "user ID the commands are run as, 0 for the one of the server")
+	flags.Uint("sandbox.gid", 0, "group ID the commands are run as, 0 for the one of the server")
+	flags.StringSlice("sandbox.env", users.DefaultSandboxEnv, "environment variables of the server the commands inherit")
+	flags.String("sandbox.root", "", "directory the commands are chrooted in, containing the scope")
+	flags.Duration("sandbox.cpu", 0, "CPU time limit of the commands, 0 for no limit")
+	flags.Uint("sandbox.memory", 0, "memory limit of the commands in MiB, 0 for no limit")
+	flags.Uint("sandbox.files", 0, "open files limit of the commands, 0 for no limit")
+	flags.Bool("sandbox.isolate", false, "run the commands in new namespaces, without network (Linux only)")
+}
+
+// getSandbox updates a sandbox, which may be nil, with the sandbox flags
+// that were set. It returns the sandbox unchanged if none were.
+func getSandbox(flags *pflag.FlagSet, sandbox *users.Sandbox) *users.Sandbox {
+	changed := false
+	flags.Visit(func(flag *pflag.Flag) {
+		changed = changed || flag.Name == "sandbox" || strings.HasPrefix(flag.Name, "sandbox.")
+	})
+
+	if !changed {
+		return sandbox
+	}
+
+	s := &users.Sandbox{Enabled: true}
+	if sandbox != nil {
+		*s = *sandbox
+	}
+
+	flags.Visit(func(flag *pflag.Flag) {
+		switch flag.Name {
+		case "sandbox":
+			s.Enabled = mustGetBool(flags, flag.Name)
+		case "sandbox.uid":
+			s.UID = uint32(mustGetUint(flags, flag.Name))
+		case "sandbox.gid":
+			s.GID = uint32(mustGetUint(flags, flag.Name))
+		case "sandbox.env":
+			env, err := flags.GetStringSlice(flag.Name)
+			checkErr(err)
+			s.Env = env
+		case "sandbox.root":
+			s.Root = mustGetString(flags, flag.Name)
+		case "sandbox.cpu":
+			s.CPUTime = uint64(mustGetDuration(flags, flag.Name).Seconds())
+		case "sandbox.memory":
+			s.Memory = uint64(mustGetUint(flags, flag.Name)) << 20 //nolint:mnd
+		case "sandbox.files":
+			s.OpenFiles = uint64(mustGetUint(flags, flag.Name))
+		case "sandbox.isolate":
+			s.Isolate = mustGetBool(flags, flag.Name)
+		}
+	})
+
+	return s
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/tokens"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+func init() {
+	rootCmd.AddCommand(tokensCmd)
+}
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "Personal access tokens management utility",
+	Long: `Personal access tokens management utility. The tokens can be
+used by scripts instead of a username and a password by sending
+them in the X-Auth header or as a bearer token.`,
+	Args: cobra.NoArgs,
+}
+
+func printTokens(list []*tokens.Token) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tName\tUser ID\tCreated\tLast Used\tExpires\tPermissions")
+
+	for _, t := range list {
+		perm := "all"
+		if t.Perm != nil {
+			perm = formatPermissions(t.Perm)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%s\t%s\t\n",
+			t.ID,
+			t.Name,
+			t.UserID,
+			formatUnix(t.Created),
+			formatUnix(t.LastUsed),
+			formatUnix(t.Expire),
+			perm,
+		)
+	}
+
+	w.Flush()
+}
+
+func formatUnix(t int64) string {
+	if t == 0 {
+		return "-"
+	}
+
+	return time.Unix(t, 0).Format(time.RFC3339)
+}
+
+func formatPermissions(p *users.Permissions) string {
+	var names []string
+	for name, ok := range map[string]bool{
+		"admin":    p.Admin,
+		"execute":  p.Execute,
+		"create":   p.Create,
+		"rename":   p.Rename,
+		"modify":   p.Modify,
+		"delete":   p.Delete,
+		"share":    p.Share,
+		"download": p.Download,
+	} {
+		if ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
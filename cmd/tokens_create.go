@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/tokens"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+func init() {
+	tokensCmd.AddCommand(tokensCreateCmd)
+	tokensCreateCmd.Flags().Duration("expires", 0, "time after which the token expires, such as 720h (never by default)")
+	tokensCreateCmd.Flags().StringSlice("perm", nil, "permissions granted by the token, among the user's ones (all by default)")
+}
+
+var tokensCreateCmd = &cobra.Command{
+	Use:   "create <id|username> <name>",
+	Short: "Create a personal access token",
+	Long: `Create a personal access token for a user. The token is
+only printed once. The permissions are a comma separated list
+of admin, execute, create, rename, modify, delete, share and
+download.`,
+	Args: cobra.ExactArgs(2), //nolint:mnd
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		flags := cmd.Flags()
+		user := getUserByArg(d.store, args[0])
+
+		expires, err := flags.GetDuration("expires")
+		checkErr(err)
+
+		var expire int64
+		if expires > 0 {
+			expire = time.Now().Add(expires).Unix()
+		}
+
+		var perm *users.Permissions
+		if flags.Changed("perm") {
+			names, err := flags.GetStringSlice("perm") //nolint:shadow
+			checkErr(err)
+			perm, err = parsePermissions(names)
+			checkErr(err)
+		}
+
+		raw, t, err := d.store.Tokens.Create(user.ID, args[1], expire, perm)
+		checkErr(err)
+
+		printTokens([]*tokens.Token{t})
+		fmt.Printf("\nToken (it won't be shown again):\n\n%s\n", raw)
+	}, pythonConfig{}),
+}
+
+func parsePermissions(names []string) (*users.Permissions, error) {
+	perm := &users.Permissions{}
+
+	for _, name := range names {
+		switch name {
+		case "admin":
+			perm.Admin = true
+		case "execute":
+			perm.Execute = true
+		case "create":
+			perm.Create = true
+		case "rename":
+			perm.Rename = true
+		case "modify":
+			perm.Modify = true
+		case "delete":
+			perm.Delete = true
+		case "share":
+			perm.Share = true
+		case "download":
+			perm.Download = true
+		default:
+			return nil, fmt.Errorf("invalid permission %q", name)
+		}
+	}
+
+	return perm, nil
+}
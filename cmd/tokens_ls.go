@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+func init() {
+	tokensCmd.AddCommand(tokensLsCmd)
+}
+
+var tokensLsCmd = &cobra.Command{
+	Use:   "ls <id|username>",
+	Short: "List the personal access tokens of a user",
+	Long:  `List the personal access tokens of a user.`,
+	Args:  cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		user := getUserByArg(d.store, args[0])
+
+		list, err := d.store.Tokens.FindByUserID(user.ID)
+		if err != errors.ErrNotExist {
+			checkErr(err)
+		}
+
+		printTokens(list)
+	}, pythonConfig{}),
+}
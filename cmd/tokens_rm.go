@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	tokensCmd.AddCommand(tokensRmCmd)
+}
+
+var tokensRmCmd = &cobra.Command{
+	Use:   "rm <id>",
+	Short: "Revoke a personal access token",
+	Long:  `Revoke a personal access token by its id.`,
+	Args:  cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		id, err := strconv.ParseUint(args[0], 10, 0)
+		checkErr(err)
+
+		err = d.store.Tokens.Delete(uint(id))
+		checkErr(err)
+		fmt.Println("token revoked successfully")
+	}, pythonConfig{}),
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/filebrowser/filebrowser/v2/settings"
+	"github.com/filebrowser/filebrowser/v2/storage"
 	"github.com/filebrowser/filebrowser/v2/users"
 )
 
@@ -59,6 +60,25 @@ func parseUsernameOrID(arg string) (username string, id uint) {
 	return "", uint(id64)
 }
 
+// getUserByArg gets the user identified by an id or a username.
+func getUserByArg(st *storage.Storage, arg string) *users.User {
+	username, id := parseUsernameOrID(arg)
+
+	var (
+		err  error
+		user *users.User
+	)
+
+	if id != 0 {
+		user, err = st.Users.Get("", id)
+	} else {
+		user, err = st.Users.Get("", username)
+	}
+
+	checkErr(err)
+	return user
+}
+
 func addUserFlags(flags *pflag.FlagSet) {
 	flags.Bool("perm.admin", false, "admin perm for users")
 	flags.Bool("perm.execute", false, "execute perm for users")
@@ -75,6 +95,8 @@ func addUserFlags(flags *pflag.FlagSet) {
 	flags.String("scope", ".", "scope for users")
 	flags.String("locale", "fr", "locale for users")
 	flags.String("viewMode", string(users.ListViewMode), "view mode for users")
+	flags.Bool("hideDotfiles", false, "hide the dotfiles to users")
+	flags.Duration("commandTimeout", 0, "time after which the commands of users are killed, 0 for no limit")
 }
 
 func getViewMode(flags *pflag.FlagSet) users.ViewMode {
@@ -119,6 +141,10 @@ func getUserDefaults(flags *pflag.FlagSet, defaults *settings.UserDefaults, all
 			defaults.Sorting.By = mustGetString(flags, flag.Name)
 		case "sorting.asc":
 			defaults.Sorting.Asc = mustGetBool(flags, flag.Name)
+		case "hideDotfiles":
+			defaults.HideDotfiles = mustGetBool(flags, flag.Name)
+		case "commandTimeout":
+			defaults.CommandTimeout = int64(mustGetDuration(flags, flag.Name).Seconds())
 		}
 	}
 
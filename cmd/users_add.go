@@ -9,6 +9,8 @@ import (
 func init() {
 	usersCmd.AddCommand(usersAddCmd)
 	addUserFlags(usersAddCmd.Flags())
+	addSandboxFlags(usersAddCmd.Flags())
+	usersAddCmd.Flags().Bool("mustChangePassword", false, "require the user to change the password at the next login")
 }
 
 var usersAddCmd = &cobra.Command{
@@ -21,16 +23,21 @@ var usersAddCmd = &cobra.Command{
 		checkErr(err)
 		getUserDefaults(cmd.Flags(), &s.Defaults, false)
 
+		err = s.Password.Check(args[0], args[1])
+		checkErr(err)
+
 		password, err := users.HashPwd(args[1])
 		checkErr(err)
 
 		user := &users.User{
-			Username:     args[0],
-			Password:     password,
-			LockPassword: mustGetBool(cmd.Flags(), "lockPassword"),
+			Username:           args[0],
+			Password:           password,
+			LockPassword:       mustGetBool(cmd.Flags(), "lockPassword"),
+			MustChangePassword: mustGetBool(cmd.Flags(), "mustChangePassword"),
 		}
 
 		s.Defaults.Apply(user)
+		user.Sandbox = getSandbox(cmd.Flags(), nil)
 
 		servSettings, err := d.store.Settings.GetServer()
 		checkErr(err)
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	usersCmd.AddCommand(usersLogoutCmd)
+}
+
+var usersLogoutCmd = &cobra.Command{
+	Use:   "logout <id|username>",
+	Short: "Log a user out everywhere",
+	Long: `Log a user out everywhere by username or id, revoking all
+of its sessions. Its personal access tokens remain valid.`,
+	Args: cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		user := getUserByArg(d.store, args[0])
+
+		err := d.store.Sessions.DeleteByUserID(user.ID)
+		checkErr(err)
+		fmt.Println("user logged out successfully")
+	}, pythonConfig{}),
+}
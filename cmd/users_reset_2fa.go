@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	usersCmd.AddCommand(usersReset2FACmd)
+}
+
+var usersReset2FACmd = &cobra.Command{
+	Use:   "reset-2fa <id|username>",
+	Short: "Reset the two-factor authentication of a user",
+	Long: `Reset the two-factor authentication of a user by username or id.
+The user will be able to login with its password only and, if 2FA is
+required, will have to set it up again.`,
+	Args: cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		user := getUserByArg(d.store, args[0])
+
+		user.ResetTOTP()
+		err := d.store.Users.Update(user, "TOTPEnabled", "TOTPSecret", "RecoveryCodes")
+		checkErr(err)
+		fmt.Println("two-factor authentication reset successfully")
+	}, pythonConfig{}),
+}
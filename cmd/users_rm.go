@@ -16,15 +16,15 @@ var usersRmCmd = &cobra.Command{
 	Long:  `Delete a user by username or id`,
 	Args:  cobra.ExactArgs(1),
 	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
-		username, id := parseUsernameOrID(args[0])
-		var err error
+		user := getUserByArg(d.store, args[0])
 
-		if username != "" {
-			err = d.store.Users.Delete(username)
-		} else {
-			err = d.store.Users.Delete(id)
-		}
+		err := d.store.Users.Delete(user.ID)
+		checkErr(err)
 
+		// The IDs of the deleted users can be reused.
+		err = d.store.Tokens.DeleteByUserID(user.ID)
+		checkErr(err)
+		err = d.store.Sessions.DeleteByUserID(user.ID)
 		checkErr(err)
 		fmt.Println("user deleted successfully")
 	}, pythonConfig{}),
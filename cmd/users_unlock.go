@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	usersCmd.AddCommand(usersUnlockCmd)
+}
+
+var usersUnlockCmd = &cobra.Command{
+	Use:   "unlock <id|username>",
+	Short: "Unlock a user locked after too many failed logins",
+	Long: `Unlock a user locked after too many failed logins by username
+or id, and reset its count of failed logins.`,
+	Args: cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		user := getUserByArg(d.store, args[0])
+
+		user.FailedLogins = 0
+		user.LockedUntil = 0
+		err := d.store.Users.Update(user, "FailedLogins", "LockedUntil")
+		checkErr(err)
+		fmt.Println("user unlocked successfully")
+	}, pythonConfig{}),
+}
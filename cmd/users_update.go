@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"github.com/filebrowser/filebrowser/v2/settings"
@@ -12,7 +15,11 @@ func init() {
 
 	usersUpdateCmd.Flags().StringP("password", "p", "", "new password")
 	usersUpdateCmd.Flags().StringP("username", "u", "", "new username")
+	usersUpdateCmd.Flags().Bool("mustChangePassword", false, "require the user to change the password at the next login")
+	usersUpdateCmd.Flags().String("oidcIssuer", "", "issuer of the OpenID Connect account to link the user to")
+	usersUpdateCmd.Flags().String("oidcSubject", "", "subject of the OpenID Connect account to link the user to")
 	addUserFlags(usersUpdateCmd.Flags())
+	addSandboxFlags(usersUpdateCmd.Flags())
 }
 
 var usersUpdateCmd = &cobra.Command{
@@ -47,6 +54,9 @@ options you want to change.`,
 			Perm:     user.Perm,
 			Sorting:  user.Sorting,
 			Commands: user.Commands,
+
+			HideDotfiles:   user.HideDotfiles,
+			CommandTimeout: user.CommandTimeout,
 		}
 		getUserDefaults(flags, &defaults, false)
 		user.Scope = defaults.Scope
@@ -55,19 +65,46 @@ options you want to change.`,
 		user.Perm = defaults.Perm
 		user.Commands = defaults.Commands
 		user.Sorting = defaults.Sorting
+		user.HideDotfiles = defaults.HideDotfiles
+		user.CommandTimeout = defaults.CommandTimeout
 		user.LockPassword = mustGetBool(flags, "lockPassword")
+		user.Sandbox = getSandbox(flags, user.Sandbox)
 
 		if newUsername != "" {
 			user.Username = newUsername
 		}
 
+		if flags.Changed("mustChangePassword") {
+			user.MustChangePassword = mustGetBool(flags, "mustChangePassword")
+		}
+
+		if flags.Changed("oidcIssuer") {
+			user.OIDCIssuer = strings.TrimSuffix(mustGetString(flags, "oidcIssuer"), "/")
+		}
+
+		if flags.Changed("oidcSubject") {
+			user.OIDCSubject = mustGetString(flags, "oidcSubject")
+		}
+
 		if password != "" {
+			s, err := d.store.Settings.Get() //nolint:shadow
+			checkErr(err)
+			err = s.Password.Check(user.Username, password)
+			checkErr(err)
+
 			user.Password, err = users.HashPwd(password)
 			checkErr(err)
+			user.PasswordChanged = time.Now().Unix()
 		}
 
 		err = d.store.Users.Update(user)
 		checkErr(err)
+
+		if password != "" {
+			err = d.store.Sessions.DeleteByUserID(user.ID)
+			checkErr(err)
+		}
+
 		printUsers([]*users.User{user})
 	}, pythonConfig{}),
 }
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/asdine/storm"
 	"github.com/spf13/cobra"
@@ -36,6 +37,24 @@ func mustGetBool(flags *pflag.FlagSet, flag string) bool {
 	return b
 }
 
+func mustGetInt(flags *pflag.FlagSet, flag string) int {
+	i, err := flags.GetInt(flag)
+	checkErr(err)
+	return i
+}
+
+func mustGetInt64(flags *pflag.FlagSet, flag string) int64 {
+	i, err := flags.GetInt64(flag)
+	checkErr(err)
+	return i
+}
+
+func mustGetDuration(flags *pflag.FlagSet, flag string) time.Duration {
+	d, err := flags.GetDuration(flag)
+	checkErr(err)
+	return d
+}
+
 func mustGetUint(flags *pflag.FlagSet, flag string) uint {
 	b, err := flags.GetUint(flag)
 	checkErr(err)
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/webhooks"
+)
+
+func init() {
+	rootCmd.AddCommand(webhooksCmd)
+}
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Webhooks management utility",
+	Long: `Webhooks management utility. The file events are sent to the
+webhooks as JSON payloads signed, in the ` + webhooks.SignatureHeader + `
+header, with the HMAC-SHA256 of the body keyed with their secret.`,
+	Args: cobra.NoArgs,
+}
+
+func printWebhooks(list []*webhooks.Webhook, showSecrets bool) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tURL\tEvents\tSecret\tCreated")
+
+	for _, hook := range list {
+		events := strings.Join(hook.Events, " ")
+		if events == "" {
+			events = "all"
+		}
+
+		secret := "-"
+		if showSecrets {
+			secret = hook.Secret
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t\n",
+			hook.ID,
+			hook.URL,
+			events,
+			secret,
+			time.Unix(hook.Created, 0).Format(time.RFC3339),
+		)
+	}
+
+	w.Flush()
+}
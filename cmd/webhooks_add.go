@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/filebrowser/filebrowser/v2/webhooks"
+)
+
+func init() {
+	webhooksCmd.AddCommand(webhooksAddCmd)
+	webhooksAddCmd.Flags().StringSlice("events", nil, "events sent to the webhook (default all)")
+	webhooksAddCmd.Flags().String("secret", "", "secret to sign the payloads with (default generated)")
+}
+
+var webhooksAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Add a webhook",
+	Long: `Add a webhook receiving the given events, such as upload,
+save, rename, copy and delete. A secret is generated if none
+is given and is only shown here.`,
+	Args: cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		events, err := cmd.Flags().GetStringSlice("events")
+		checkErr(err)
+
+		hook := &webhooks.Webhook{
+			URL:    args[0],
+			Secret: mustGetString(cmd.Flags(), "secret"),
+			Events: events,
+		}
+
+		err = d.store.Webhooks.Save(hook)
+		checkErr(err)
+		printWebhooks([]*webhooks.Webhook{hook}, true)
+	}, pythonConfig{}),
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	webhooksCmd.AddCommand(webhooksDeliveriesCmd)
+	webhooksDeliveriesCmd.Flags().IntP("limit", "l", 20, "maximum number of deliveries to show") //nolint:mnd
+}
+
+var webhooksDeliveriesCmd = &cobra.Command{
+	Use:   "deliveries <id>",
+	Short: "Show the delivery log of a webhook",
+	Long:  `Show the last deliveries to a webhook, the newest first.`,
+	Args:  cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		id, err := strconv.ParseUint(args[0], 10, 0)
+		checkErr(err)
+
+		_, err = d.store.Webhooks.Get(uint(id))
+		checkErr(err)
+
+		list, err := d.store.Webhooks.Deliveries(uint(id), mustGetInt(cmd.Flags(), "limit"))
+		checkErr(err)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTime\tEvent\tAttempts\tStatus\tDelivered\tError")
+
+		for _, delivery := range list {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%t\t%s\t\n",
+				delivery.ID,
+				time.Unix(delivery.Time, 0).Format(time.RFC3339),
+				delivery.Event,
+				delivery.Attempts,
+				delivery.StatusCode,
+				delivery.Delivered,
+				delivery.Error,
+			)
+		}
+
+		w.Flush()
+	}, pythonConfig{}),
+}
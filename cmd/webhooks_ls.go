@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	webhooksCmd.AddCommand(webhooksLsCmd)
+	webhooksLsCmd.Flags().Bool("secrets", false, "show the secrets of the webhooks")
+}
+
+var webhooksLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List all webhooks",
+	Long:  `List all webhooks.`,
+	Args:  cobra.NoArgs,
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		list, err := d.store.Webhooks.Gets()
+		checkErr(err)
+		printWebhooks(list, mustGetBool(cmd.Flags(), "secrets"))
+	}, pythonConfig{}),
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	webhooksCmd.AddCommand(webhooksRmCmd)
+}
+
+var webhooksRmCmd = &cobra.Command{
+	Use:   "rm <id>",
+	Short: "Delete a webhook",
+	Long:  `Delete a webhook, and its delivery log, by its id.`,
+	Args:  cobra.ExactArgs(1),
+	Run: python(func(cmd *cobra.Command, args []string, d pythonData) {
+		id, err := strconv.ParseUint(args[0], 10, 0)
+		checkErr(err)
+
+		err = d.store.Webhooks.Delete(uint(id))
+		checkErr(err)
+		fmt.Println("webhook deleted successfully")
+	}, pythonConfig{}),
+}
@@ -8,6 +8,8 @@ var (
 	ErrNotExist             = errors.New("the resource does not exist")
 	ErrEmptyPassword        = errors.New("password is empty")
 	ErrEmptyUsername        = errors.New("username is empty")
+	ErrEmptyName            = errors.New("name is empty")
+	ErrInvalidMount         = errors.New("invalid mount")
 	ErrEmptyRequest         = errors.New("empty request")
 	ErrScopeIsRelative      = errors.New("scope is a relative path")
 	ErrInvalidDataType      = errors.New("invalid data type")
@@ -16,4 +18,18 @@ var (
 	ErrInvalidAuthMethod    = errors.New("invalid auth method")
 	ErrPermissionDenied     = errors.New("permission denied")
 	ErrInvalidRequestParams = errors.New("invalid request params")
+	ErrTOTPRequired         = errors.New("two-factor authentication code required")
+	ErrPasswordTooShort     = errors.New("password is too short")
+	ErrPasswordTooWeak      = errors.New("password doesn't contain the required characters")
+	ErrPasswordDenied       = errors.New("password is not allowed")
+	ErrInvalidWebhook       = errors.New("invalid webhook")
+	ErrPTYUnsupported       = errors.New("pseudo terminals are not supported on this platform")
+	ErrSandboxUnsupported   = errors.New("the sandbox is not supported on this platform")
+	ErrOutsideSandbox       = errors.New("the scope is outside of the sandbox root")
+	ErrInvalidSandbox       = errors.New("the sandbox sets a uid without a gid")
+	ErrInvalidAction        = errors.New("invalid action")
+	ErrInvalidActionParam   = errors.New("invalid action parameter")
+	ErrPreconditionFailed   = errors.New("the resource was modified")
+	ErrNotText              = errors.New("the file is not a text file")
+	ErrLocked               = errors.New("the resource is locked")
 )
@@ -0,0 +1,40 @@
+package expiry
+
+import (
+	"log"
+	"time"
+)
+
+// Passed checks if an expiration date, given as a unix time, has
+// already passed at the given unix time. A zero expiration date
+// never passes.
+func Passed(expire, now int64) bool {
+	return expire != 0 && expire <= now
+}
+
+// SweepFunc deletes the expired entries of a storage and returns
+// how many were deleted.
+type SweepFunc func() (int, error)
+
+// StartSweeper runs sweep every interval until the stop channel is
+// closed. The name is the one of the swept entries, used in the logs.
+// It is meant to be run on its own goroutine.
+func StartSweeper(name string, sweep SweepFunc, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := sweep()
+		if err != nil {
+			log.Printf("%s sweeper: %v", name, err)
+		} else if n > 0 {
+			log.Printf("%s sweeper: deleted %d expired entries", name, n)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
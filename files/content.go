@@ -0,0 +1,285 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+// contentChunk is the size of the blocks text files are read by.
+const contentChunk = 64 * 1024
+
+// ContentRange is the part of a text file given in the content of a
+// FileInfo: the bytes from Offset to End, excluded, which hold Lines
+// lines. Line is the number of the first one, from 1, when the part
+// was asked by lines. Size is the size of the file when it was read.
+type ContentRange struct {
+	Offset int64 `json:"offset"`
+	End    int64 `json:"end"`
+	Line   int64 `json:"line,omitempty"`
+	Lines  int64 `json:"lines"`
+	Size   int64 `json:"size"`
+}
+
+// ContentOptions selects the part of a text file to read: Length bytes
+// from Offset, Lines lines from Line, counted from 1, or the last Tail
+// lines. The parts are limited to MaxSize bytes, if set, as well as the
+// lengths, which are MaxSize by default. The byte ranges are adjusted
+// not to split characters.
+type ContentOptions struct {
+	Offset  int64
+	Length  int64
+	Line    int64
+	Lines   int64
+	Tail    int64
+	MaxSize int64
+}
+
+// ReadContent reads a part of a text file in its content.
+func (i *FileInfo) ReadContent(opts ContentOptions) error {
+	if i.IsDir {
+		return errors.ErrIsDirectory
+	}
+
+	if i.Type != "text" && i.Type != "textImmutable" {
+		return errors.ErrNotText
+	}
+
+	file, err := i.Fs.Open(i.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	max := opts.MaxSize
+	if max <= 0 {
+		max = info.Size()
+	}
+
+	var (
+		content []byte
+		r       = &ContentRange{Size: info.Size()}
+	)
+
+	switch {
+	case opts.Tail > 0:
+		content, r.Offset, err = readTail(file, info.Size(), opts.Tail, max)
+	case opts.Line > 0:
+		r.Line = opts.Line
+		content, r.Offset, err = readLines(file, opts.Line, opts.Lines, max)
+	default:
+		length := opts.Length
+		if length <= 0 || length > max {
+			length = max
+		}
+		content, r.Offset, err = readBytes(file, info.Size(), opts.Offset, length)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	r.End = r.Offset + int64(len(content))
+	r.Lines = countLines(content)
+	i.Content = string(content)
+	i.Range = r
+	return nil
+}
+
+// readBytes reads length bytes from offset, without the bytes of the
+// characters cut at the start and at the end, and returns them along
+// with the offset they start at.
+func readBytes(f io.ReadSeeker, size, offset, length int64) ([]byte, int64, error) {
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset > size {
+		offset = size
+	}
+
+	if length > size-offset {
+		length = size - offset
+	}
+
+	_, err := f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	buf := make([]byte, length)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, 0, err
+	}
+	buf = buf[:n]
+
+	start := 0
+	for offset > 0 && start < len(buf) && start < utf8.UTFMax && !utf8.RuneStart(buf[start]) {
+		start++
+	}
+
+	if offset+int64(n) < size {
+		buf = trimPartialRune(buf)
+	}
+
+	if start > len(buf) {
+		start = len(buf)
+	}
+
+	return buf[start:], offset + int64(start), nil
+}
+
+// readLines reads count lines from the line number first, counted from
+// 1, up to max bytes, and returns them along with their offset.
+func readLines(r io.Reader, first, count, max int64) ([]byte, int64, error) {
+	br := bufio.NewReaderSize(r, contentChunk)
+	offset := int64(0)
+
+	for line := int64(1); line < first; {
+		b, err := br.ReadSlice('\n')
+		offset += int64(len(b))
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+
+		if err == io.EOF {
+			return []byte{}, offset, nil
+		} else if err != nil {
+			return nil, 0, err
+		}
+		line++
+	}
+
+	var (
+		content []byte
+		lines   int64
+	)
+
+	for (count <= 0 || lines < count) && int64(len(content)) < max {
+		b, err := br.ReadSlice('\n')
+		content = append(content, b...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, 0, err
+		}
+		lines++
+	}
+
+	if int64(len(content)) > max {
+		content = trimPartialRune(content[:max])
+	}
+
+	return content, offset, nil
+}
+
+// readTail reads the last count lines of a file, up to max bytes, and
+// returns them along with their offset.
+func readTail(f io.ReaderAt, size, count, max int64) ([]byte, int64, error) {
+	var (
+		chunks [][]byte
+		read   int64
+		lines  int64
+		pos    = size
+	)
+
+	join := func(offset int64) ([]byte, int64, error) {
+		content := make([]byte, 0, read)
+		for j := len(chunks) - 1; j >= 0; j-- {
+			content = append(content, chunks[j]...)
+		}
+		return limitTail(content, offset, max)
+	}
+
+	for pos > 0 && read < max {
+		n := int64(contentChunk)
+		if n > pos {
+			n = pos
+		}
+		pos -= n
+
+		chunk := make([]byte, n)
+		_, err := f.ReadAt(chunk, pos)
+		if err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+
+		// The last new line of the file doesn't start a line.
+		for j := len(chunk) - 1; j >= 0; j-- {
+			if chunk[j] != '\n' || pos+int64(j) == size-1 {
+				continue
+			}
+
+			lines++
+			if lines == count {
+				chunks = append(chunks, chunk[j+1:])
+				read += n - int64(j) - 1
+				return join(pos + int64(j) + 1)
+			}
+		}
+
+		chunks = append(chunks, chunk)
+		read += n
+	}
+
+	return join(pos)
+}
+
+// limitTail keeps the last max bytes of the end of a file read from
+// offset, from the start of the first line they hold whole, if any, or
+// else without the bytes of the character cut at the start.
+func limitTail(content []byte, offset, max int64) ([]byte, int64, error) {
+	if cut := int64(len(content)) - max; cut > 0 {
+		content = content[cut:]
+		offset += cut
+
+		if i := bytes.IndexByte(content, '\n'); i >= 0 && i < len(content)-1 {
+			content = content[i+1:]
+			offset += int64(i) + 1
+		}
+
+		for start := 0; start < utf8.UTFMax && len(content) > 0 && !utf8.RuneStart(content[0]); start++ {
+			content = content[1:]
+			offset++
+		}
+	}
+
+	return content, offset, nil
+}
+
+// trimPartialRune removes the bytes of the character cut at the end.
+func trimPartialRune(b []byte) []byte {
+	for k := 1; k <= utf8.UTFMax && k <= len(b); k++ {
+		if utf8.RuneStart(b[len(b)-k]) {
+			if !utf8.FullRune(b[len(b)-k:]) {
+				return b[:len(b)-k]
+			}
+			break
+		}
+	}
+
+	return b
+}
+
+// countLines counts the lines of a text, the last one being ended or not.
+func countLines(b []byte) int64 {
+	n := int64(bytes.Count(b, []byte{'\n'}))
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		n++
+	}
+
+	return n
+}
@@ -0,0 +1,116 @@
+package files
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+func newTextFile(t *testing.T, content string) *FileInfo {
+	fs := afero.NewMemMapFs()
+	err := afero.WriteFile(fs, "/a.txt", []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &FileInfo{Fs: fs, Path: "/a.txt", Type: "text"}
+}
+
+func TestReadContent(t *testing.T) {
+	// The last line has no new line.
+	const text = "one\ntwo\nthree\nfour\nfive"
+
+	tests := []struct {
+		name    string
+		content string
+		opts    ContentOptions
+		want    string
+		r       ContentRange
+	}{
+		{"whole", text, ContentOptions{}, text,
+			ContentRange{Offset: 0, End: 23, Lines: 5, Size: 23}},
+		{"bytes", text, ContentOptions{Offset: 4, Length: 3}, "two",
+			ContentRange{Offset: 4, End: 7, Lines: 1, Size: 23}},
+		{"bytes to the end", text, ContentOptions{Offset: 19, Length: 100}, "five",
+			ContentRange{Offset: 19, End: 23, Lines: 1, Size: 23}},
+		{"bytes past the end", text, ContentOptions{Offset: 100}, "",
+			ContentRange{Offset: 23, End: 23, Size: 23}},
+		{"bytes over max", text, ContentOptions{Length: 100, MaxSize: 5}, "one\nt",
+			ContentRange{Offset: 0, End: 5, Lines: 2, Size: 23}},
+		{"lines", text, ContentOptions{Line: 2, Lines: 2}, "two\nthree\n",
+			ContentRange{Offset: 4, End: 14, Line: 2, Lines: 2, Size: 23}},
+		{"last line", text, ContentOptions{Line: 5, Lines: 3}, "five",
+			ContentRange{Offset: 19, End: 23, Line: 5, Lines: 1, Size: 23}},
+		{"lines past the end", text, ContentOptions{Line: 10}, "",
+			ContentRange{Offset: 23, End: 23, Line: 10, Size: 23}},
+		{"lines over max", text, ContentOptions{Line: 1, MaxSize: 6}, "one\ntw",
+			ContentRange{Offset: 0, End: 6, Line: 1, Lines: 2, Size: 23}},
+		{"tail", text, ContentOptions{Tail: 2}, "four\nfive",
+			ContentRange{Offset: 14, End: 23, Lines: 2, Size: 23}},
+		{"tail of all", text, ContentOptions{Tail: 10}, text,
+			ContentRange{Offset: 0, End: 23, Lines: 5, Size: 23}},
+		{"tail with final new line", "a\nb\n", ContentOptions{Tail: 1}, "b\n",
+			ContentRange{Offset: 2, End: 4, Lines: 1, Size: 4}},
+		{"tail over max", text, ContentOptions{Tail: 2, MaxSize: 6}, "five",
+			ContentRange{Offset: 19, End: 23, Lines: 1, Size: 23}},
+		{"tail of empty", "", ContentOptions{Tail: 2}, "",
+			ContentRange{}},
+
+		// The characters aren't split.
+		{"cut start", "héllo", ContentOptions{Offset: 2, Length: 10}, "llo",
+			ContentRange{Offset: 3, End: 6, Lines: 1, Size: 6}},
+		{"cut end", "héllo", ContentOptions{Length: 2}, "h",
+			ContentRange{Offset: 0, End: 1, Lines: 1, Size: 6}},
+	}
+
+	for _, tt := range tests {
+		file := newTextFile(t, tt.content)
+		if err := file.ReadContent(tt.opts); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if file.Content != tt.want {
+			t.Errorf("%s: got content %q, want %q", tt.name, file.Content, tt.want)
+		}
+
+		if *file.Range != tt.r {
+			t.Errorf("%s: got range %+v, want %+v", tt.name, *file.Range, tt.r)
+		}
+	}
+}
+
+func TestReadContentTailChunks(t *testing.T) {
+	// The file is read back from its end by several chunks.
+	var b strings.Builder
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&b, "line %05d\n", i)
+	}
+	text := b.String()
+	size := int64(len(text))
+
+	for _, count := range []int64{3, 7000} {
+		file := newTextFile(t, text)
+		if err := file.ReadContent(ContentOptions{Tail: count}); err != nil {
+			t.Fatal(err)
+		}
+
+		want := text[size-11*count:]
+		if file.Content != want || file.Range.Offset != size-11*count || file.Range.Lines != count {
+			t.Errorf("tail %d: got %d bytes from %d, %d lines", count, len(file.Content), file.Range.Offset, file.Range.Lines)
+		}
+	}
+}
+
+func TestReadContentNotText(t *testing.T) {
+	file := newTextFile(t, "a")
+	file.Type = "blob"
+
+	if err := file.ReadContent(ContentOptions{Tail: 1}); err != errors.ErrNotText {
+		t.Errorf("got error %v, want %v", err, errors.ErrNotText)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/spf13/afero"
 
 	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/locks"
 	"github.com/filebrowser/filebrowser/v2/rules"
 )
 
@@ -37,16 +38,23 @@ type FileInfo struct {
 	Type      string            `json:"type"`
 	Subtitles []string          `json:"subtitles,omitempty"`
 	Content   string            `json:"content,omitempty"`
+	Range     *ContentRange     `json:"range,omitempty"`
 	Checksums map[string]string `json:"checksums,omitempty"`
+	Lock      *locks.Lock       `json:"lock,omitempty"`
 }
 
-// FileOptions are the options when getting a file info.
+// FileOptions are the options when getting a file info. The content of
+// the text files is only read if Content is set, when they aren't bigger
+// than MaxTextSize. The bigger ones can't be modified. A MaxTextSize of
+// zero doesn't limit their size.
 type FileOptions struct {
-	Fs      afero.Fs
-	Path    string
-	Modify  bool
-	Expand  bool
-	Checker rules.Checker
+	Fs          afero.Fs
+	Path        string
+	Modify      bool
+	Expand      bool
+	Content     bool
+	MaxTextSize int64
+	Checker     rules.Checker
 }
 
 // NewFileInfo creates a File object from a path and a given user. This File
@@ -75,13 +83,13 @@ func NewFileInfo(opts FileOptions) (*FileInfo, error) {
 
 	if opts.Expand {
 		if file.IsDir {
-			if err := file.readListing(opts.Checker); err != nil { //nolint:shadow
+			if err := file.readListing(opts.Checker, opts.MaxTextSize); err != nil { //nolint:shadow
 				return nil, err
 			}
 			return file, nil
 		}
 
-		err = file.detectType(opts.Modify, true)
+		err = file.detectType(opts.Modify, opts.Content, opts.MaxTextSize)
 		if err != nil {
 			return nil, err
 		}
@@ -134,7 +142,7 @@ func (i *FileInfo) Checksum(algo string) error {
 
 //nolint:goconst
 //TODO: use constants
-func (i *FileInfo) detectType(modify, saveContent bool) error {
+func (i *FileInfo) detectType(modify, saveContent bool, maxTextSize int64) error {
 	// failing to detect the type should not return error.
 	// imagine the situation where a file in a dir with thousands
 	// of files couldn't be opened: we'd have immediately
@@ -171,17 +179,18 @@ func (i *FileInfo) detectType(modify, saveContent bool) error {
 	case strings.HasPrefix(mimetype, "image"):
 		i.Type = "image"
 		return nil
-	case isBinary(buffer[:n], n) || i.Size > 10*1024*1024: // 10 MB
+	case isBinary(buffer[:n], n):
 		i.Type = "blob"
 		return nil
 	default:
 		i.Type = "text"
+		tooBig := maxTextSize > 0 && i.Size > maxTextSize
 
-		if !modify {
+		if !modify || tooBig {
 			i.Type = "textImmutable"
 		}
 
-		if saveContent {
+		if saveContent && !tooBig {
 			afs := &afero.Afero{Fs: i.Fs}
 			content, err := afs.ReadFile(i.Path)
 			if err != nil {
@@ -211,7 +220,7 @@ func (i *FileInfo) detectSubtitles() {
 	}
 }
 
-func (i *FileInfo) readListing(checker rules.Checker) error {
+func (i *FileInfo) readListing(checker rules.Checker, maxTextSize int64) error {
 	afs := &afero.Afero{Fs: i.Fs}
 	dir, err := afs.ReadDir(i.Path)
 	if err != nil {
@@ -257,7 +266,7 @@ func (i *FileInfo) readListing(checker rules.Checker) error {
 		} else {
 			listing.NumFiles++
 
-			err := file.detectType(true, false)
+			err := file.detectType(true, false, maxTextSize)
 			if err != nil {
 				return err
 			}
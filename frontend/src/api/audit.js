@@ -0,0 +1,10 @@
+import { fetchJSON } from './utils'
+
+export async function find (query) {
+  const params = Object.keys(query)
+    .filter(key => query[key] !== '')
+    .map(key => `${key}=${encodeURIComponent(query[key])}`)
+    .join('&')
+
+  return fetchJSON(`/api/audit?${params}`, {})
+}
@@ -2,15 +2,20 @@ import { fetchURL, removePrefix } from './utils'
 import { baseURL } from '@/utils/constants'
 import store from '@/store'
 
-export async function fetch (url) {
+export async function fetch (url, content = false) {
   url = removePrefix(url)
 
-  const res = await fetchURL(`/api/resources${url}`, {})
+  const query = content ? '?content=true' : ''
+  const res = await fetchURL(`/api/resources${url}${query}`, {})
 
   if (res.status === 200) {
     let data = await res.json()
     data.url = `/files${url}`
 
+    if (!data.isDir) {
+      data.etag = res.headers.get('ETag')
+    }
+
     if (data.isDir) {
       if (!data.url.endsWith('/')) data.url += '/'
       data.items = data.items.map((item, index) => {
@@ -31,10 +36,10 @@ export async function fetch (url) {
   }
 }
 
-async function resourceAction (url, method, content) {
+async function resourceAction (url, method, content, headers) {
   url = removePrefix(url)
 
-  let opts = { method }
+  let opts = { method, headers }
 
   if (content) {
     opts.body = content
@@ -43,7 +48,7 @@ async function resourceAction (url, method, content) {
   const res = await fetchURL(`/api/resources${url}`, opts)
 
   if (res.status !== 200) {
-    throw new Error(res.responseText)
+    throw new Error(await res.text())
   } else {
     return res
   }
@@ -53,8 +58,24 @@ export async function remove (url) {
   return resourceAction(url, 'DELETE')
 }
 
-export async function put (url, content = '') {
-  return resourceAction(url, 'PUT', content)
+export async function put (url, content = '', etag) {
+  const headers = etag ? { 'If-Match': etag } : {}
+  return resourceAction(url, 'PUT', content, headers)
+}
+
+export async function merge (url, base, content) {
+  url = removePrefix(url)
+
+  const res = await fetchURL(`/api/merge${url}`, {
+    method: 'POST',
+    body: JSON.stringify({ base, content })
+  })
+
+  if (res.status === 200) {
+    return res.json()
+  } else {
+    throw new Error(await res.text())
+  }
 }
 
 export function download (format, ...files) {
@@ -137,6 +158,17 @@ export function copy (items) {
   return moveCopy(items, true)
 }
 
+// Reads a part of a text file: the bytes from offset, the lines from
+// line, or the last lines with tail, as in { tail: 1000 }.
+export async function content (url, range) {
+  const params = Object.keys(range)
+    .map(key => `${key}=${range[key]}`)
+    .join('&')
+
+  const data = await resourceAction(`${url}?${params}`, 'GET')
+  return data.json()
+}
+
 export async function checksum (url, algo) {
   const data = await resourceAction(`${url}?checksum=${algo}`, 'GET')
   return (await data.json()).checksums[algo]
@@ -0,0 +1,10 @@
+import { fetchJSON } from './utils'
+
+export async function log (query) {
+  const params = Object.keys(query)
+    .filter(key => query[key] !== '' && query[key] !== false)
+    .map(key => `${key}=${encodeURIComponent(query[key])}`)
+    .join('&')
+
+  return fetchJSON(`/api/hooks?${params}`, {})
+}
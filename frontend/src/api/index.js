@@ -1,15 +1,29 @@
 import * as files from './files'
 import * as share from './share'
 import * as users from './users'
+import * as groups from './groups'
 import * as settings from './settings'
+import * as sessions from './sessions'
+import * as totp from './totp'
+import * as audit from './audit'
+import * as hooks from './hooks'
+import * as locks from './locks'
 import search from './search'
 import commands from './commands'
+import tail from './tail'
 
 export {
   files,
   share,
   users,
+  groups,
   settings,
+  sessions,
+  totp,
+  audit,
+  hooks,
+  locks,
   commands,
+  tail,
   search
 }
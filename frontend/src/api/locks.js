@@ -0,0 +1,25 @@
+import { fetchURL, removePrefix } from './utils'
+
+async function lockAction (url, method) {
+  url = removePrefix(url)
+
+  const res = await fetchURL(`/api/locks${url}`, { method })
+
+  if (res.status !== 200) {
+    throw new Error(await res.text())
+  }
+
+  return res
+}
+
+export async function lock (url) {
+  return (await lockAction(url, 'POST')).json()
+}
+
+export async function refresh (url) {
+  return (await lockAction(url, 'PUT')).json()
+}
+
+export async function unlock (url) {
+  return lockAction(url, 'DELETE')
+}
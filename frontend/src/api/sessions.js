@@ -0,0 +1,25 @@
+import { fetchURL, fetchJSON } from './utils'
+
+export async function getAll () {
+  return fetchJSON(`/api/sessions`, {})
+}
+
+export async function remove (id) {
+  const res = await fetchURL(`/api/sessions/${encodeURIComponent(id)}`, {
+    method: 'DELETE'
+  })
+
+  if (res.status !== 200) {
+    throw new Error(res.status)
+  }
+}
+
+export async function removeAll () {
+  const res = await fetchURL(`/api/sessions`, {
+    method: 'DELETE'
+  })
+
+  if (res.status !== 200) {
+    throw new Error(res.status)
+  }
+}
@@ -0,0 +1,18 @@
+import { removePrefix } from './utils'
+import { baseURL } from '@/utils/constants'
+import store from '@/store'
+
+const ssl = (window.location.protocol === 'https:')
+const protocol = (ssl ? 'wss:' : 'ws:')
+
+// Follows a text file from its last lines: onmessage is called with the
+// lines appended to it and when it is truncated or rotated.
+export default function tail (url, lines, onmessage, onclose) {
+  url = removePrefix(url)
+  url = `${protocol}//${window.location.host}${baseURL}/api/tail${url}?lines=${lines}&auth=${store.state.jwt}`
+
+  let conn = new window.WebSocket(url)
+  conn.onmessage = (event) => onmessage(JSON.parse(event.data))
+  conn.onclose = onclose
+  return conn
+}
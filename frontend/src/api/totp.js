@@ -0,0 +1,25 @@
+import { fetchURL, fetchJSON } from './utils'
+
+export async function setup () {
+  return fetchJSON(`/api/totp/setup`, {
+    method: 'POST'
+  })
+}
+
+export async function enable (code) {
+  return fetchJSON(`/api/totp/enable`, {
+    method: 'POST',
+    body: JSON.stringify({ code })
+  })
+}
+
+export async function disable (code) {
+  const res = await fetchURL(`/api/totp`, {
+    method: 'DELETE',
+    body: JSON.stringify({ code })
+  })
+
+  if (res.status !== 200) {
+    throw new Error(res.status)
+  }
+}
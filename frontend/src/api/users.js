@@ -50,3 +50,24 @@ export async function remove (id) {
     throw new Error(res.status)
   }
 }
+
+export async function logout (id) {
+  const res = await fetchURL(`/api/users/${id}/sessions`, {
+    method: 'DELETE'
+  })
+
+  if (res.status !== 200) {
+    throw new Error(res.status)
+  }
+}
+
+export async function changePassword (current, password) {
+  const res = await fetchURL(`/api/password`, {
+    method: 'POST',
+    body: JSON.stringify({ current, password })
+  })
+
+  if (res.status !== 200) {
+    throw new Error(res.status)
+  }
+}
@@ -6,6 +6,10 @@ import Files from '@/views/Files'
 import Share from '@/views/Share'
 import Users from '@/views/settings/Users'
 import User from '@/views/settings/User'
+import Groups from '@/views/settings/Groups'
+import Audit from '@/views/settings/Audit'
+import HookLog from '@/views/settings/HookLog'
+import Group from '@/views/settings/Group'
 import Settings from '@/views/Settings'
 import GlobalSettings from '@/views/settings/Global'
 import ProfileSettings from '@/views/settings/Profile'
@@ -90,6 +94,38 @@ const router = new Router({
               meta: {
                 requiresAdmin: true
               }
+            },
+            {
+              path: '/settings/groups',
+              name: 'Groups',
+              component: Groups,
+              meta: {
+                requiresAdmin: true
+              }
+            },
+            {
+              path: '/settings/groups/*',
+              name: 'Group',
+              component: Group,
+              meta: {
+                requiresAdmin: true
+              }
+            },
+            {
+              path: '/settings/audit',
+              name: 'Audit',
+              component: Audit,
+              meta: {
+                requiresAdmin: true
+              }
+            },
+            {
+              path: '/settings/hooks',
+              name: 'HookLog',
+              component: HookLog,
+              meta: {
+                requiresAdmin: true
+              }
             }
           ]
         },
@@ -136,6 +172,11 @@ router.beforeEach((to, from, next) => {
       return
     }
 
+    if ((store.state.user.totpSetup || store.state.user.passwordChange) && to.path !== '/settings/profile') {
+      next({ path: '/settings/profile' })
+      return
+    }
+
     if (to.matched.some(record => record.meta.requiresAdmin)) {
       if (!store.state.user.perm.admin) {
         next({ path: '/403' })
@@ -27,8 +27,8 @@ export async function validateLogin () {
   }
 }
 
-export async function login (username, password, recaptcha) {
-  const data = { username, password, recaptcha }
+export async function login (username, password, recaptcha, otp) {
+  const data = { username, password, recaptcha, otp }
 
   const res = await fetch(`${baseURL}/api/login`, {
     method: 'POST',
@@ -42,6 +42,8 @@ export async function login (username, password, recaptcha) {
 
   if (res.status === 200) {
     parseToken(body)
+  } else if (res.status === 401 || res.status === 429) {
+    throw new Error(res.status)
   } else {
     throw new Error(body)
   }
@@ -81,6 +83,17 @@ export async function signup (username, password) {
 }
 
 export function logout () {
+  // Revoke the session on the server. The user is logged out
+  // locally in any case.
+  if (store.state.jwt) {
+    fetch(`${baseURL}/api/logout`, {
+      method: 'POST',
+      headers: {
+        'X-Auth': store.state.jwt
+      }
+    }).catch(() => {})
+  }
+
   store.commit('setJWT', '')
   store.commit('setUser', null)
   localStorage.setItem('jwt', null)
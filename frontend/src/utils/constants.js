@@ -10,6 +10,7 @@ const logoURL = `${staticURL}/img/logo.svg`
 const noAuth = window.FileBrowser.NoAuth
 const authMethod = window.FileBrowser.AuthMethod
 const loginPage = window.FileBrowser.LoginPage
+const loginRedirect = window.FileBrowser.LoginRedirect
 const theme = window.FileBrowser.Theme
 
 export {
@@ -24,5 +25,6 @@ export {
   noAuth,
   authMethod,
   loginPage,
+  loginRedirect,
   theme
 }
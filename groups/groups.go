@@ -0,0 +1,118 @@
+package groups
+
+import (
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/rules"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// Group describes a set of permissions, rules and commands
+// shared by its members.
+type Group struct {
+	ID       uint              `storm:"id,increment" json:"id"`
+	Name     string            `storm:"unique" json:"name"`
+	Perm     users.Permissions `json:"perm"`
+	Commands []string          `json:"commands"`
+	Rules    []rules.Rule      `json:"rules"`
+	Mounts   []users.Mount     `json:"mounts"`
+	Sandbox  *users.Sandbox    `json:"sandbox"`
+}
+
+// GetRules implements rules.Provider.
+func (g *Group) GetRules() []rules.Rule {
+	return g.Rules
+}
+
+// Clean verifies if the group is alright to be saved.
+func (g *Group) Clean() error {
+	if g.Name == "" {
+		return errors.ErrEmptyName
+	}
+
+	if g.Commands == nil {
+		g.Commands = []string{}
+	}
+
+	if g.Rules == nil {
+		g.Rules = []rules.Rule{}
+	}
+
+	if g.Mounts == nil {
+		g.Mounts = []users.Mount{}
+	}
+
+	if g.Sandbox != nil {
+		if err := g.Sandbox.Clean(); err != nil {
+			return err
+		}
+	}
+
+	return users.CheckMounts(g.Mounts)
+}
+
+// Apply grants the permissions, the commands and the mounts of the groups
+// to the user, on top of its own ones. The mounts of the user override the
+// ones of the groups with the same name. A user without a sandbox gets the
+// one of its first group having one. It returns whether mounts were
+// added, in which case the file system of the user must be reset. The rules
+// aren't merged: the ones of the groups are checked before the ones of the
+// user, which override them.
+func Apply(u *users.User, groups []*Group) bool {
+	mounted := false
+	for _, g := range groups {
+		u.Perm = union(u.Perm, g.Perm)
+
+		if u.Sandbox == nil && g.Sandbox != nil {
+			sandbox := *g.Sandbox
+			u.Sandbox = &sandbox
+		}
+
+		for _, cmd := range g.Commands {
+			if !contains(u.Commands, cmd) {
+				u.Commands = append(u.Commands, cmd)
+			}
+		}
+
+		for _, m := range g.Mounts {
+			if !hasMount(u.Mounts, m.Name) {
+				u.Mounts = append(u.Mounts, m)
+				mounted = true
+			}
+		}
+	}
+
+	return mounted
+}
+
+func union(a, b users.Permissions) users.Permissions {
+	return users.Permissions{
+		Admin:    a.Admin || b.Admin,
+		Execute:  a.Execute || b.Execute,
+		Create:   a.Create || b.Create,
+		Rename:   a.Rename || b.Rename,
+		Modify:   a.Modify || b.Modify,
+		Delete:   a.Delete || b.Delete,
+		Share:    a.Share || b.Share,
+		Download: a.Download || b.Download,
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasMount(mounts []users.Mount, name string) bool {
+	for _, m := range mounts {
+		if m.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
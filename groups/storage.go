@@ -0,0 +1,68 @@
+package groups
+
+import (
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// StorageBackend is the interface to implement for a groups storage.
+type StorageBackend interface {
+	GetBy(interface{}) (*Group, error)
+	Gets() ([]*Group, error)
+	Save(g *Group) error
+	DeleteByID(uint) error
+}
+
+// Storage is a groups storage.
+type Storage struct {
+	back StorageBackend
+}
+
+// NewStorage creates a groups storage from a backend.
+func NewStorage(back StorageBackend) *Storage {
+	return &Storage{back: back}
+}
+
+// Get allows you to get a group by its name or its ID. The provided id
+// must be a string for name lookup or a uint for id lookup. If id is
+// neither, a ErrInvalidDataType will be returned.
+func (s *Storage) Get(id interface{}) (*Group, error) {
+	return s.back.GetBy(id)
+}
+
+// Gets gets all the groups.
+func (s *Storage) Gets() ([]*Group, error) {
+	return s.back.Gets()
+}
+
+// ForUser gets the groups the user is member of. The groups that
+// no longer exist are ignored.
+func (s *Storage) ForUser(u *users.User) ([]*Group, error) {
+	groups := []*Group{}
+	for _, id := range u.Groups {
+		g, err := s.back.GetBy(id)
+		if err == errors.ErrNotExist {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, g)
+	}
+
+	return groups, nil
+}
+
+// Save saves the group in a storage.
+func (s *Storage) Save(g *Group) error {
+	if err := g.Clean(); err != nil {
+		return err
+	}
+
+	return s.back.Save(g)
+}
+
+// Delete deletes a group by its ID.
+func (s *Storage) Delete(id uint) error {
+	return s.back.DeleteByID(id)
+}
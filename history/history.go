@@ -0,0 +1,79 @@
+package history
+
+import (
+	"sync"
+	"time"
+)
+
+// Run is a record of the history of the commands run by the users. Dir
+// is the working directory, relative to the scope of the user. Start
+// and End are unix times in milliseconds. The output of the commands
+// run in a terminal is the raw output of the terminal.
+type Run struct {
+	ID        uint   `json:"id" storm:"id,increment"`
+	UserID    uint   `json:"userId" storm:"index"`
+	Username  string `json:"username"`
+	Command   string `json:"command"`
+	Dir       string `json:"dir"`
+	Terminal  bool   `json:"terminal"`
+	Start     int64  `json:"start" storm:"index"`
+	End       int64  `json:"end"`
+	ExitCode  int    `json:"exitCode"`
+	Error     string `json:"error,omitempty"`
+	Output    string `json:"output,omitempty"`
+	Truncated bool   `json:"truncated"`
+}
+
+// Millis returns a time as a unix time in milliseconds.
+func Millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// Query filters the history. A zero UserID matches the runs of all
+// the users.
+type Query struct {
+	UserID uint
+	Limit  int
+	Offset int
+}
+
+// Output captures the output of a command, up to a number of bytes.
+// It is safe for concurrent use.
+type Output struct {
+	mu        sync.Mutex
+	buf       []byte
+	max       int64
+	truncated bool
+}
+
+// NewOutput creates an output keeping the first max bytes written to it.
+func NewOutput(max int64) *Output {
+	return &Output{max: max}
+}
+
+func (o *Output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	room := o.max - int64(len(o.buf))
+	switch {
+	case room <= 0:
+		o.truncated = o.truncated || len(p) > 0
+	case int64(len(p)) > room:
+		o.buf = append(o.buf, p[:room]...)
+		o.truncated = true
+	default:
+		o.buf = append(o.buf, p...)
+	}
+
+	return len(p), nil
+}
+
+// Fill sets the output and the truncation of a run.
+func (o *Output) Fill(run *Run) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	run.Output = string(o.buf)
+	run.Truncated = o.truncated
+}
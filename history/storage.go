@@ -0,0 +1,75 @@
+package history
+
+import (
+	"time"
+)
+
+// StorageBackend is the interface to implement for a command
+// history storage.
+type StorageBackend interface {
+	Save(r *Run) error
+	Get(id uint) (*Run, error)
+	Find(q *Query) ([]*Run, error)
+	Delete(id uint) error
+	DeleteBefore(time int64) (int, error)
+	// Trim deletes the runs of a user but the keep newest ones.
+	Trim(userID uint, keep int) error
+}
+
+// Storage is a command history storage.
+type Storage struct {
+	back StorageBackend
+}
+
+// NewStorage creates a command history storage from a backend.
+func NewStorage(back StorageBackend) *Storage {
+	return &Storage{back: back}
+}
+
+// Record saves a run in the history and deletes the runs of the
+// user that are older than maxAge seconds or beyond the maxRuns
+// newest ones.
+func (s *Storage) Record(r *Run, maxAge int64, maxRuns int) error {
+	err := s.back.Save(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Prune(Millis(time.Now().Add(-time.Duration(maxAge) * time.Second)))
+	if err != nil {
+		return err
+	}
+
+	return s.back.Trim(r.UserID, maxRuns)
+}
+
+// Get gets a run by its id.
+func (s *Storage) Get(id uint) (*Run, error) {
+	return s.back.Get(id)
+}
+
+// Find gets the runs matching the query, the newest first,
+// without their outputs.
+func (s *Storage) Find(q *Query) ([]*Run, error) {
+	runs, err := s.back.Find(q)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range runs {
+		r.Output = ""
+	}
+
+	return runs, nil
+}
+
+// Delete deletes a run by its id.
+func (s *Storage) Delete(id uint) error {
+	return s.back.Delete(id)
+}
+
+// Prune deletes the runs started before the given unix time, in
+// milliseconds, and returns how many were deleted.
+func (s *Storage) Prune(before int64) (int, error) {
+	return s.back.DeleteBefore(before)
+}
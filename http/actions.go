@@ -0,0 +1,387 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/filebrowser/filebrowser/v2/actions"
+	"github.com/filebrowser/filebrowser/v2/audit"
+	libErrors "github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/rules"
+	"github.com/filebrowser/filebrowser/v2/runner"
+)
+
+// maxActionLine is the size above which the lines of the outputs
+// of the actions are split.
+const maxActionLine = 64 * 1024
+
+type actionRunRequest struct {
+	Files  []string          `json:"files"`
+	Params map[string]string `json:"params"`
+}
+
+// actionEvent is a line of the stream of the run of an action. The
+// events are "start" and "exit" for each file, "output" for each line
+// the command prints, including the progress lines ended by carriage
+// returns, and "done" once all the files were processed.
+type actionEvent struct {
+	Type   string `json:"type"`
+	File   string `json:"file,omitempty"`
+	Index  int    `json:"index"`
+	Total  int    `json:"total"`
+	Stream string `json:"stream,omitempty"`
+	Data   string `json:"data,omitempty"`
+	Code   int    `json:"code"`
+	Error  string `json:"error,omitempty"`
+	Failed int    `json:"failed,omitempty"`
+}
+
+// actionStream writes the events of the run of an action as
+// newline delimited JSON, flushing each of them.
+type actionStream struct {
+	mu  sync.Mutex
+	w   http.ResponseWriter
+	enc *json.Encoder
+}
+
+func (s *actionStream) send(e *actionEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_ = s.enc.Encode(e)
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func getActionID(r *http.Request) (uint, error) {
+	i, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 0)
+	if err != nil {
+		return 0, err
+	}
+	return uint(i), err
+}
+
+func getAction(r *http.Request) (*actions.Action, error) {
+	if r.Body == nil {
+		return nil, libErrors.ErrEmptyRequest
+	}
+
+	a := &actions.Action{}
+	err := json.NewDecoder(r.Body).Decode(a)
+	return a, err
+}
+
+// saveAction saves an action. The reason an action is invalid is
+// written in the response, for it to be fixed.
+func saveAction(w http.ResponseWriter, d *data, a *actions.Action) (int, error) {
+	err := d.store.Actions.Save(a)
+	switch {
+	case errors.Is(err, libErrors.ErrInvalidAction):
+		return invalid(w, err)
+	case err == libErrors.ErrEmptyName:
+		return http.StatusBadRequest, err
+	case err != nil:
+		return errToStatus(err), err
+	}
+
+	return 0, nil
+}
+
+// invalid writes a bad request error along with its reason.
+func invalid(w http.ResponseWriter, err error) (int, error) {
+	http.Error(w, strconv.Itoa(http.StatusBadRequest)+" "+err.Error(), http.StatusBadRequest)
+	return 0, err
+}
+
+// withAllowedAction gets the action of the request, which the user must
+// be allowed to run, unless it is an administrator.
+func withAllowedAction(fn func(w http.ResponseWriter, r *http.Request, d *data, a *actions.Action) (int, error)) handleFunc {
+	return withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+		id, err := getActionID(r)
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+
+		action, err := d.store.Actions.Get(id)
+		if err != nil {
+			return errToStatus(err), err
+		}
+
+		if !d.user.Perm.Admin && !action.Allowed(d.user) {
+			return http.StatusForbidden, nil
+		}
+
+		return fn(w, r, d, action)
+	})
+}
+
+var actionsGetHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	var (
+		list []*actions.Action
+		err  error
+	)
+
+	if d.user.Perm.Admin {
+		list, err = d.store.Actions.Gets()
+	} else {
+		list, err = d.store.Actions.ForUser(d.user)
+	}
+
+	if err != nil && err != libErrors.ErrNotExist {
+		return http.StatusInternalServerError, err
+	}
+
+	if list == nil {
+		list = []*actions.Action{}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	return renderJSON(w, r, list)
+})
+
+var actionGetHandler = withAllowedAction(func(w http.ResponseWriter, r *http.Request, d *data, a *actions.Action) (int, error) {
+	return renderJSON(w, r, a)
+})
+
+var actionPostHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	action, err := getAction(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	action.ID = 0
+	d.entry.Source = action.Name
+	status, err := saveAction(w, d, action)
+	if status != 0 || err != nil {
+		return status, err
+	}
+
+	w.Header().Set("Location", "/api/actions/"+strconv.FormatUint(uint64(action.ID), 10))
+	return renderJSONStatus(w, r, http.StatusCreated, action)
+})
+
+var actionPutHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getActionID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	action, err := getAction(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	d.entry.Source = action.Name
+	if action.ID != id {
+		return http.StatusBadRequest, nil
+	}
+
+	_, err = d.store.Actions.Get(id)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	status, err := saveAction(w, d, action)
+	if status != 0 || err != nil {
+		return status, err
+	}
+
+	return http.StatusOK, nil
+})
+
+var actionDeleteHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getActionID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if action, err := d.store.Actions.Get(id); err == nil { //nolint:shadow
+		d.entry.Source = action.Name
+	}
+
+	err = d.store.Actions.Delete(id)
+	return errToStatus(err), err
+})
+
+// actionRunHandler runs an action on files, one after the other, and
+// streams the events of the runs as newline delimited JSON. The commands
+// are killed if the client goes away.
+var actionRunHandler = withAllowedAction(func(w http.ResponseWriter, r *http.Request, d *data, a *actions.Action) (int, error) {
+	d.entry.Source = a.Name
+
+	if !d.user.Perm.Execute {
+		return http.StatusForbidden, nil
+	}
+
+	if r.Body == nil {
+		return http.StatusBadRequest, libErrors.ErrEmptyRequest
+	}
+
+	req := &actionRunRequest{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil || len(req.Files) == 0 {
+		return http.StatusBadRequest, err
+	}
+
+	d.entry.Details = strings.Join(req.Files, ", ")
+
+	values, err := a.Resolve(req.Params)
+	if err != nil {
+		return invalid(w, err)
+	}
+
+	for _, file := range req.Files {
+		// The commands are run in the folder of the file, where they
+		// can write their outputs.
+		dir := path.Dir(path.Clean("/" + file))
+		if !d.Check(file) || !d.CheckAction(file, rules.ActionExecute) ||
+			!d.can(d.user.Perm.Create, dir, rules.ActionCreate) ||
+			!d.can(d.user.Perm.Modify, dir, rules.ActionModify) {
+			d.entry.Result = audit.ResultDenied
+			return http.StatusForbidden, nil
+		}
+
+		if _, err := d.user.Fs.Stat(file); err != nil { //nolint:shadow
+			return errToStatus(err), err
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &actionStream{w: w, enc: json.NewEncoder(w)}
+	total := len(req.Files)
+	failed := 0
+
+	for i, file := range req.Files {
+		stream.send(&actionEvent{Type: "start", File: file, Index: i, Total: total})
+
+		code, err := runAction(r.Context(), d, a, file, values, func(name, line string) { //nolint:shadow
+			stream.send(&actionEvent{Type: "output", File: file, Index: i, Total: total, Stream: name, Data: line})
+		})
+
+		exit := &actionEvent{Type: "exit", File: file, Index: i, Total: total, Code: code}
+		if err != nil {
+			exit.Error = err.Error()
+			failed++
+		}
+		stream.send(exit)
+
+		if r.Context().Err() != nil {
+			break
+		}
+	}
+
+	if failed > 0 {
+		d.entry.Result = audit.ResultFailure
+	}
+
+	stream.send(&actionEvent{Type: "done", Total: total, Failed: failed})
+	return 0, nil
+})
+
+// runAction runs an action on a file, in the directory of the file,
+// calling out for each line the command prints. It returns the exit
+// code of the command.
+func runAction(ctx context.Context, d *data, a *actions.Action, file string,
+	values map[string]string, out func(stream, line string)) (int, error) {
+	full := d.user.FullPath(file)
+	args, err := a.Args(full, values)
+	if err != nil {
+		return -1, err
+	}
+
+	if d.user.CommandTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(d.user.CommandTimeout)*time.Second)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec
+	cmd.Dir = filepath.Dir(full)
+	cmd.Env = runner.Environ(d.user.Sandbox)
+
+	err = runner.Sandbox(cmd, d.user.Sandbox)
+	if err != nil {
+		return -1, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return -1, err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return -1, err
+	}
+
+	if err := cmd.Start(); err != nil { //nolint:shadow
+		return -1, err
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2) //nolint:mnd
+	go scanOutput(&wg, stdout, func(line string) { out("stdout", line) })
+	go scanOutput(&wg, stderr, func(line string) { out("stderr", line) })
+	wg.Wait()
+
+	err = cmd.Wait()
+	if ctx.Err() == context.DeadlineExceeded {
+		return -1, errors.New("the command timed out")
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), err
+	} else if err != nil {
+		return -1, err
+	}
+
+	return 0, nil
+}
+
+// scanOutput calls fn for each non empty line of an output, the lines
+// being ended by new lines or by carriage returns, or split if too long.
+func scanOutput(wg *sync.WaitGroup, r io.Reader, fn func(line string)) {
+	defer wg.Done()
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 4096), maxActionLine+1) //nolint:mnd
+	s.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+
+		if (atEOF || len(data) >= maxActionLine) && len(data) > 0 {
+			return len(data), data, nil
+		}
+
+		return 0, nil, nil
+	})
+
+	for s.Scan() {
+		if line := s.Text(); line != "" {
+			fn(line)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/filebrowser/filebrowser/v2/actions"
+	"github.com/filebrowser/filebrowser/v2/audit"
+	"github.com/filebrowser/filebrowser/v2/rules"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+func TestActionPost(t *testing.T) {
+	e := newTestEnv(t, users.Permissions{Admin: true})
+
+	body := `{"name": "checksum", "command": "sha256sum {file}"}`
+	w := e.do(withAudit(audit.ActionActionCreate, actionPostHandler), "POST", "/", strings.NewReader(body))
+	checkStatus(t, w, http.StatusCreated)
+
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("got content type %q, want JSON", got)
+	}
+
+	if w.Header().Get("Location") == "" {
+		t.Error("no location")
+	}
+}
+
+func TestActionRunFolderRights(t *testing.T) {
+	perm := users.Permissions{Execute: true, Create: true, Modify: true}
+	e := newTestEnv(t, perm,
+		rules.Rule{Path: "/readonly", Actions: []rules.Action{rules.ActionCreate}},
+	)
+	e.writeFile(t, "/readonly/a.txt", "a")
+	e.writeFile(t, "/public/a.txt", "a")
+
+	a := &actions.Action{Name: "list", Command: "ls {file}"}
+	if err := e.store.Actions.Save(a); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(file string) *httptest.ResponseRecorder {
+		r := e.request("POST", "/", strings.NewReader(`{"files": ["`+file+`"]}`))
+		r = mux.SetURLVars(r, map[string]string{"id": strconv.FormatUint(uint64(a.ID), 10)})
+		return e.serve(withAudit(audit.ActionActionRun, actionRunHandler), r)
+	}
+
+	checkStatus(t, run("/readonly/a.txt"), http.StatusForbidden)
+	checkStatus(t, run("/public/a.txt"), http.StatusOK)
+}
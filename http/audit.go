@@ -0,0 +1,162 @@
+package http
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/filebrowser/filebrowser/v2/audit"
+)
+
+// withAudit records the outcome of the handler in the audit log. The
+// handler completes d.entry with the paths or the objects it dealt
+// with. The requests of unauthenticated users aren't recorded.
+func withAudit(action string, fn handleFunc) handleFunc {
+	return func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+		d.entry = &audit.Entry{
+			Action: action,
+			IP:     d.IP,
+		}
+
+		var counter interface{ count() int64 }
+		switch action {
+		case audit.ActionFileCreate, audit.ActionFileModify:
+			body := &countingReader{ReadCloser: r.Body}
+			r.Body = body
+			counter = body
+		case audit.ActionFileDownload:
+			cw := &countingWriter{ResponseWriter: w}
+			w = cw
+			counter = cw
+		}
+
+		status, err := fn(w, r, d)
+		if d.user == nil {
+			return status, err
+		}
+
+		d.entry.Username = d.user.Username
+		if counter != nil && d.entry.Bytes == 0 {
+			d.entry.Bytes = counter.count()
+		}
+
+		if d.entry.Result == "" {
+			d.entry.Result = auditResult(status, err)
+		}
+
+		if err != nil && d.entry.Details != "" {
+			d.entry.Details += "; " + err.Error()
+		} else if err != nil {
+			d.entry.Details = err.Error()
+		}
+
+		if err := d.store.Audit.Record(d.entry); err != nil { //nolint:shadow
+			log.Printf("audit: %v", err)
+		}
+
+		return status, err
+	}
+}
+
+// auditUser sets the user an action is done on as the source of
+// the audit entry.
+func auditUser(d *data, id uint) {
+	if u, err := d.store.Users.Get(d.server.Root, id); err == nil {
+		d.entry.Source = u.Username
+	}
+}
+
+func auditResult(status int, err error) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return audit.ResultDenied
+	case err != nil || status >= 400:
+		return audit.ResultFailure
+	default:
+		return audit.ResultSuccess
+	}
+}
+
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *countingReader) count() int64 {
+	return r.n
+}
+
+type countingWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+func (w *countingWriter) count() int64 {
+	return w.n
+}
+
+var auditGetHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	query := r.URL.Query()
+	q := &audit.Query{
+		Username: query.Get("username"),
+		Action:   query.Get("action"),
+		Result:   query.Get("result"),
+		Path:     query.Get("path"),
+		Limit:    100, //nolint:mnd
+	}
+
+	var err error
+	for name, field := range map[string]*int64{"since": &q.Since, "until": &q.Until} {
+		if v := query.Get(name); v != "" {
+			*field, err = parseAuditTime(v)
+			if err != nil {
+				return http.StatusBadRequest, err
+			}
+		}
+	}
+
+	for name, field := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+		if v := query.Get(name); v != "" {
+			*field, err = strconv.Atoi(v)
+			if err != nil || *field < 0 {
+				return http.StatusBadRequest, err
+			}
+		}
+	}
+
+	entries, err := d.store.Audit.Find(q)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return renderJSON(w, r, entries)
+})
+
+// parseAuditTime parses a time given either as a unix time
+// or in the RFC 3339 format.
+func parseAuditTime(v string) (int64, error) {
+	if unix, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return unix, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.Unix(), nil
+}
@@ -1,17 +1,27 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
+	"html/template"
+	"io"
+	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/dgrijalva/jwt-go/request"
 
+	"github.com/filebrowser/filebrowser/v2/audit"
+	"github.com/filebrowser/filebrowser/v2/auth"
 	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/settings"
+	"github.com/filebrowser/filebrowser/v2/tokens"
 	"github.com/filebrowser/filebrowser/v2/users"
 )
 
@@ -20,12 +30,16 @@ const (
 )
 
 type userInfo struct {
-	ID           uint              `json:"id"`
-	Locale       string            `json:"locale"`
-	ViewMode     users.ViewMode    `json:"viewMode"`
-	Perm         users.Permissions `json:"perm"`
-	Commands     []string          `json:"commands"`
-	LockPassword bool              `json:"lockPassword"`
+	ID             uint              `json:"id"`
+	Locale         string            `json:"locale"`
+	ViewMode       users.ViewMode    `json:"viewMode"`
+	Perm           users.Permissions `json:"perm"`
+	Commands       []string          `json:"commands"`
+	LockPassword   bool              `json:"lockPassword"`
+	TOTP           bool              `json:"totp"`
+	TOTPSetup      bool              `json:"totpSetup"`
+	PasswordChange bool              `json:"passwordChange"`
+	HideDotfiles   bool              `json:"hideDotfiles"`
 }
 
 type authToken struct {
@@ -54,31 +68,207 @@ func (e extractor) ExtractToken(r *http.Request) (string, error) {
 }
 
 func withUser(fn handleFunc) handleFunc {
+	return withAuthenticatedUser(fn, false)
+}
+
+// withPendingUser is like withUser, but it also lets through the users
+// that still have to set up the mandatory two-factor authentication or
+// to change their password.
+func withPendingUser(fn handleFunc) handleFunc {
+	return withAuthenticatedUser(fn, true)
+}
+
+func withAuthenticatedUser(fn handleFunc, allowPending bool) handleFunc {
 	return func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
-		keyFunc := func(token *jwt.Token) (interface{}, error) {
-			return d.settings.Key, nil
+		var (
+			status int
+			err    error
+		)
+
+		if raw := extractAccessToken(r); raw != "" {
+			status, err = authenticateAccessToken(raw, d)
+		} else {
+			status, err = authenticateJWT(w, r, d)
 		}
 
-		var tk authToken
-		token, err := request.ParseFromRequest(r, &extractor{}, keyFunc, request.WithClaims(&tk))
+		if status != 0 || err != nil {
+			return status, err
+		}
 
-		if err != nil || !token.Valid {
+		if !allowPending && (mustSetupTOTP(d, d.user) || mustChangePassword(d, d.user)) {
 			return http.StatusForbidden, nil
 		}
 
-		expired := !tk.VerifyExpiresAt(time.Now().Add(time.Hour).Unix(), true)
-		updated := d.store.Users.LastUpdate(tk.User.ID) > tk.IssuedAt
+		return fn(w, r, d)
+	}
+}
+
+func authenticateJWT(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		return d.settings.Key, nil
+	}
+
+	var tk authToken
+	token, err := request.ParseFromRequest(r, &extractor{}, keyFunc, request.WithClaims(&tk))
+
+	if err != nil || !token.Valid {
+		return http.StatusForbidden, nil
+	}
 
-		if expired || updated {
-			w.Header().Add("X-Renew-Token", "true")
+	expired := !tk.VerifyExpiresAt(time.Now().Add(time.Hour).Unix(), true)
+	updated := d.store.Users.LastUpdate(tk.User.ID) > tk.IssuedAt
+
+	if expired || updated {
+		w.Header().Add("X-Renew-Token", "true")
+	}
+
+	// The tokens issued before the sessions existed have no ID.
+	if tk.Id == "" {
+		return http.StatusForbidden, nil
+	}
+
+	sess, err := d.store.Sessions.Get(tk.Id)
+	if err == errors.ErrNotExist {
+		return http.StatusForbidden, nil
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if sess.UserID != tk.User.ID {
+		return http.StatusForbidden, nil
+	}
+
+	// The session may have been revoked since it was read.
+	err = d.store.Sessions.Touch(sess, d.IP)
+	if err == errors.ErrNotExist {
+		return http.StatusForbidden, nil
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	user, err := d.store.Users.Get(d.server.Root, tk.User.ID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	err = d.setUser(user)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	d.session = sess
+	return 0, nil
+}
+
+// extractAccessToken gets the personal access token from the request,
+// if there's one. It can be sent in the same places as the JWT or as
+// a bearer token.
+func extractAccessToken(r *http.Request) string {
+	candidates := []string{
+		r.Header.Get("X-Auth"),
+		strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "),
+		r.URL.Query().Get("auth"),
+	}
+
+	for _, raw := range candidates {
+		if tokens.IsToken(raw) {
+			return raw
 		}
+	}
 
-		d.user, err = d.store.Users.Get(d.server.Root, tk.User.ID)
-		if err != nil {
-			return http.StatusInternalServerError, err
+	return ""
+}
+
+func authenticateAccessToken(raw string, d *data) (int, error) {
+	t, err := d.store.Tokens.Authenticate(raw)
+	if err == errors.ErrNotExist {
+		return http.StatusForbidden, nil
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	user, err := d.store.Users.Get(d.server.Root, t.UserID)
+	if err == errors.ErrNotExist {
+		return http.StatusForbidden, nil
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	err = d.setUser(user)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	d.user.Perm = t.Permissions(d.user.Perm)
+	d.token = t
+	return 0, nil
+}
+
+// revalidate checks again that the session or the token the user was
+// authenticated with is still valid, and reloads the settings and the
+// user, for the requests that last. It returns false if the user can't
+// be let through anymore.
+func revalidate(d *data) (bool, error) {
+	switch {
+	case d.session != nil:
+		sess, err := d.store.Sessions.Get(d.session.ID)
+		if err == errors.ErrNotExist {
+			return false, nil
+		} else if err != nil {
+			return false, err
 		}
-		return fn(w, r, d)
+		d.session = sess
+	case d.token != nil:
+		t, err := d.store.Tokens.Get(d.token.ID)
+		if err == errors.ErrNotExist {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+
+		if t.Expired(time.Now().Unix()) {
+			return false, nil
+		}
+		d.token = t
+	}
+
+	set, err := d.store.Settings.Get()
+	if err != nil {
+		return false, err
+	}
+
+	user, err := d.store.Users.Get(d.server.Root, d.user.ID)
+	if err == errors.ErrNotExist {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	d.settings = set
+	d.Runner.Settings = set
+	err = d.setUser(user)
+	if err != nil {
+		return false, err
+	}
+
+	if d.token != nil {
+		d.user.Perm = d.token.Permissions(d.user.Perm)
 	}
+
+	return !mustSetupTOTP(d, d.user) && !mustChangePassword(d, d.user), nil
+}
+
+// withSession only lets through the users that logged in. The personal
+// access tokens can't be used, otherwise a token could for example be
+// used to create a broader one.
+func withSession(fn handleFunc) handleFunc {
+	return withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+		if d.session == nil {
+			return http.StatusForbidden, nil
+		}
+
+		return fn(w, r, d)
+	})
 }
 
 func withAdmin(fn handleFunc) handleFunc {
@@ -91,20 +281,266 @@ func withAdmin(fn handleFunc) handleFunc {
 	})
 }
 
+// maxLoginBodySize is the maximum size of the body of a login request.
+const maxLoginBodySize = 1 << 20
+
 var loginHandler = func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
 	auther, err := d.store.Auth.Get(d.settings.AuthMethod)
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
 
-	user, err := auther.Auth(r, d.store.Users, d.server.Root)
-	if err == os.ErrPermission {
-		return http.StatusForbidden, nil
-	} else if err != nil {
-		return http.StatusInternalServerError, err
-	} else {
+	username, err := peekLoginUsername(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	return login(w, r, d, auther, username, func(user *users.User) (int, error) {
 		return printToken(w, r, d, user)
+	})
+}
+
+// login logs in the user of a request with the auther. The username is
+// the one given by the user, if any. The attempts are limited and
+// recorded, the hooks are run, and respond sends the token to the user
+// once it is authenticated.
+func login(w http.ResponseWriter, r *http.Request, d *data, auther auth.Auther, username string,
+	respond func(user *users.User) (int, error)) (int, error) {
+	cfg := d.settings.BruteForce
+	now := time.Now()
+	ip := d.IP
+	keys := loginLimiterKeys(ip, username)
+
+	if !cfg.Disabled {
+		if wait := limiter.reserve(now, cfg, keys...); wait > 0 {
+			loginFailed(d, username, nil, ip, audit.ResultDenied, "too many attempts")
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			return http.StatusTooManyRequests, nil
+		}
+	}
+
+	user, status, err := authenticate(w, r, d, auther, username, ip, keys, now)
+	if status != 0 || err != nil {
+		return status, err
+	}
+
+	limiter.reset(keys...)
+	if user.FailedLogins != 0 || user.LockedUntil != 0 {
+		user.FailedLogins = 0
+		user.LockedUntil = 0
+		err = d.store.Users.Update(user, "FailedLogins", "LockedUntil")
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
 	}
+
+	vars := map[string]string{"LOGIN_METHOD": string(d.settings.AuthMethod)}
+	err = d.Before("login", "", "", user, vars)
+	if err != nil {
+		recordLogin(d, user.Username, ip, audit.ResultDenied, err.Error())
+		return errToStatus(err), err
+	}
+
+	status, err = respond(user)
+	if status == 0 && err == nil {
+		recordLogin(d, user.Username, ip, audit.ResultSuccess, "")
+		d.runAfter("login", "", "", user, vars)
+	}
+
+	return status, err
+}
+
+// authenticate authenticates the user of a login request, whose attempt
+// has been reserved in the limiter. It returns a status if it fails.
+func authenticate(w http.ResponseWriter, r *http.Request, d *data, auther auth.Auther,
+	username, ip string, keys []string, now time.Time) (*users.User, int, error) {
+	cfg := d.settings.BruteForce
+	failed := false
+	defer func() {
+		if !cfg.Disabled && !failed {
+			limiter.release(keys...)
+		}
+	}()
+
+	var known *users.User
+	if username != "" {
+		var err error
+		known, err = d.store.Users.Get(d.server.Root, username)
+		if err != nil && err != errors.ErrNotExist {
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+
+	if !cfg.Disabled && known != nil && known.LockedUntil > now.Unix() {
+		loginFailed(d, username, known, ip, audit.ResultDenied, "account locked")
+		w.Header().Set("Retry-After", strconv.FormatInt(known.LockedUntil-now.Unix(), 10))
+		return nil, http.StatusTooManyRequests, nil
+	}
+
+	user, err := auther.Auth(r, d.store.Users, d.settings, d.server)
+	switch {
+	case err == os.ErrPermission:
+		loginFailed(d, username, known, ip, audit.ResultFailure, "")
+		if cfg.Disabled {
+			return nil, http.StatusForbidden, nil
+		}
+
+		failed = true
+		limiter.fail(now, cfg, keys...)
+		if known != nil {
+			err = lockOnFailure(d, known, cfg, now)
+			if err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+		}
+
+		return nil, http.StatusForbidden, nil
+	case err == errors.ErrTOTPRequired:
+		return nil, http.StatusUnauthorized, nil
+	case err != nil:
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return user, 0, nil
+}
+
+// loginFailed records a failed login and runs its hooks. The user is
+// nil if the username isn't known.
+func loginFailed(d *data, username string, user *users.User, ip, result, reason string) {
+	recordLogin(d, username, ip, result, reason)
+
+	if user == nil {
+		user = &users.User{Username: username}
+	}
+
+	if reason == "" {
+		reason = "invalid credentials"
+	}
+
+	vars := map[string]string{
+		"LOGIN_METHOD": string(d.settings.AuthMethod),
+		"LOGIN_REASON": reason,
+	}
+
+	// The login already failed, so the hooks can't change anything.
+	err := d.RunHookWith(func() error {
+		return nil
+	}, "login_failed", "", "", user, vars)
+	if err != nil {
+		log.Printf("login_failed: %v", err)
+	}
+}
+
+// peekLoginUsername reads the username from the body of a login request,
+// if there's one, leaving the body untouched for the auther.
+func peekLoginUsername(r *http.Request) (string, error) {
+	if r.Body == nil {
+		return "", nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxLoginBodySize))
+	if err != nil {
+		return "", err
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var cred struct {
+		Username string `json:"username"`
+	}
+
+	// The body may not be json, depending on the auth method.
+	_ = json.Unmarshal(body, &cred)
+	return cred.Username, nil
+}
+
+// lockOnFailure counts a failed login of the user, locking the
+// account if it failed too many times in a row.
+func lockOnFailure(d *data, user *users.User, cfg settings.BruteForce, now time.Time) error {
+	user.FailedLogins++
+	if user.FailedLogins >= cfg.LockoutAttempts {
+		user.FailedLogins = 0
+		user.LockedUntil = now.Unix() + cfg.LockoutDuration
+		log.Printf("login: user %s locked until %s", user.Username, time.Unix(user.LockedUntil, 0).Format(time.RFC3339))
+	}
+
+	return d.store.Users.Update(user, "FailedLogins", "LockedUntil")
+}
+
+func recordLogin(d *data, username, ip, result, details string) {
+	err := d.store.Audit.Record(&audit.Entry{
+		Username: username,
+		IP:       ip,
+		Action:   audit.ActionLogin,
+		Result:   result,
+		Details:  details,
+	})
+	if err != nil {
+		log.Printf("audit: %v", err)
+	}
+}
+
+var loginRedirectHandler = func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	auther, err := d.store.Auth.Get(d.settings.AuthMethod)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	redirecter, ok := auther.(auth.Redirecter)
+	if !ok {
+		return http.StatusNotFound, nil
+	}
+
+	u, err := redirecter.LoginURL(w, r, d.settings, d.server)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	http.Redirect(w, r, u, http.StatusFound)
+	return 0, nil
+}
+
+// loginCallbackPage stores the token the same way the login page
+// does and sends the user to the files.
+var loginCallbackPage = template.Must(template.New("callback").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>File Browser</title></head>
+<body>
+<script>
+localStorage.setItem("jwt", {{.Token}});
+window.location.replace({{.Redirect}});
+</script>
+</body>
+</html>`))
+
+var loginCallbackHandler = func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	auther, err := d.store.Auth.Get(d.settings.AuthMethod)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if _, ok := auther.(auth.Redirecter); !ok {
+		return http.StatusNotFound, nil
+	}
+
+	// The username is only known once the provider authenticated the user.
+	return login(w, r, d, auther, "", func(user *users.User) (int, error) {
+		signed, err := signToken(r, d, user)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = loginCallbackPage.Execute(w, map[string]string{
+			"Token":    signed,
+			"Redirect": d.server.BaseURL + "/files/",
+		})
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+
+		return 0, nil
+	})
 }
 
 type signupBody struct {
@@ -131,6 +567,11 @@ var signupHandler = func(w http.ResponseWriter, r *http.Request, d *data) (int,
 		return http.StatusBadRequest, nil
 	}
 
+	err = d.settings.Password.Check(info.Username, info.Password)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
 	user := &users.User{
 		Username: info.Username,
 	}
@@ -152,46 +593,118 @@ var signupHandler = func(w http.ResponseWriter, r *http.Request, d *data) (int,
 	user.Scope = userHome
 	log.Printf("new user: %s, home dir: [%s].", user.Username, userHome)
 
-	err = d.store.Users.Save(user)
+	// The users signing up create their own account.
+	vars := userVars(user)
+	vars["USER_SIGNUP"] = "true"
+	err = d.RunHookWith(func() error {
+		err := d.store.Users.Save(user) //nolint:shadow
+		vars["TARGET_USER_ID"] = strconv.FormatUint(uint64(user.ID), 10)
+		return err
+	}, "user_create", "", "", user, vars)
 	if err == errors.ErrExist {
 		return http.StatusConflict, err
 	} else if err != nil {
-		return http.StatusInternalServerError, err
+		return errToStatus(err), err
 	}
 
 	return http.StatusOK, nil
 }
 
-var renewHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+var renewHandler = withPendingUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	// A JWT would have all the permissions of the user.
+	if d.token != nil {
+		return http.StatusForbidden, nil
+	}
+
 	return printToken(w, r, d, d.user)
 })
 
-func printToken(w http.ResponseWriter, _ *http.Request, d *data, user *users.User) (int, error) {
+var logoutHandler = withPendingUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	if d.session == nil {
+		return http.StatusForbidden, nil
+	}
+
+	err := d.store.Sessions.Delete(d.session.ID)
+	return errToStatus(err), err
+})
+
+func printToken(w http.ResponseWriter, r *http.Request, d *data, user *users.User) (int, error) {
+	signed, err := signToken(r, d, user)
+	if err == errors.ErrNotExist {
+		return http.StatusForbidden, nil
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	w.Header().Set("Content-Type", "cty")
+	if _, err := w.Write([]byte(signed)); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	return 0, nil
+}
+
+// signToken signs a new token for the user. It belongs to the current
+// session, which is extended, or to a new one when logging in.
+func signToken(r *http.Request, d *data, user *users.User) (string, error) {
+	expire := time.Now().Add(TokenExpirationTime).Unix()
+
+	// The token tells the frontend what the user can do, including
+	// what its groups allow when logging in.
+	if d.user != user {
+		if err := d.setUser(user); err != nil {
+			return "", err
+		}
+	}
+
+	var err error
+	if d.session != nil {
+		err = d.store.Sessions.Extend(d.session, expire)
+	} else {
+		d.session, err = d.store.Sessions.Create(user.ID, d.IP, r.UserAgent(), expire)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
 	claims := &authToken{
 		User: userInfo{
-			ID:           user.ID,
-			Locale:       user.Locale,
-			ViewMode:     user.ViewMode,
-			Perm:         user.Perm,
-			LockPassword: user.LockPassword,
-			Commands:     user.Commands,
+			ID:             user.ID,
+			Locale:         user.Locale,
+			ViewMode:       user.ViewMode,
+			Perm:           user.Perm,
+			LockPassword:   user.LockPassword,
+			Commands:       user.Commands,
+			TOTP:           user.TOTPEnabled,
+			TOTPSetup:      mustSetupTOTP(d, user),
+			PasswordChange: mustChangePassword(d, user),
+			HideDotfiles:   user.HideDotfiles,
 		},
 		StandardClaims: jwt.StandardClaims{
+			Id:        d.session.ID,
 			IssuedAt:  time.Now().Unix(),
-			ExpiresAt: time.Now().Add(TokenExpirationTime).Unix(),
+			ExpiresAt: expire,
 			Issuer:    "File Browser",
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signed, err := token.SignedString(d.settings.Key)
-	if err != nil {
-		return http.StatusInternalServerError, err
-	}
+	return token.SignedString(d.settings.Key)
+}
 
-	w.Header().Set("Content-Type", "cty")
-	if _, err := w.Write([]byte(signed)); err != nil {
-		return http.StatusInternalServerError, err
+// mustSetupTOTP checks if the user has to set up two-factor authentication
+// before being allowed to do anything else.
+func mustSetupTOTP(d *data, user *users.User) bool {
+	return d.settings.RequireTOTP && d.settings.AuthMethod == auth.MethodJSONAuth && !user.TOTPEnabled
+}
+
+// mustChangePassword checks if the user has to change its password,
+// either because it was asked to or because the password expired,
+// before being allowed to do anything else.
+func mustChangePassword(d *data, user *users.User) bool {
+	if user.LockPassword || d.settings.AuthMethod != auth.MethodJSONAuth {
+		return false
 	}
-	return 0, nil
+
+	return user.MustChangePassword || d.settings.Password.Expired(user.PasswordChanged, time.Now().Unix())
 }
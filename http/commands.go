@@ -2,15 +2,21 @@ package http
 
 import (
 	"bufio"
+	"encoding/json"
 	"io"
 	"log"
 	"net/http"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 
+	"github.com/filebrowser/filebrowser/v2/audit"
+	"github.com/filebrowser/filebrowser/v2/history"
+	"github.com/filebrowser/filebrowser/v2/rules"
 	"github.com/filebrowser/filebrowser/v2/runner"
 )
 
@@ -27,6 +33,24 @@ var (
 	cmdNotAllowed = []byte("Command not allowed.")
 )
 
+// Default size of the pseudo terminals.
+const (
+	defaultTerminalRows = 24
+	defaultTerminalCols = 80
+)
+
+// terminalMessage is a JSON control message of the terminals. The
+// clients send "input" messages, as an alternative to the binary ones,
+// and "resize" messages. The server sends an "exit" message once the
+// command ended.
+type terminalMessage struct {
+	Type string `json:"type"`
+	Data string `json:"data,omitempty"`
+	Rows uint16 `json:"rows,omitempty"`
+	Cols uint16 `json:"cols,omitempty"`
+	Code int    `json:"code"`
+}
+
 func wsErr(ws *websocket.Conn, r *http.Request, status int, err error) { //nolint:unparam
 	txt := http.StatusText(status)
 	if err != nil || status >= 400 {
@@ -59,7 +83,12 @@ var commandsHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *d
 		}
 	}
 
-	if !d.user.CanExecute(strings.Split(raw, " ")[0]) {
+	d.entry.Source = r.URL.Path
+	d.entry.Details = raw
+
+	if !d.user.CanExecute(strings.Split(raw, " ")[0]) || !d.Check(r.URL.Path) ||
+		!d.CheckAction(r.URL.Path, rules.ActionExecute) {
+		d.entry.Result = audit.ResultDenied
 		if err := conn.WriteMessage(websocket.TextMessage, cmdNotAllowed); err != nil { //nolint:shadow
 			wsErr(conn, r, http.StatusInternalServerError, err)
 		}
@@ -69,14 +98,50 @@ var commandsHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *d
 
 	command, err := runner.ParseCommand(d.settings, raw)
 	if err != nil {
+		d.entry.Result = audit.ResultFailure
 		if err := conn.WriteMessage(websocket.TextMessage, []byte(err.Error())); err != nil { //nolint:shadow
 			wsErr(conn, r, http.StatusInternalServerError, err)
 		}
 		return 0, nil
 	}
 
+	pty := r.URL.Query().Get("pty") == "true"
+
 	cmd := exec.Command(command[0], command[1:]...) //nolint:gosec
 	cmd.Dir = d.user.FullPath(r.URL.Path)
+	cmd.Env = runner.Environ(d.user.Sandbox)
+	if pty {
+		cmd.Env = append(cmd.Env, "TERM=xterm-256color")
+	}
+
+	err = runner.Sandbox(cmd, d.user.Sandbox)
+	if err != nil {
+		d.entry.Result = audit.ResultFailure
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(err.Error())); err != nil { //nolint:shadow
+			wsErr(conn, r, http.StatusInternalServerError, err)
+		}
+		return 0, nil
+	}
+
+	run := &history.Run{
+		UserID:   d.user.ID,
+		Username: d.user.Username,
+		Command:  raw,
+		Dir:      r.URL.Path,
+		Terminal: pty,
+		Start:    history.Millis(time.Now()),
+	}
+	output := history.NewOutput(d.settings.History.MaxOutput)
+
+	if pty {
+		run.ExitCode, err = runTerminal(conn, r, d, cmd, output)
+		if err != nil {
+			run.Error = err.Error()
+		}
+
+		recordRun(d, run, output)
+		return 0, nil
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -91,20 +156,173 @@ var commandsHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *d
 	}
 
 	if err := cmd.Start(); err != nil {
+		d.entry.Result = audit.ResultFailure
 		wsErr(conn, r, http.StatusInternalServerError, err)
+		run.ExitCode = -1
+		run.Error = err.Error()
+		recordRun(d, run, output)
 		return 0, nil
 	}
 
+	if d.user.CommandTimeout > 0 {
+		timer := time.AfterFunc(time.Duration(d.user.CommandTimeout)*time.Second, func() {
+			_ = cmd.Process.Kill()
+		})
+		defer timer.Stop()
+	}
+
 	s := bufio.NewScanner(io.MultiReader(stdout, stderr))
 	for s.Scan() {
+		_, _ = output.Write(append(s.Bytes(), '\n'))
 		if err := conn.WriteMessage(websocket.TextMessage, s.Bytes()); err != nil {
 			log.Print(err)
 		}
 	}
 
 	if err := cmd.Wait(); err != nil {
+		d.entry.Result = audit.ResultFailure
 		wsErr(conn, r, http.StatusInternalServerError, err)
+		run.ExitCode = exitCode(err)
 	}
 
+	recordRun(d, run, output)
 	return 0, nil
 })
+
+// exitCode returns the exit code of a command from the error
+// it exited with, -1 if it didn't exit by itself.
+func exitCode(err error) int {
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+
+	return -1
+}
+
+// recordRun saves the run of a command in the history, unless the
+// history is disabled.
+func recordRun(d *data, run *history.Run, output *history.Output) {
+	set := d.settings.History
+	if set.Disabled {
+		return
+	}
+
+	run.End = history.Millis(time.Now())
+	output.Fill(run)
+
+	if err := d.store.History.Record(run, set.MaxAge, set.MaxRuns); err != nil {
+		log.Printf("history: %v", err)
+	}
+}
+
+// runTerminal runs a command in a pseudo terminal. The output of the
+// terminal is sent in binary messages and its input is read from binary
+// messages or from the control messages. The command and the processes
+// it started are killed when the client disconnects or when the command
+// times out. The output is also written to output. It returns the exit
+// code of the command.
+func runTerminal(conn *websocket.Conn, r *http.Request, d *data, cmd *exec.Cmd, output io.Writer) (int, error) {
+	rows, cols := terminalSize(r)
+
+	ptm, err := runner.StartPTY(cmd, rows, cols)
+	if err != nil {
+		d.entry.Result = audit.ResultFailure
+		wsErr(conn, r, http.StatusInternalServerError, err)
+		return -1, err
+	}
+	defer ptm.Close()
+
+	var once sync.Once
+	kill := func() {
+		once.Do(func() {
+			_ = runner.KillPTY(cmd)
+		})
+	}
+	defer kill()
+
+	if d.user.CommandTimeout > 0 {
+		timer := time.AfterFunc(time.Duration(d.user.CommandTimeout)*time.Second, kill)
+		defer timer.Stop()
+	}
+
+	go func() {
+		for {
+			typ, msg, err := conn.ReadMessage()
+			if err != nil {
+				kill()
+				return
+			}
+
+			if typ == websocket.BinaryMessage {
+				_, _ = ptm.Write(msg)
+				continue
+			}
+
+			var ctl terminalMessage
+			if err := json.Unmarshal(msg, &ctl); err != nil { //nolint:shadow
+				continue
+			}
+
+			switch ctl.Type {
+			case "input":
+				_, _ = ptm.Write([]byte(ctl.Data))
+			case "resize":
+				if ctl.Rows > 0 && ctl.Cols > 0 {
+					_ = runner.ResizePTY(ptm, ctl.Rows, ctl.Cols)
+				}
+			}
+		}
+	}()
+
+	buf := make([]byte, 32*1024) //nolint:mnd
+	for {
+		n, err := ptm.Read(buf)
+		if n > 0 {
+			_, _ = output.Write(buf[:n])
+			if err := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); err != nil { //nolint:shadow
+				kill()
+				break
+			}
+		}
+
+		// Reading fails once the command and its children closed the terminal.
+		if err != nil {
+			break
+		}
+	}
+
+	code := 0
+	if err := cmd.Wait(); err != nil {
+		d.entry.Result = audit.ResultFailure
+		code = exitCode(err)
+	}
+
+	msg, _ := json.Marshal(terminalMessage{Type: "exit", Code: code})
+	if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+		log.Print(err)
+	}
+
+	err = conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(WSWriteDeadline))
+	if err != nil {
+		log.Print(err)
+	}
+
+	return code, nil
+}
+
+// terminalSize reads the initial size of a terminal from the
+// rows and cols query parameters.
+func terminalSize(r *http.Request) (rows, cols uint16) {
+	rows, cols = defaultTerminalRows, defaultTerminalCols
+
+	if v, err := strconv.ParseUint(r.URL.Query().Get("rows"), 10, 16); err == nil && v > 0 {
+		rows = uint16(v)
+	}
+
+	if v, err := strconv.ParseUint(r.URL.Query().Get("cols"), 10, 16); err == nil && v > 0 {
+		cols = uint16(v)
+	}
+
+	return rows, cols
+}
@@ -1,16 +1,26 @@
 package http
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"path"
 	"strconv"
+	"strings"
 
-	"github.com/tomasen/realip"
+	"github.com/spf13/afero"
 
+	"github.com/filebrowser/filebrowser/v2/audit"
+	"github.com/filebrowser/filebrowser/v2/groups"
+	"github.com/filebrowser/filebrowser/v2/rules"
 	"github.com/filebrowser/filebrowser/v2/runner"
+	"github.com/filebrowser/filebrowser/v2/session"
 	"github.com/filebrowser/filebrowser/v2/settings"
+	"github.com/filebrowser/filebrowser/v2/share"
 	"github.com/filebrowser/filebrowser/v2/storage"
+	"github.com/filebrowser/filebrowser/v2/tokens"
 	"github.com/filebrowser/filebrowser/v2/users"
+	"github.com/filebrowser/filebrowser/v2/webhooks"
 )
 
 type handleFunc func(w http.ResponseWriter, r *http.Request, d *data) (int, error)
@@ -21,28 +31,138 @@ type data struct {
 	server   *settings.Server
 	store    *storage.Storage
 	user     *users.User
+	groups   []*groups.Group
+	token    *tokens.Token
+	session  *session.Session
 	raw      interface{}
+	link     *share.Link
+	entry    *audit.Entry
+	// ignores caches the rules of the ignore files by folder.
+	ignores map[string][]rules.Rule
 }
 
-// Check implements rules.Checker.
+// eachRule calls fn for every rule that applies to the user, from
+// the lowest to the highest priority.
+func (d *data) eachRule(fn func(rule *rules.Rule)) {
+	for i := range d.settings.Rules {
+		fn(&d.settings.Rules[i])
+	}
+
+	for _, group := range d.groups {
+		for i := range group.Rules {
+			fn(&group.Rules[i])
+		}
+	}
+
+	for i := range d.user.Rules {
+		fn(&d.user.Rules[i])
+	}
+}
+
+// Check implements rules.Checker. Besides the rules, the dotfiles
+// can be hidden and the ignore files hide the files they list.
 func (d *data) Check(path string) bool {
+	if (d.settings.ForceHideDotfiles || d.user.HideDotfiles) && rules.IsDotfile(path) {
+		return false
+	}
+
 	allow := true
-	for _, rule := range d.settings.Rules {
-		if rule.Matches(path) {
+	d.eachRule(func(rule *rules.Rule) {
+		if rule.Decides(path, "") {
 			allow = rule.Allow
 		}
+	})
+
+	return allow && !d.ignored(path)
+}
+
+// ignored checks if a path is hidden by the ignore files of the
+// folders it is in, the deepest ones having the priority.
+func (d *data) ignored(name string) bool {
+	name = path.Clean("/" + name)
+
+	ignored := false
+	dir := "/"
+	for _, elem := range strings.Split(strings.TrimPrefix(name, "/"), "/") {
+		ignore := d.ignoreRules(dir)
+		for i := range ignore {
+			if ignore[i].Matches(name) {
+				ignored = !ignore[i].Allow
+			}
+		}
+
+		dir = path.Join(dir, elem)
 	}
 
-	for _, rule := range d.user.Rules {
-		if rule.Matches(path) {
+	return ignored
+}
+
+// ignoreRules gets the rules of the ignore file of a folder. A folder
+// without a readable ignore file has no rules.
+func (d *data) ignoreRules(dir string) []rules.Rule {
+	if ignore, ok := d.ignores[dir]; ok {
+		return ignore
+	}
+
+	if d.ignores == nil {
+		d.ignores = map[string][]rules.Rule{}
+	}
+
+	var ignore []rules.Rule
+	content, err := afero.ReadFile(d.user.Fs, path.Join(dir, rules.IgnoreFile))
+	if err == nil {
+		ignore = rules.ParseIgnore(dir, content)
+	}
+
+	d.ignores[dir] = ignore
+	return ignore
+}
+
+// CheckAction implements rules.Checker.
+func (d *data) CheckAction(path string, action rules.Action) bool {
+	allow := true
+	d.eachRule(func(rule *rules.Rule) {
+		if rule.Decides(path, action) {
 			allow = rule.Allow
 		}
-	}
+	})
 
 	return allow
 }
 
-func handle(fn handleFunc, prefix string, store *storage.Storage, server *settings.Server) http.Handler {
+// can checks if the user has the permission for the action
+// and if the rules allow it on the path.
+func (d *data) can(perm bool, path string, action rules.Action) bool {
+	return perm && d.CheckAction(path, action)
+}
+
+// setUser sets the current user, granting it what its groups allow.
+func (d *data) setUser(user *users.User) error {
+	gs, err := d.store.Groups.ForUser(user)
+	if err != nil {
+		return err
+	}
+
+	if groups.Apply(user, gs) {
+		user.ResetFs(d.server.Root)
+	}
+
+	d.user = user
+	d.groups = gs
+	d.ignores = nil
+	return nil
+}
+
+// runAfter runs the after hooks of an event once the response was
+// written, when their errors can only be logged.
+func (d *data) runAfter(evt, path, dst string, user *users.User, vars map[string]string) {
+	if err := d.After(evt, path, dst, user, vars); err != nil {
+		log.Printf("after_%s: %v", evt, err)
+	}
+}
+
+func handle(fn handleFunc, prefix string, store *storage.Storage, server *settings.Server,
+	dispatcher *webhooks.Dispatcher) http.Handler {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		settings, err := store.Settings.Get()
 		if err != nil {
@@ -50,8 +170,15 @@ func handle(fn handleFunc, prefix string, store *storage.Storage, server *settin
 			return
 		}
 
+		ip := clientIP(r, settings.BruteForce)
 		status, err := fn(w, r, &data{
-			Runner:   &runner.Runner{Settings: settings},
+			Runner: &runner.Runner{
+				Settings:      settings,
+				Webhooks:      dispatcher,
+				Log:           store.Hooks,
+				IP:            ip,
+				ContentLength: r.ContentLength,
+			},
 			store:    store,
 			settings: settings,
 			server:   server,
@@ -59,12 +186,18 @@ func handle(fn handleFunc, prefix string, store *storage.Storage, server *settin
 
 		if status != 0 {
 			txt := http.StatusText(status)
+
+			// The rejection messages of the hooks are for the users.
+			var rejection *runner.Rejection
+			if errors.As(err, &rejection) && rejection.Message != "" {
+				txt = rejection.Message
+			}
+
 			http.Error(w, strconv.Itoa(status)+" "+txt, status)
 		}
 
 		if status >= 400 || err != nil {
-			clientIP := realip.FromRequest(r)
-			log.Printf("%s: %v %s %v", r.URL.Path, status, clientIP, err)
+			log.Printf("%s: %v %s %v", r.URL.Path, status, ip, err)
 		}
 	})
 
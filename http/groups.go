@@ -0,0 +1,143 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/groups"
+)
+
+func getGroupID(r *http.Request) (uint, error) {
+	i, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 0)
+	if err != nil {
+		return 0, err
+	}
+	return uint(i), err
+}
+
+func getGroup(r *http.Request) (*groups.Group, error) {
+	if r.Body == nil {
+		return nil, errors.ErrEmptyRequest
+	}
+
+	g := &groups.Group{}
+	err := json.NewDecoder(r.Body).Decode(g)
+	return g, err
+}
+
+// checkGroups verifies that all the groups exist.
+func checkGroups(d *data, ids []uint) (int, error) {
+	for _, id := range ids {
+		_, err := d.store.Groups.Get(id)
+		if err == errors.ErrNotExist {
+			return http.StatusBadRequest, err
+		} else if err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+
+	return 0, nil
+}
+
+var groupsGetHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	groups, err := d.store.Groups.Gets()
+	if err != nil && err != errors.ErrNotExist {
+		return http.StatusInternalServerError, err
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].ID < groups[j].ID
+	})
+
+	return renderJSON(w, r, groups)
+})
+
+var groupGetHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getGroupID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	g, err := d.store.Groups.Get(id)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	return renderJSON(w, r, g)
+})
+
+var groupPostHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	g, err := getGroup(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	g.ID = 0
+	d.entry.Source = g.Name
+	err = d.store.Groups.Save(g)
+	switch {
+	case err == errors.ErrEmptyName, err == errors.ErrInvalidMount, err == errors.ErrInvalidSandbox:
+		return http.StatusBadRequest, err
+	case err != nil:
+		return errToStatus(err), err
+	}
+
+	w.Header().Set("Location", "/settings/groups/"+strconv.FormatUint(uint64(g.ID), 10))
+	return http.StatusCreated, nil
+})
+
+var groupPutHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getGroupID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	g, err := getGroup(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	d.entry.Source = g.Name
+	if g.ID != id {
+		return http.StatusBadRequest, nil
+	}
+
+	_, err = d.store.Groups.Get(id)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	err = d.store.Groups.Save(g)
+	switch {
+	case err == errors.ErrEmptyName, err == errors.ErrInvalidMount, err == errors.ErrInvalidSandbox:
+		return http.StatusBadRequest, err
+	case err != nil:
+		return errToStatus(err), err
+	}
+
+	return http.StatusOK, nil
+})
+
+var groupDeleteHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getGroupID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if g, err := d.store.Groups.Get(id); err == nil { //nolint:shadow
+		d.entry.Source = g.Name
+	}
+
+	err = d.store.Groups.Delete(id)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	err = d.store.Users.RemoveGroup(id)
+	return errToStatus(err), err
+})
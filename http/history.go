@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/filebrowser/filebrowser/v2/history"
+)
+
+// withRun gets the run of the history of the request, which must be
+// one of the user, unless it is an administrator.
+func withRun(fn func(w http.ResponseWriter, r *http.Request, d *data, run *history.Run) (int, error)) handleFunc {
+	return withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+		id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 0)
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+
+		run, err := d.store.History.Get(uint(id))
+		if err != nil {
+			return errToStatus(err), err
+		}
+
+		if run.UserID != d.user.ID && !d.user.Perm.Admin {
+			return http.StatusNotFound, nil
+		}
+
+		return fn(w, r, d, run)
+	})
+}
+
+// historyGetHandler lists the runs of the user, the newest first and
+// without their outputs. The administrators can list the runs of all
+// the users with ?all=true, or the ones of another user with ?user=<id>.
+var historyGetHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	query := r.URL.Query()
+	q := &history.Query{
+		UserID: d.user.ID,
+		Limit:  100, //nolint:mnd
+	}
+
+	if d.user.Perm.Admin {
+		if query.Get("all") == "true" {
+			q.UserID = 0
+		}
+
+		if v := query.Get("user"); v != "" {
+			id, err := strconv.ParseUint(v, 10, 0)
+			if err != nil {
+				return http.StatusBadRequest, err
+			}
+			q.UserID = uint(id)
+		}
+	}
+
+	var err error
+	for name, field := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+		if v := query.Get(name); v != "" {
+			*field, err = strconv.Atoi(v)
+			if err != nil || *field < 0 {
+				return http.StatusBadRequest, err
+			}
+		}
+	}
+
+	runs, err := d.store.History.Find(q)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return renderJSON(w, r, runs)
+})
+
+var historyRunGetHandler = withRun(func(w http.ResponseWriter, r *http.Request, d *data, run *history.Run) (int, error) {
+	return renderJSON(w, r, run)
+})
+
+var historyRunDeleteHandler = withRun(func(w http.ResponseWriter, r *http.Request, d *data, run *history.Run) (int, error) {
+	err := d.store.History.Delete(run.ID)
+	return errToStatus(err), err
+})
@@ -0,0 +1,35 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/filebrowser/filebrowser/v2/runner"
+)
+
+var hooksLogHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	query := r.URL.Query()
+	q := &runner.LogQuery{
+		Trigger:  query.Get("trigger"),
+		Username: query.Get("username"),
+		Failed:   query.Get("failed") == "true",
+		Limit:    100, //nolint:mnd
+	}
+
+	var err error
+	for name, field := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+		if v := query.Get(name); v != "" {
+			*field, err = strconv.Atoi(v)
+			if err != nil || *field < 0 {
+				return http.StatusBadRequest, err
+			}
+		}
+	}
+
+	executions, err := d.store.Hooks.Find(q)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return renderJSON(w, r, executions)
+})
@@ -5,8 +5,10 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/filebrowser/filebrowser/v2/audit"
 	"github.com/filebrowser/filebrowser/v2/settings"
 	"github.com/filebrowser/filebrowser/v2/storage"
+	"github.com/filebrowser/filebrowser/v2/webhooks"
 )
 
 type modifyRequest struct {
@@ -14,11 +16,13 @@ type modifyRequest struct {
 	Which []string `json:"which"` // Answer to: which fields?
 }
 
-func NewHandler(store *storage.Storage, server *settings.Server) (http.Handler, error) {
+// NewHandler builds the handler of the API and of the frontend. The events
+// are sent to the webhooks by the dispatcher.
+func NewHandler(store *storage.Storage, server *settings.Server, dispatcher *webhooks.Dispatcher) (http.Handler, error) {
 	server.Clean()
 
 	r := mux.NewRouter()
-	index, static := getStaticHandlers(store, server)
+	index, static := getStaticHandlers(store, server, dispatcher)
 
 	// NOTE: This fixes the issue where it would redirect if people did not put a
 	// trailing slash in the end. I hate this decision since this allows some awful
@@ -26,7 +30,7 @@ func NewHandler(store *storage.Storage, server *settings.Server) (http.Handler,
 	r = r.SkipClean(true)
 
 	monkey := func(fn handleFunc, prefix string) http.Handler {
-		return handle(fn, prefix, store, server)
+		return handle(fn, prefix, store, server, dispatcher)
 	}
 
 	r.PathPrefix("/static").Handler(static)
@@ -35,36 +39,94 @@ func NewHandler(store *storage.Storage, server *settings.Server) (http.Handler,
 	api := r.PathPrefix("/api").Subrouter()
 
 	api.Handle("/login", monkey(loginHandler, ""))
+	api.Handle("/login/redirect", monkey(loginRedirectHandler, "")).Methods("GET")
+	api.Handle("/login/callback", monkey(loginCallbackHandler, "")).Methods("GET")
 	api.Handle("/signup", monkey(signupHandler, ""))
 	api.Handle("/renew", monkey(renewHandler, ""))
+	api.Handle("/logout", monkey(logoutHandler, "")).Methods("POST")
+	api.Handle("/password", monkey(withAudit(audit.ActionUserPassword, passwordHandler), "")).Methods("POST")
+
+	sessions := api.PathPrefix("/sessions").Subrouter()
+	sessions.Handle("", monkey(sessionsGetHandler, "")).Methods("GET")
+	sessions.Handle("", monkey(sessionsDeleteHandler, "")).Methods("DELETE")
+	sessions.Handle("/{id}", monkey(sessionDeleteHandler, "")).Methods("DELETE")
+
+	totp := api.PathPrefix("/totp").Subrouter()
+	totp.Handle("", monkey(totpDeleteHandler, "")).Methods("DELETE")
+	totp.Handle("/setup", monkey(totpSetupHandler, "")).Methods("POST")
+	totp.Handle("/enable", monkey(totpEnableHandler, "")).Methods("POST")
 
 	users := api.PathPrefix("/users").Subrouter()
 	users.Handle("", monkey(usersGetHandler, "")).Methods("GET")
-	users.Handle("", monkey(userPostHandler, "")).Methods("POST")
-	users.Handle("/{id:[0-9]+}", monkey(userPutHandler, "")).Methods("PUT")
+	users.Handle("", monkey(withAudit(audit.ActionUserCreate, userPostHandler), "")).Methods("POST")
+	users.Handle("/{id:[0-9]+}", monkey(withAudit(audit.ActionUserUpdate, userPutHandler), "")).Methods("PUT")
 	users.Handle("/{id:[0-9]+}", monkey(userGetHandler, "")).Methods("GET")
-	users.Handle("/{id:[0-9]+}", monkey(userDeleteHandler, "")).Methods("DELETE")
+	users.Handle("/{id:[0-9]+}", monkey(withAudit(audit.ActionUserDelete, userDeleteHandler), "")).Methods("DELETE")
+	users.Handle("/{id:[0-9]+}/sessions", monkey(userSessionsGetHandler, "")).Methods("GET")
+	users.Handle("/{id:[0-9]+}/sessions", monkey(userSessionsDeleteHandler, "")).Methods("DELETE")
+
+	groups := api.PathPrefix("/groups").Subrouter()
+	groups.Handle("", monkey(groupsGetHandler, "")).Methods("GET")
+	groups.Handle("", monkey(withAudit(audit.ActionGroupCreate, groupPostHandler), "")).Methods("POST")
+	groups.Handle("/{id:[0-9]+}", monkey(withAudit(audit.ActionGroupUpdate, groupPutHandler), "")).Methods("PUT")
+	groups.Handle("/{id:[0-9]+}", monkey(groupGetHandler, "")).Methods("GET")
+	groups.Handle("/{id:[0-9]+}", monkey(withAudit(audit.ActionGroupDelete, groupDeleteHandler), "")).Methods("DELETE")
+
+	tokens := api.PathPrefix("/tokens").Subrouter()
+	tokens.Handle("", monkey(tokensGetHandler, "")).Methods("GET")
+	tokens.Handle("", monkey(withAudit(audit.ActionTokenCreate, tokenPostHandler), "")).Methods("POST")
+	tokens.Handle("/{id:[0-9]+}", monkey(withAudit(audit.ActionTokenDelete, tokenDeleteHandler), "")).Methods("DELETE")
+
+	hooks := api.PathPrefix("/webhooks").Subrouter()
+	hooks.Handle("", monkey(webhooksGetHandler, "")).Methods("GET")
+	hooks.Handle("", monkey(withAudit(audit.ActionWebhookCreate, webhookPostHandler), "")).Methods("POST")
+	hooks.Handle("/{id:[0-9]+}", monkey(webhookGetHandler, "")).Methods("GET")
+	hooks.Handle("/{id:[0-9]+}", monkey(withAudit(audit.ActionWebhookUpdate, webhookPutHandler), "")).Methods("PUT")
+	hooks.Handle("/{id:[0-9]+}", monkey(withAudit(audit.ActionWebhookDelete, webhookDeleteHandler), "")).Methods("DELETE")
+	hooks.Handle("/{id:[0-9]+}/deliveries", monkey(webhookDeliveriesHandler, "")).Methods("GET")
+	hooks.Handle("/{id:[0-9]+}/ping", monkey(webhookPingHandler, "")).Methods("POST")
+
+	actions := api.PathPrefix("/actions").Subrouter()
+	actions.Handle("", monkey(actionsGetHandler, "")).Methods("GET")
+	actions.Handle("", monkey(withAudit(audit.ActionActionCreate, actionPostHandler), "")).Methods("POST")
+	actions.Handle("/{id:[0-9]+}", monkey(actionGetHandler, "")).Methods("GET")
+	actions.Handle("/{id:[0-9]+}", monkey(withAudit(audit.ActionActionUpdate, actionPutHandler), "")).Methods("PUT")
+	actions.Handle("/{id:[0-9]+}", monkey(withAudit(audit.ActionActionDelete, actionDeleteHandler), "")).Methods("DELETE")
+	actions.Handle("/{id:[0-9]+}/run", monkey(withAudit(audit.ActionActionRun, actionRunHandler), "")).Methods("POST")
 
 	api.PathPrefix("/resources").Handler(monkey(resourceGetHandler, "/api/resources")).Methods("GET")
-	api.PathPrefix("/resources").Handler(monkey(resourceDeleteHandler, "/api/resources")).Methods("DELETE")
-	api.PathPrefix("/resources").Handler(monkey(resourcePostPutHandler, "/api/resources")).Methods("POST")
-	api.PathPrefix("/resources").Handler(monkey(resourcePostPutHandler, "/api/resources")).Methods("PUT")
-	api.PathPrefix("/resources").Handler(monkey(resourcePatchHandler, "/api/resources")).Methods("PATCH")
+	api.PathPrefix("/resources").Handler(monkey(withAudit(audit.ActionFileDelete, resourceDeleteHandler), "/api/resources")).Methods("DELETE")
+	api.PathPrefix("/resources").Handler(monkey(withAudit(audit.ActionFileCreate, resourcePostPutHandler), "/api/resources")).Methods("POST")
+	api.PathPrefix("/resources").Handler(monkey(withAudit(audit.ActionFileModify, resourcePostPutHandler), "/api/resources")).Methods("PUT")
+	api.PathPrefix("/resources").Handler(monkey(withAudit(audit.ActionFileRename, resourcePatchHandler), "/api/resources")).Methods("PATCH")
+
+	api.Handle("/locks", monkey(locksGetHandler, "")).Methods("GET")
+	api.PathPrefix("/locks").Handler(monkey(withAudit(audit.ActionFileLock, lockPostHandler), "/api/locks")).Methods("POST")
+	api.PathPrefix("/locks").Handler(monkey(lockPutHandler, "/api/locks")).Methods("PUT")
+	api.PathPrefix("/locks").Handler(monkey(withAudit(audit.ActionFileUnlock, lockDeleteHandler), "/api/locks")).Methods("DELETE")
+	api.PathPrefix("/merge").Handler(monkey(mergeHandler, "/api/merge")).Methods("POST")
 
 	api.PathPrefix("/share").Handler(monkey(shareGetsHandler, "/api/share")).Methods("GET")
-	api.PathPrefix("/share").Handler(monkey(sharePostHandler, "/api/share")).Methods("POST")
-	api.PathPrefix("/share").Handler(monkey(shareDeleteHandler, "/api/share")).Methods("DELETE")
+	api.PathPrefix("/share").Handler(monkey(withAudit(audit.ActionShareCreate, sharePostHandler), "/api/share")).Methods("POST")
+	api.PathPrefix("/share").Handler(monkey(withAudit(audit.ActionShareDelete, shareDeleteHandler), "/api/share")).Methods("DELETE")
 
 	api.Handle("/settings", monkey(settingsGetHandler, "")).Methods("GET")
-	api.Handle("/settings", monkey(settingsPutHandler, "")).Methods("PUT")
+	api.Handle("/settings", monkey(withAudit(audit.ActionSettingsUpdate, settingsPutHandler), "")).Methods("PUT")
+
+	api.Handle("/audit", monkey(auditGetHandler, "")).Methods("GET")
+	api.Handle("/hooks", monkey(hooksLogHandler, "")).Methods("GET")
 
-	api.PathPrefix("/raw").Handler(monkey(rawHandler, "/api/raw")).Methods("GET")
+	api.PathPrefix("/raw").Handler(monkey(withAudit(audit.ActionFileDownload, rawHandler), "/api/raw")).Methods("GET")
 	api.PathPrefix("/preview/{size}/{path:.*}").Handler(monkey(previewHandler, "/api/preview")).Methods("GET")
-	api.PathPrefix("/command").Handler(monkey(commandsHandler, "/api/command")).Methods("GET")
+	api.PathPrefix("/tail").Handler(monkey(withAudit(audit.ActionFileTail, tailHandler), "/api/tail")).Methods("GET")
+	api.PathPrefix("/command").Handler(monkey(withAudit(audit.ActionCommandRun, commandsHandler), "/api/command")).Methods("GET")
+	api.Handle("/history", monkey(historyGetHandler, "")).Methods("GET")
+	api.Handle("/history/{id:[0-9]+}", monkey(historyRunGetHandler, "")).Methods("GET")
+	api.Handle("/history/{id:[0-9]+}", monkey(historyRunDeleteHandler, "")).Methods("DELETE")
 	api.PathPrefix("/search").Handler(monkey(searchHandler, "/api/search")).Methods("GET")
 
 	public := api.PathPrefix("/public").Subrouter()
-	public.PathPrefix("/dl").Handler(monkey(publicDlHandler, "/api/public/dl/")).Methods("GET")
+	public.PathPrefix("/dl").Handler(monkey(withAudit(audit.ActionFileDownload, publicDlHandler), "/api/public/dl/")).Methods("GET")
 	public.PathPrefix("/share").Handler(monkey(publicShareHandler, "/api/public/share/")).Methods("GET")
 
 	return stripPrefix(server.BaseURL, r), nil
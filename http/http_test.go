@@ -0,0 +1,133 @@
+package http
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asdine/storm"
+	"github.com/spf13/afero"
+
+	"github.com/filebrowser/filebrowser/v2/rules"
+	"github.com/filebrowser/filebrowser/v2/settings"
+	"github.com/filebrowser/filebrowser/v2/storage"
+	"github.com/filebrowser/filebrowser/v2/storage/bolt"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// testEnv is an instance with a single user, authenticated by a personal
+// access token, whose files are in a temporary folder.
+type testEnv struct {
+	store  *storage.Storage
+	server *settings.Server
+	user   *users.User
+	token  string
+}
+
+func newTestEnv(t *testing.T, perm users.Permissions, userRules ...rules.Rule) *testEnv {
+	dir, err := ioutil.TempDir(os.TempDir(), "filebrowser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	root := filepath.Join(dir, "root")
+	if err = os.Mkdir(root, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := storm.Open(filepath.Join(dir, "database.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store, err := bolt.NewStorage(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.Settings.Save(&settings.Settings{Key: []byte("key")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &settings.Server{Root: root}
+	err = store.Settings.SaveServer(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &users.User{
+		Username: "user",
+		Password: "password",
+		Scope:    ".",
+		Perm:     perm,
+		Rules:    userRules,
+	}
+	if err = store.Users.Save(user); err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := store.Tokens.Create(user.ID, "tests", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err = store.Users.Get(root, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testEnv{store: store, server: server, user: user, token: token}
+}
+
+// request creates a request of the user.
+func (e *testEnv) request(method, target string, body io.Reader) *http.Request {
+	r := httptest.NewRequest(method, target, body)
+	r.Header.Set("X-Auth", e.token)
+	return r
+}
+
+// serve serves a request with fn.
+func (e *testEnv) serve(fn handleFunc, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handle(fn, "", e.store, e.server, nil).ServeHTTP(w, r)
+	return w
+}
+
+// do serves a request of the user with fn.
+func (e *testEnv) do(fn handleFunc, method, target string, body io.Reader) *httptest.ResponseRecorder {
+	return e.serve(fn, e.request(method, target, body))
+}
+
+func (e *testEnv) writeFile(t *testing.T, name, content string) {
+	err := e.user.Fs.MkdirAll(filepath.Dir(name), 0700)
+	if err == nil {
+		err = afero.WriteFile(e.user.Fs, name, []byte(content), 0600)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (e *testEnv) exists(t *testing.T, name string) bool {
+	ok, err := afero.Exists(e.user.Fs, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ok
+}
+
+func checkStatus(t *testing.T, w *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if w.Code != want {
+		t.Fatalf("got status %d, want %d: %s", w.Code, want, w.Body.String())
+	}
+}
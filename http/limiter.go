@@ -0,0 +1,194 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tomasen/realip"
+
+	"github.com/filebrowser/filebrowser/v2/settings"
+)
+
+const (
+	// loginAttemptsTTL is how long the failed login attempts of an IP or
+	// a username are remembered after the last one.
+	loginAttemptsTTL = time.Hour
+	loginBaseDelay   = time.Second
+	loginMaxShift    = 30
+)
+
+// loginLimiter slows down the login attempts of the IPs and the usernames
+// that failed too many times, with an exponential backoff. Its state is
+// kept in memory, so it is lost on restart.
+type loginLimiter struct {
+	mux      sync.Mutex
+	attempts map[string]*loginAttempts
+	pruned   time.Time
+}
+
+type loginAttempts struct {
+	failures int
+	pending  int
+	last     time.Time
+	until    time.Time
+}
+
+var limiter = &loginLimiter{attempts: map[string]*loginAttempts{}}
+
+func loginLimiterKeys(ip, username string) []string {
+	keys := []string{"ip:" + ip}
+	if username != "" {
+		keys = append(keys, "user:"+strings.ToLower(username))
+	}
+	return keys
+}
+
+// clientIP returns the IP of the client of a request. The headers set by
+// the proxies are only used if the request comes from a trusted proxy, as
+// the clients could set them to anything.
+func clientIP(r *http.Request, cfg settings.BruteForce) string {
+	if cfg.TrustedRequest(r) {
+		return realip.FromRequest(r)
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return host
+}
+
+// reserve reserves a login attempt, which must then be ended by fail,
+// reset or release. The attempts in progress count as failures, so that
+// the ones made in parallel can't go past the limit. If no attempt can
+// be made yet, it returns how long the caller must wait before trying
+// again.
+func (l *loginLimiter) reserve(now time.Time, cfg settings.BruteForce, keys ...string) time.Duration {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	var wait time.Duration
+	for _, key := range keys {
+		a, ok := l.attempts[key]
+		if !ok {
+			continue
+		}
+
+		if a.until.Sub(now) > wait {
+			wait = a.until.Sub(now)
+		}
+
+		// The attempts in progress could all fail.
+		if a.pending > 0 {
+			if delay := loginDelay(a.failures+a.pending+1, cfg); delay > wait {
+				wait = delay
+			}
+		}
+	}
+
+	if wait > 0 {
+		return wait
+	}
+
+	for _, key := range keys {
+		a, ok := l.attempts[key]
+		if !ok {
+			a = &loginAttempts{}
+			l.attempts[key] = a
+		}
+
+		a.pending++
+		a.last = now
+	}
+
+	return 0
+}
+
+// fail records the failure of a reserved attempt.
+func (l *loginLimiter) fail(now time.Time, cfg settings.BruteForce, keys ...string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.prune(now)
+
+	for _, key := range keys {
+		a, ok := l.attempts[key]
+		if !ok {
+			a = &loginAttempts{}
+			l.attempts[key] = a
+		}
+
+		if a.pending > 0 {
+			a.pending--
+		}
+
+		a.failures++
+		a.last = now
+
+		if delay := loginDelay(a.failures, cfg); delay > 0 {
+			a.until = now.Add(delay)
+		}
+	}
+}
+
+// release ends a reserved attempt that neither failed nor succeeded.
+func (l *loginLimiter) release(keys ...string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	for _, key := range keys {
+		if a, ok := l.attempts[key]; ok && a.pending > 0 {
+			a.pending--
+		}
+	}
+}
+
+// loginDelay returns how long to wait after the given number of
+// failures, which grows exponentially past the free attempts.
+func loginDelay(failures int, cfg settings.BruteForce) time.Duration {
+	extra := failures - cfg.FreeAttempts
+	if extra <= 0 {
+		return 0
+	}
+
+	shift := extra - 1
+	if shift > loginMaxShift {
+		shift = loginMaxShift
+	}
+
+	delay := loginBaseDelay << uint(shift)
+	if maxDelay := time.Duration(cfg.MaxDelay) * time.Second; delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay
+}
+
+// reset forgets the failed attempts after a successful login.
+func (l *loginLimiter) reset(keys ...string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	for _, key := range keys {
+		delete(l.attempts, key)
+	}
+}
+
+// prune forgets the attempts that are too old, at most once a minute.
+// It must be called with the lock held.
+func (l *loginLimiter) prune(now time.Time) {
+	if now.Sub(l.pruned) < time.Minute {
+		return
+	}
+
+	l.pruned = now
+	for key, a := range l.attempts {
+		if a.pending == 0 && now.Sub(a.last) > loginAttemptsTTL && now.After(a.until) {
+			delete(l.attempts, key)
+		}
+	}
+}
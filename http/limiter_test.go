@@ -0,0 +1,154 @@
+package http
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filebrowser/filebrowser/v2/settings"
+)
+
+func TestClientIP(t *testing.T) {
+	cfg := settings.BruteForce{TrustedProxies: []string{"10.0.0.1", "192.168.0.0/16"}}
+
+	tests := []struct {
+		remote    string
+		forwarded string
+		realIP    string
+		want      string
+	}{
+		{"203.0.113.1:1234", "", "", "203.0.113.1"},
+		{"203.0.113.1:1234", "198.51.100.1", "198.51.100.2", "203.0.113.1"},
+		{"10.0.0.1:1234", "198.51.100.1", "", "198.51.100.1"},
+		{"10.0.0.1:1234", "", "198.51.100.2", "198.51.100.2"},
+		{"192.168.1.1:1234", "198.51.100.1, 10.0.0.1", "", "198.51.100.1"},
+		{"10.0.0.2:1234", "198.51.100.1", "", "10.0.0.2"},
+		{"[2001:db8::1]:1234", "198.51.100.1", "", "2001:db8::1"},
+		{"203.0.113.1", "198.51.100.1", "", "203.0.113.1"},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remote
+		if tt.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		if tt.realIP != "" {
+			r.Header.Set("X-Real-Ip", tt.realIP)
+		}
+
+		if got := clientIP(r, cfg); got != tt.want {
+			t.Errorf("%s with %q and %q: got %s, want %s", tt.remote, tt.forwarded, tt.realIP, got, tt.want)
+		}
+	}
+}
+
+func TestLoginDelay(t *testing.T) {
+	cfg := settings.BruteForce{FreeAttempts: 3, MaxDelay: 10}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{3, 0},
+		{4, time.Second},
+		{5, 2 * time.Second},
+		{7, 8 * time.Second},
+		{8, 10 * time.Second},
+		{1000, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := loginDelay(tt.failures, cfg); got != tt.want {
+			t.Errorf("%d failures: got %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLimiterReserveParallel(t *testing.T) {
+	l := &loginLimiter{attempts: map[string]*loginAttempts{}}
+	cfg := settings.BruteForce{FreeAttempts: 3, MaxDelay: 60}
+	now := time.Now()
+	keys := loginLimiterKeys("203.0.113.1", "Admin")
+
+	var (
+		wg       sync.WaitGroup
+		mux      sync.Mutex
+		reserved int
+	)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if l.reserve(now, cfg, keys...) == 0 {
+				mux.Lock()
+				reserved++
+				mux.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The attempts in progress could all fail, so no more than the free
+	// attempts are made at once.
+	if reserved != cfg.FreeAttempts {
+		t.Fatalf("reserved %d attempts, want %d", reserved, cfg.FreeAttempts)
+	}
+
+	for i := 0; i < reserved; i++ {
+		l.fail(now, cfg, keys...)
+	}
+
+	if wait := l.reserve(now, cfg, keys...); wait != 0 {
+		t.Fatalf("the last free attempt waits %s", wait)
+	}
+	l.fail(now, cfg, keys...)
+
+	if wait := l.reserve(now, cfg, keys...); wait != time.Second {
+		t.Errorf("got wait %s, want %s", wait, time.Second)
+	}
+
+	// The username is limited whatever the IP.
+	other := loginLimiterKeys("203.0.113.2", "admin")
+	if wait := l.reserve(now, cfg, other...); wait != time.Second {
+		t.Errorf("another IP: got wait %s, want %s", wait, time.Second)
+	}
+
+	if wait := l.reserve(now, cfg, "ip:203.0.113.2"); wait != 0 {
+		t.Errorf("another IP without username: got wait %s", wait)
+	}
+
+	if wait := l.reserve(now.Add(2*time.Second), cfg, keys...); wait != 0 {
+		t.Errorf("after the delay: got wait %s", wait)
+	}
+}
+
+func TestLimiterReleaseReset(t *testing.T) {
+	l := &loginLimiter{attempts: map[string]*loginAttempts{}}
+	cfg := settings.BruteForce{FreeAttempts: 1, MaxDelay: 60}
+	now := time.Now()
+
+	// The attempts that neither failed nor succeeded don't count.
+	for i := 0; i < 3; i++ {
+		if wait := l.reserve(now, cfg, "ip:a"); wait != 0 {
+			t.Fatalf("attempt %d waits %s", i, wait)
+		}
+		l.release("ip:a")
+	}
+
+	l.reserve(now, cfg, "ip:a")
+	l.fail(now, cfg, "ip:a")
+	l.reserve(now, cfg, "ip:a")
+	l.fail(now, cfg, "ip:a")
+	if wait := l.reserve(now, cfg, "ip:a"); wait == 0 {
+		t.Fatal("no wait after the failures")
+	}
+
+	l.reset("ip:a")
+	if wait := l.reserve(now, cfg, "ip:a"); wait != 0 {
+		t.Errorf("got wait %s after a reset", wait)
+	}
+}
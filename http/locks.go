@@ -0,0 +1,179 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/files"
+	"github.com/filebrowser/filebrowser/v2/locks"
+	"github.com/filebrowser/filebrowser/v2/rules"
+)
+
+type lockRequest struct {
+	// Timeout is the number of seconds the lock lasts if not refreshed.
+	Timeout int64 `json:"timeout"`
+}
+
+func getLockRequest(r *http.Request) (*lockRequest, error) {
+	req := &lockRequest{}
+	if r.Body == nil || r.ContentLength == 0 {
+		return req, nil
+	}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	return req, err
+}
+
+// locked writes that a file is locked, and by who.
+func locked(w http.ResponseWriter, l *locks.Lock) (int, error) {
+	msg := fmt.Sprintf("%d the file is locked by %s", http.StatusLocked, l.Username)
+	http.Error(w, msg, http.StatusLocked)
+	return 0, errors.ErrLocked
+}
+
+// checkLock rejects the changes of a file, or of a directory, locked by
+// another user.
+func checkLock(w http.ResponseWriter, d *data, path string) (int, error) {
+	l, err := d.store.Locks.Check(d.user.FullPath(path), d.user.ID)
+	switch {
+	case err == errors.ErrLocked:
+		return locked(w, l)
+	case err != nil:
+		return http.StatusInternalServerError, err
+	}
+
+	return 0, nil
+}
+
+// attachLocks sets the locks of a file, or of the files of a directory.
+// Only the owners of the locks are given their tokens.
+func attachLocks(d *data, file *files.FileInfo) error {
+	list, err := d.store.Locks.Gets()
+	if err != nil || len(list) == 0 {
+		return err
+	}
+
+	byPath := map[string]*locks.Lock{}
+	for _, l := range list {
+		byPath[l.Path] = l
+		l.HideSecrets(d.user.ID)
+	}
+
+	file.Lock = byPath[d.user.FullPath(file.Path)]
+	if file.Listing == nil {
+		return nil
+	}
+
+	for _, item := range file.Items {
+		item.Lock = byPath[d.user.FullPath(item.Path)]
+	}
+
+	return nil
+}
+
+var locksGetHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	list, err := d.store.Locks.Gets()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Created < list[j].Created
+	})
+
+	return renderJSON(w, r, list)
+})
+
+// lockPostHandler locks a file the user can modify, or refreshes the
+// lock if the user already holds it.
+var lockPostHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	d.entry.Source = r.URL.Path
+	if !d.Check(r.URL.Path) || !d.can(d.user.Perm.Modify, r.URL.Path, rules.ActionModify) {
+		return http.StatusForbidden, nil
+	}
+
+	req, err := getLockRequest(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	info, err := d.user.Fs.Stat(r.URL.Path)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	if info.IsDir() {
+		return http.StatusBadRequest, errors.ErrIsDirectory
+	}
+
+	l, err := d.store.Locks.Acquire(d.user.FullPath(r.URL.Path), d.user.ID, d.user.Username, req.Timeout)
+	if err == errors.ErrLocked {
+		return locked(w, l)
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	l.HideSecrets(d.user.ID)
+	return renderJSON(w, r, l)
+})
+
+// lockPutHandler refreshes the lock the user holds on a file, unless it
+// was released meanwhile.
+var lockPutHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	req, err := getLockRequest(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	l, err := d.store.Locks.GetByPath(d.user.FullPath(r.URL.Path))
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	if l.UserID != d.user.ID {
+		return locked(w, l)
+	}
+
+	err = d.store.Locks.Refresh(l, req.Timeout)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	l.HideSecrets(d.user.ID)
+	return renderJSON(w, r, l)
+})
+
+// lockDeleteHandler releases the lock the user holds on a file. The
+// administrators can release the locks of the other users, given by
+// their files or by their tokens, for the files out of their scope.
+var lockDeleteHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	d.entry.Source = r.URL.Path
+
+	var (
+		l   *locks.Lock
+		err error
+	)
+
+	if token := r.URL.Query().Get("token"); token != "" && d.user.Perm.Admin {
+		l, err = d.store.Locks.Get(token)
+	} else {
+		l, err = d.store.Locks.GetByPath(d.user.FullPath(r.URL.Path))
+	}
+
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	if l.UserID != d.user.ID {
+		if !d.user.Perm.Admin {
+			return locked(w, l)
+		}
+		d.entry.Details = "forced, locked by " + l.Username
+	}
+
+	err = d.store.Locks.Delete(l.Token)
+	return errToStatus(err), err
+})
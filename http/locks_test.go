@@ -0,0 +1,59 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/filebrowser/filebrowser/v2/audit"
+	"github.com/filebrowser/filebrowser/v2/rules"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+func TestLockPost(t *testing.T) {
+	e := newTestEnv(t, users.Permissions{Modify: true},
+		rules.Rule{Path: "/hidden"},
+		rules.Rule{Path: "/readonly", Actions: []rules.Action{rules.ActionModify}},
+	)
+	e.writeFile(t, "/hidden/a.txt", "a")
+	e.writeFile(t, "/readonly/a.txt", "a")
+	e.writeFile(t, "/public/a.txt", "a")
+
+	post := withAudit(audit.ActionFileLock, lockPostHandler)
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/hidden/a.txt", http.StatusForbidden},
+		{"/readonly/a.txt", http.StatusForbidden},
+		{"/public/missing.txt", http.StatusNotFound},
+		{"/public/a.txt", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		w := e.do(post, "POST", tt.path, nil)
+		checkStatus(t, w, tt.status)
+
+		_, err := e.store.Locks.GetByPath(e.user.FullPath(tt.path))
+		if locked := err == nil; locked != (tt.status == http.StatusOK) {
+			t.Errorf("lock of %s: locked is %v", tt.path, locked)
+		}
+	}
+}
+
+func TestLockPutReleased(t *testing.T) {
+	e := newTestEnv(t, users.Permissions{Modify: true})
+	e.writeFile(t, "/a.txt", "a")
+
+	l, err := e.store.Locks.Acquire(e.user.FullPath("/a.txt"), e.user.ID, e.user.Username, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkStatus(t, e.do(lockPutHandler, "PUT", "/a.txt", nil), http.StatusOK)
+
+	if err = e.store.Locks.Delete(l.Token); err != nil {
+		t.Fatal(err)
+	}
+
+	checkStatus(t, e.do(lockPutHandler, "PUT", "/a.txt", nil), http.StatusNotFound)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/merge"
+	"github.com/filebrowser/filebrowser/v2/rules"
+)
+
+// mergeRequest is the content an editor tried to save and the content of
+// the file it was editing, before it was modified by someone else.
+type mergeRequest struct {
+	Base    string `json:"base"`
+	Content string `json:"content"`
+}
+
+// mergeResponse is the merge of the content of an editor with the one of
+// the file, which is given along with its entity tag for the editor to
+// save the merge with If-Match and to merge it again if needed.
+type mergeResponse struct {
+	*merge.Result
+	Current string `json:"current"`
+	ETag    string `json:"etag"`
+}
+
+// mergeHandler merges the changes made in an editor with the ones saved
+// to a text file meanwhile, which a save with If-Match refused. The text
+// files too big to be edited aren't merged either.
+var mergeHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	// The content of the file is sent back and the merge is to be saved.
+	if !d.Check(r.URL.Path) ||
+		!d.can(d.user.Perm.Download, r.URL.Path, rules.ActionDownload) ||
+		!d.can(d.user.Perm.Modify, r.URL.Path, rules.ActionModify) {
+		return http.StatusForbidden, nil
+	}
+
+	if r.Body == nil {
+		return http.StatusBadRequest, errors.ErrEmptyRequest
+	}
+
+	req := &mergeRequest{}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 3*d.settings.MaxTextSize)).Decode(req) //nolint:mnd
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	file, err := d.user.Fs.Open(r.URL.Path)
+	if err != nil {
+		return errToStatus(err), err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	if info.IsDir() {
+		return http.StatusBadRequest, errors.ErrIsDirectory
+	}
+
+	if info.Size() > d.settings.MaxTextSize {
+		return invalid(w, errors.ErrNotText)
+	}
+
+	current, err := ioutil.ReadAll(file)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if !utf8.Valid(current) {
+		return invalid(w, errors.ErrNotText)
+	}
+
+	etag := fileETag(info.ModTime(), info.Size())
+	w.Header().Set("ETag", etag)
+
+	return renderJSON(w, r, &mergeResponse{
+		Result:  merge.Merge(req.Base, req.Content, string(current), "yours", "saved"),
+		Current: string(current),
+		ETag:    etag,
+	})
+})
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+type passwordRequest struct {
+	Current  string `json:"current"`
+	Password string `json:"password"`
+}
+
+// passwordHandler changes the password of the current user. It is the
+// only thing a user can do while it has to change its password.
+var passwordHandler = withPendingUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	d.entry.Source = d.user.Username
+	if d.token != nil || d.user.LockPassword {
+		return http.StatusForbidden, nil
+	}
+
+	if r.Body == nil {
+		return http.StatusBadRequest, nil
+	}
+
+	req := &passwordRequest{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if !users.CheckPwd(req.Current, d.user.Password) {
+		return http.StatusForbidden, nil
+	}
+
+	if req.Current == req.Password {
+		return http.StatusBadRequest, nil
+	}
+
+	status, err := setPassword(d, d.user, req.Password)
+	if status != 0 {
+		return status, err
+	}
+
+	d.user.MustChangePassword = false
+	err = d.store.Users.Update(d.user, "Password", "PasswordChanged", "MustChangePassword")
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	err = revokeOtherSessions(d, d.user.ID)
+	return errToStatus(err), err
+})
+
+// setPassword checks the password against the password policy and,
+// if it complies, sets it as the new password of the user.
+func setPassword(d *data, user *users.User, password string) (int, error) {
+	err := d.settings.Password.Check(user.Username, password)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	user.Password, err = users.HashPwd(password)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	user.PasswordChanged = time.Now().Unix()
+	return 0, nil
+}
+
+// revokeOtherSessions logs a user whose password was changed out of
+// all its sessions, but the current one if it's the user's own.
+func revokeOtherSessions(d *data, userID uint) error {
+	keep := ""
+	if d.session != nil && d.session.UserID == userID {
+		keep = d.session.ID
+	}
+
+	return d.store.Sessions.DeleteOthers(userID, keep)
+}
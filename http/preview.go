@@ -9,6 +9,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/filebrowser/filebrowser/v2/files"
+	"github.com/filebrowser/filebrowser/v2/rules"
 )
 
 const (
@@ -28,12 +29,18 @@ var previewHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *da
 		return http.StatusNotImplemented, nil
 	}
 
+	path := "/" + vars["path"]
+	if !d.CheckAction(path, rules.ActionDownload) {
+		return http.StatusForbidden, nil
+	}
+
 	file, err := files.NewFileInfo(files.FileOptions{
-		Fs:      d.user.Fs,
-		Path:    "/" + vars["path"],
-		Modify:  d.user.Perm.Modify,
-		Expand:  true,
-		Checker: d,
+		Fs:          d.user.Fs,
+		Path:        path,
+		Modify:      d.can(d.user.Perm.Modify, path, rules.ActionModify),
+		Expand:      true,
+		MaxTextSize: d.settings.MaxTextSize,
+		Checker:     d,
 	})
 	if err != nil {
 		return errToStatus(err), err
@@ -2,9 +2,12 @@ package http
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/filebrowser/filebrowser/v2/files"
+	"github.com/filebrowser/filebrowser/v2/rules"
+	"github.com/filebrowser/filebrowser/v2/share"
 )
 
 var withHashFile = func(fn handleFunc) handleFunc {
@@ -22,12 +25,15 @@ var withHashFile = func(fn handleFunc) handleFunc {
 			return errToStatus(err), err
 		}
 
-		d.user = user
+		err = d.setUser(user)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
 
 		file, err := files.NewFileInfo(files.FileOptions{
 			Fs:      d.user.Fs,
 			Path:    link.Path,
-			Modify:  d.user.Perm.Modify,
+			Modify:  d.can(d.user.Perm.Modify, link.Path, rules.ActionModify),
 			Expand:  false,
 			Checker: d,
 		})
@@ -36,6 +42,7 @@ var withHashFile = func(fn handleFunc) handleFunc {
 		}
 
 		d.raw = file
+		d.link = link
 		return fn(w, r, d)
 	}
 }
@@ -54,14 +61,55 @@ func ifPathWithName(r *http.Request) string {
 }
 
 var publicShareHandler = withHashFile(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
-	return renderJSON(w, r, d.raw)
+	file := d.raw.(*files.FileInfo)
+	vars := shareVars(d.link, "view")
+
+	err := d.Before("share_access", file.Path, "", d.user, vars)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	status, err := renderJSON(w, r, d.raw)
+	if status == 0 && err == nil {
+		d.runAfter("share_access", file.Path, "", d.user, vars)
+	}
+
+	return status, err
 })
 
 var publicDlHandler = withHashFile(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
 	file := d.raw.(*files.FileInfo)
-	if !file.IsDir {
-		return rawFileHandler(w, r, file)
+	d.entry.Source = file.Path
+	d.entry.Details = "public share"
+	if !d.CheckAction(file.Path, rules.ActionDownload) {
+		return http.StatusForbidden, nil
+	}
+
+	// The archives are described to the after hooks as well.
+	vars := shareVars(d.link, "download")
+	err := d.Before("share_access", file.Path, "", d.user, vars)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	status, err := download(w, r, d, file, vars)
+	if status == 0 && err == nil {
+		d.runAfter("share_access", file.Path, "", d.user, vars)
 	}
 
-	return rawDirHandler(w, r, d, file)
+	return status, err
 })
+
+// shareVars describes a share link, and how it is accessed, to the hooks.
+func shareVars(link *share.Link, access string) map[string]string {
+	vars := map[string]string{
+		"SHARE_HASH":   link.Hash,
+		"SHARE_EXPIRE": strconv.FormatInt(link.Expire, 10),
+	}
+
+	if access != "" {
+		vars["SHARE_ACCESS"] = access
+	}
+
+	return vars
+}
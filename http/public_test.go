@@ -0,0 +1,53 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/filebrowser/filebrowser/v2/audit"
+	"github.com/filebrowser/filebrowser/v2/share"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+func TestPublicDlArchiveVars(t *testing.T) {
+	e := newTestEnv(t, users.Permissions{Download: true, Share: true})
+	e.writeFile(t, "/shared/a.txt", "a")
+	e.writeFile(t, "/shared/b.txt", "b")
+
+	err := e.store.Share.Save(&share.Link{Hash: "hash", Path: "/shared", UserID: e.user.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	marker := filepath.Join(e.server.Root, "hook-vars")
+	set, err := e.store.Settings.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set.Shell = []string{"sh", "-c"}
+	set.Commands["after_share_access"] = []string{
+		`printf '%s|%s|%s' "$SHARE_ACCESS" "$DOWNLOAD_ARCHIVE" "$DOWNLOAD_FILES" > ` + marker,
+	}
+	if err = e.store.Settings.Save(set); err != nil {
+		t.Fatal(err)
+	}
+
+	dl := withAudit(audit.ActionFileDownload, publicDlHandler)
+	// The prefix of the route, up to the hash, is stripped.
+	r := e.request("GET", "/hash?algo=zip", nil)
+	r.URL.Path = "hash"
+	w := e.serve(dl, r)
+	checkStatus(t, w, http.StatusOK)
+
+	got, err := ioutil.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("the hook didn't run: %v", err)
+	}
+
+	if want := "download|zip|/shared"; string(got) != want {
+		t.Errorf("got variables %q, want %q", got, want)
+	}
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/hacdias/fileutils"
 	"github.com/mholt/archiver"
 
+	"github.com/filebrowser/filebrowser/v2/audit"
 	"github.com/filebrowser/filebrowser/v2/files"
+	"github.com/filebrowser/filebrowser/v2/rules"
 	"github.com/filebrowser/filebrowser/v2/users"
 )
 
@@ -68,14 +70,20 @@ func setContentDisposition(w http.ResponseWriter, r *http.Request, file *files.F
 }
 
 var rawHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	d.entry.Source = r.URL.Path
 	if !d.user.Perm.Download {
+		d.entry.Result = audit.ResultDenied
 		return http.StatusAccepted, nil
 	}
 
+	if !d.CheckAction(r.URL.Path, rules.ActionDownload) {
+		return http.StatusForbidden, nil
+	}
+
 	file, err := files.NewFileInfo(files.FileOptions{
 		Fs:      d.user.Fs,
 		Path:    r.URL.Path,
-		Modify:  d.user.Perm.Modify,
+		Modify:  d.can(d.user.Perm.Modify, r.URL.Path, rules.ActionModify),
 		Expand:  false,
 		Checker: d,
 	})
@@ -83,17 +91,52 @@ var rawHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data)
 		return errToStatus(err), err
 	}
 
+	return download(w, r, d, file, map[string]string{})
+})
+
+// download serves a file, or an archive of the files of a folder,
+// between the hooks of the download event. The archives are described
+// to the hooks in DOWNLOAD_ARCHIVE, their format, and DOWNLOAD_FILES,
+// the list of the paths they are made of, one per line.
+func download(w http.ResponseWriter, r *http.Request, d *data, file *files.FileInfo, vars map[string]string) (int, error) {
+	if file.IsDir {
+		filenames, err := parseQueryFiles(r, file, d.user)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+
+		extension, _, err := parseQueryAlgorithm(r)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+
+		vars["DOWNLOAD_ARCHIVE"] = strings.TrimPrefix(extension, ".")
+		vars["DOWNLOAD_FILES"] = strings.Join(filenames, "\n")
+	}
+
+	err := d.Before("download", file.Path, "", d.user, vars)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	var status int
 	if !file.IsDir {
-		return rawFileHandler(w, r, file)
+		status, err = rawFileHandler(w, r, file)
+	} else {
+		status, err = rawDirHandler(w, r, d, file)
 	}
 
-	return rawDirHandler(w, r, d, file)
-})
+	if status == 0 && err == nil {
+		d.runAfter("download", file.Path, "", d.user, vars)
+	}
+
+	return status, err
+}
 
 func addFile(ar archiver.Writer, d *data, path string) error {
 	// Checks are always done with paths with "/" as path separator.
 	path = strings.Replace(path, "\\", "/", -1)
-	if !d.Check(path) {
+	if !d.Check(path) || !d.CheckAction(path, rules.ActionDownload) {
 		return nil
 	}
 
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -8,31 +9,111 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/filebrowser/filebrowser/v2/audit"
 	"github.com/filebrowser/filebrowser/v2/errors"
 	"github.com/filebrowser/filebrowser/v2/files"
 	"github.com/filebrowser/filebrowser/v2/fileutils"
+	"github.com/filebrowser/filebrowser/v2/rules"
 )
 
+// contentOptions parses the part of a text file a request asks for, if
+// any: see files.ContentOptions.
+func contentOptions(r *http.Request, maxSize int64) (*files.ContentOptions, error) {
+	opts := &files.ContentOptions{MaxSize: maxSize}
+	params := []struct {
+		name  string
+		value *int64
+	}{
+		{"offset", &opts.Offset},
+		{"length", &opts.Length},
+		{"line", &opts.Line},
+		{"lines", &opts.Lines},
+		{"tail", &opts.Tail},
+	}
+
+	found := false
+	for _, p := range params {
+		raw := r.URL.Query().Get(p.name)
+		if raw == "" {
+			continue
+		}
+
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid %s: %w", p.name, errors.ErrInvalidRequestParams)
+		}
+
+		*p.value = v
+		found = true
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return opts, nil
+}
+
+// resourceGetHandler gets a file or a directory. The content of the text
+// files is only given if asked with content=true, when they aren't too
+// big, or by parts: see contentOptions.
 var resourceGetHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	ranged, err := contentOptions(r, d.settings.MaxTextSize)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	content := ranged == nil && r.URL.Query().Get("content") == "true"
+	download := d.can(d.user.Perm.Download, r.URL.Path, rules.ActionDownload)
+
 	file, err := files.NewFileInfo(files.FileOptions{
-		Fs:      d.user.Fs,
-		Path:    r.URL.Path,
-		Modify:  d.user.Perm.Modify,
-		Expand:  true,
-		Checker: d,
+		Fs:          d.user.Fs,
+		Path:        r.URL.Path,
+		Modify:      d.can(d.user.Perm.Modify, r.URL.Path, rules.ActionModify),
+		Expand:      true,
+		Content:     content && download,
+		MaxTextSize: d.settings.MaxTextSize,
+		Checker:     d,
 	})
 	if err != nil {
 		return errToStatus(err), err
 	}
 
+	// The content of the files, or a part of it, is downloaded.
+	if !file.IsDir && (content || ranged != nil) && !download {
+		return http.StatusForbidden, nil
+	}
+
+	err = attachLocks(d, file)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if !file.IsDir {
+		w.Header().Set("ETag", fileETag(file.ModTime, file.Size))
+		w.Header().Set("Last-Modified", file.ModTime.UTC().Format(http.TimeFormat))
+	}
+
 	if file.IsDir {
 		file.Listing.Sorting = d.user.Sorting
 		file.Listing.ApplySort()
 		return renderJSON(w, r, file)
 	}
 
+	if ranged != nil {
+		err = file.ReadContent(*ranged)
+		if err == errors.ErrNotText {
+			return invalid(w, err)
+		} else if err != nil {
+			return errToStatus(err), err
+		}
+	}
+
 	if checksum := r.URL.Query().Get("checksum"); checksum != "" {
 		err := file.Checksum(checksum)
 		if err == errors.ErrInvalidOption {
@@ -49,10 +130,15 @@ var resourceGetHandler = withUser(func(w http.ResponseWriter, r *http.Request, d
 })
 
 var resourceDeleteHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
-	if r.URL.Path == "/" || !d.user.Perm.Delete {
+	d.entry.Source = r.URL.Path
+	if r.URL.Path == "/" || !d.can(d.user.Perm.Delete, r.URL.Path, rules.ActionDelete) {
 		return http.StatusForbidden, nil
 	}
 
+	if status, err := checkLock(w, d, r.URL.Path); status != 0 || err != nil {
+		return status, err
+	}
+
 	err := d.RunHook(func() error {
 		return d.user.Fs.RemoveAll(r.URL.Path)
 	}, "delete", r.URL.Path, "", d.user)
@@ -65,11 +151,12 @@ var resourceDeleteHandler = withUser(func(w http.ResponseWriter, r *http.Request
 })
 
 var resourcePostPutHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
-	if !d.user.Perm.Create && r.Method == http.MethodPost {
+	d.entry.Source = r.URL.Path
+	if r.Method == http.MethodPost && !d.can(d.user.Perm.Create, r.URL.Path, rules.ActionCreate) {
 		return http.StatusForbidden, nil
 	}
 
-	if !d.user.Perm.Modify && r.Method == http.MethodPut {
+	if r.Method == http.MethodPut && !d.can(d.user.Perm.Modify, r.URL.Path, rules.ActionModify) {
 		return http.StatusForbidden, nil
 	}
 
@@ -83,7 +170,9 @@ var resourcePostPutHandler = withUser(func(w http.ResponseWriter, r *http.Reques
 			return http.StatusMethodNotAllowed, nil
 		}
 
-		err := d.user.Fs.MkdirAll(r.URL.Path, 0775)
+		err := d.RunHook(func() error {
+			return d.user.Fs.MkdirAll(r.URL.Path, 0775)
+		}, "mkdir", r.URL.Path, "", d.user)
 		return errToStatus(err), err
 	}
 
@@ -93,12 +182,50 @@ var resourcePostPutHandler = withUser(func(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
+	if status, err := checkLock(w, d, r.URL.Path); status != 0 || err != nil {
+		return status, err
+	}
+
 	action := "upload"
 	if r.Method == http.MethodPut {
 		action = "save"
 	}
 
+	// The conditional saves, made by the editor, are checked and written
+	// at once, so the body is read first. It can't be bigger than the
+	// text files that can be edited.
+	var body io.Reader = r.Body
+	conditional := r.Method == http.MethodPut &&
+		(r.Header.Get("If-Match") != "" || r.Header.Get("If-Unmodified-Since") != "")
+	if conditional {
+		content, err := ioutil.ReadAll(io.LimitReader(r.Body, d.settings.MaxTextSize+1))
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+
+		if int64(len(content)) > d.settings.MaxTextSize {
+			return http.StatusRequestEntityTooLarge, nil
+		}
+		body = bytes.NewReader(content)
+
+		// The hooks aren't run for the saves bound to fail.
+		err = checkPreconditions(w, r, d)
+		if err != nil {
+			return errToStatus(err), err
+		}
+	}
+
 	err := d.RunHook(func() error {
+		if conditional {
+			conditionalWrites.Lock()
+			defer conditionalWrites.Unlock()
+
+			err := checkPreconditions(w, r, d) //nolint:shadow
+			if err != nil {
+				return err
+			}
+		}
+
 		dir, _ := filepath.Split(r.URL.Path)
 		err := d.user.Fs.MkdirAll(dir, 0775)
 		if err != nil {
@@ -111,7 +238,7 @@ var resourcePostPutHandler = withUser(func(w http.ResponseWriter, r *http.Reques
 		}
 		defer file.Close()
 
-		_, err = io.Copy(file, r.Body)
+		_, err = io.Copy(file, body)
 		if err != nil {
 			return err
 		}
@@ -122,14 +249,78 @@ var resourcePostPutHandler = withUser(func(w http.ResponseWriter, r *http.Reques
 			return err
 		}
 
-		etag := fmt.Sprintf(`"%x%x"`, info.ModTime().UnixNano(), info.Size())
-		w.Header().Set("ETag", etag)
+		w.Header().Set("ETag", fileETag(info.ModTime(), info.Size()))
 		return nil
 	}, action, r.URL.Path, "", d.user)
 
 	return errToStatus(err), err
 })
 
+// conditionalWrites serializes the checks of the preconditions of the
+// saves with the writes of the files.
+var conditionalWrites sync.Mutex
+
+// fileETag is the entity tag of a file, from its modification time and
+// its size.
+func fileETag(modTime time.Time, size int64) string {
+	return fmt.Sprintf(`"%x%x"`, modTime.UnixNano(), size)
+}
+
+// checkPreconditions checks the preconditions of a save against the file
+// it modifies. If they aren't met, it sends the current entity tag of the
+// file and returns errors.ErrPreconditionFailed.
+func checkPreconditions(w http.ResponseWriter, r *http.Request, d *data) error {
+	info, err := d.user.Fs.Stat(r.URL.Path)
+	if err != nil {
+		info = nil
+	}
+
+	if preconditionsMet(r, info) {
+		return nil
+	}
+
+	if info != nil {
+		w.Header().Set("ETag", fileETag(info.ModTime(), info.Size()))
+	}
+
+	return errors.ErrPreconditionFailed
+}
+
+// preconditionsMet checks the If-Match and If-Unmodified-Since headers of
+// a request against the file it modifies, whose info is nil if it does
+// not exist. If-Unmodified-Since is ignored when If-Match is given.
+func preconditionsMet(r *http.Request, info os.FileInfo) bool {
+	if match := r.Header.Get("If-Match"); match != "" {
+		if info == nil {
+			return false
+		}
+
+		if strings.TrimSpace(match) == "*" {
+			return true
+		}
+
+		etag := fileETag(info.ModTime(), info.Size())
+		for _, tag := range strings.Split(match, ",") {
+			if strings.TrimSpace(tag) == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if since := r.Header.Get("If-Unmodified-Since"); since != "" {
+		t, err := http.ParseTime(since)
+		if err != nil {
+			return true
+		}
+
+		return info != nil && !info.ModTime().Truncate(time.Second).After(t)
+	}
+
+	return true
+}
+
 var resourcePatchHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
 	src := r.URL.Path
 	dst := r.URL.Query().Get("destination")
@@ -140,20 +331,41 @@ var resourcePatchHandler = withUser(func(w http.ResponseWriter, r *http.Request,
 		return errToStatus(err), err
 	}
 
+	d.entry.Source = src
+	d.entry.Destination = dst
+	if action == "copy" {
+		d.entry.Action = audit.ActionFileCopy
+	}
+
 	if dst == "/" || src == "/" {
 		return http.StatusForbidden, nil
 	}
 
+	if action == "rename" {
+		if status, err := checkLock(w, d, src); status != 0 || err != nil { //nolint:shadow
+			return status, err
+		}
+	}
+
+	if status, err := checkLock(w, d, dst); status != 0 || err != nil { //nolint:shadow
+		return status, err
+	}
+
 	err = d.RunHook(func() error {
 		switch action {
 		// TODO: use enum
 		case "copy":
-			if !d.user.Perm.Create {
+			// The copy could be downloaded instead of the source.
+			if !d.Check(src) || !d.can(d.user.Perm.Download, src, rules.ActionDownload) ||
+				!d.can(d.user.Perm.Create, dst, rules.ActionCreate) {
 				return errors.ErrPermissionDenied
 			}
 			return fileutils.Copy(d.user.Fs, src, dst)
 		case "rename":
-			if !d.user.Perm.Rename {
+			// Moving a file removes it from its folder.
+			if !d.Check(src) || !d.can(d.user.Perm.Rename, src, rules.ActionRename) ||
+				!d.can(d.user.Perm.Delete, src, rules.ActionDelete) ||
+				!d.can(d.user.Perm.Rename, dst, rules.ActionRename) {
 				return errors.ErrPermissionDenied
 			}
 			return d.user.Fs.Rename(src, dst)
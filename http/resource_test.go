@@ -0,0 +1,118 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/filebrowser/filebrowser/v2/audit"
+	"github.com/filebrowser/filebrowser/v2/rules"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+var patch = withAudit(audit.ActionFileRename, resourcePatchHandler)
+
+func TestResourcePatchCopy(t *testing.T) {
+	perm := users.Permissions{Create: true, Rename: true, Delete: true, Download: true}
+	e := newTestEnv(t, perm,
+		rules.Rule{Path: "/private", Actions: []rules.Action{rules.ActionDownload}},
+		rules.Rule{Path: "/hidden"},
+	)
+	e.writeFile(t, "/private/a.txt", "a")
+	e.writeFile(t, "/hidden/a.txt", "a")
+	e.writeFile(t, "/public/a.txt", "a")
+
+	tests := []struct {
+		src, dst string
+		status   int
+	}{
+		{"/private/a.txt", "/public/b.txt", http.StatusForbidden},
+		{"/hidden/a.txt", "/public/c.txt", http.StatusForbidden},
+		{"/public/a.txt", "/public/d.txt", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		w := e.do(patch, "PATCH", tt.src+"?action=copy&destination="+tt.dst, nil)
+		checkStatus(t, w, tt.status)
+
+		if copied := e.exists(t, tt.dst); copied != (tt.status == http.StatusOK) {
+			t.Errorf("copy of %s to %s: copied is %v", tt.src, tt.dst, copied)
+		}
+	}
+}
+
+func TestResourcePatchRename(t *testing.T) {
+	perm := users.Permissions{Create: true, Rename: true, Delete: true, Download: true}
+	e := newTestEnv(t, perm,
+		rules.Rule{Path: "/kept", Actions: []rules.Action{rules.ActionDelete}},
+	)
+	e.writeFile(t, "/kept/a.txt", "a")
+	e.writeFile(t, "/public/a.txt", "a")
+
+	w := e.do(patch, "PATCH", "/kept/a.txt?action=rename&destination=/public/b.txt", nil)
+	checkStatus(t, w, http.StatusForbidden)
+	if !e.exists(t, "/kept/a.txt") || e.exists(t, "/public/b.txt") {
+		t.Error("the file was moved out of a folder where it can't be deleted")
+	}
+
+	w = e.do(patch, "PATCH", "/public/a.txt?action=rename&destination=/public/b.txt", nil)
+	checkStatus(t, w, http.StatusOK)
+	if e.exists(t, "/public/a.txt") || !e.exists(t, "/public/b.txt") {
+		t.Error("the file wasn't renamed")
+	}
+
+	// Without the permission to delete, nothing can be moved.
+	e.user.Perm.Delete = false
+	err := e.store.Users.Update(e.user, "Perm")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w = e.do(patch, "PATCH", "/public/b.txt?action=rename&destination=/public/c.txt", nil)
+	checkStatus(t, w, http.StatusForbidden)
+}
+
+func TestResourcePutConditional(t *testing.T) {
+	e := newTestEnv(t, users.Permissions{Modify: true})
+	e.writeFile(t, "/a.txt", "a")
+
+	marker := filepath.Join(e.server.Root, "hook-ran")
+	set, err := e.store.Settings.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set.MaxTextSize = 16
+	set.Commands["before_save"] = []string{"touch " + marker}
+	if err = e.store.Settings.Save(set); err != nil {
+		t.Fatal(err)
+	}
+
+	put := func(etag, body string) *httptest.ResponseRecorder {
+		r := e.request("PUT", "/a.txt", strings.NewReader(body))
+		r.Header.Set("If-Match", etag)
+		return e.serve(withAudit(audit.ActionFileModify, resourcePostPutHandler), r)
+	}
+
+	// The saves bound to fail don't run the hooks.
+	w := put(`"stale"`, "b")
+	checkStatus(t, w, http.StatusPreconditionFailed)
+	etag := w.Header().Get("ETag")
+
+	w = put(etag, strings.Repeat("b", 17))
+	checkStatus(t, w, http.StatusRequestEntityTooLarge)
+
+	if _, err = os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("the hooks ran: %v", err)
+	}
+
+	w = put(etag, "b")
+	checkStatus(t, w, http.StatusOK)
+
+	if _, err = os.Stat(marker); err != nil {
+		t.Errorf("the hooks didn't run: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gorilla/mux"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/session"
+)
+
+type sessionInfo struct {
+	*session.Session
+	Current bool `json:"current"`
+}
+
+func renderSessions(w http.ResponseWriter, r *http.Request, d *data, userID uint) (int, error) {
+	list, err := d.store.Sessions.FindByUserID(userID)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastSeen > list[j].LastSeen
+	})
+
+	infos := make([]*sessionInfo, len(list))
+	for i, sess := range list {
+		infos[i] = &sessionInfo{
+			Session: sess,
+			Current: d.session != nil && d.session.ID == sess.ID,
+		}
+	}
+
+	return renderJSON(w, r, infos)
+}
+
+var sessionsGetHandler = withSession(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	return renderSessions(w, r, d, d.user.ID)
+})
+
+// sessionsDeleteHandler logs the user out everywhere.
+var sessionsDeleteHandler = withSession(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	err := d.store.Sessions.DeleteByUserID(d.user.ID)
+	return errToStatus(err), err
+})
+
+var sessionDeleteHandler = withSession(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	sess, err := d.store.Sessions.Get(mux.Vars(r)["id"])
+	if err == errors.ErrNotExist {
+		return http.StatusNotFound, nil
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if sess.UserID != d.user.ID && !d.user.Perm.Admin {
+		return http.StatusNotFound, nil
+	}
+
+	err = d.store.Sessions.Delete(sess.ID)
+	return errToStatus(err), err
+})
+
+var userSessionsGetHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getUserID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	return renderSessions(w, r, d, id)
+})
+
+// userSessionsDeleteHandler forces a user to log out everywhere.
+var userSessionsDeleteHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getUserID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	err = d.store.Sessions.DeleteByUserID(id)
+	return errToStatus(err), err
+})
@@ -9,13 +9,20 @@ import (
 )
 
 type settingsData struct {
-	Signup        bool                  `json:"signup"`
-	CreateUserDir bool                  `json:"createUserDir"`
-	Defaults      settings.UserDefaults `json:"defaults"`
-	Rules         []rules.Rule          `json:"rules"`
-	Branding      settings.Branding     `json:"branding"`
-	Shell         []string              `json:"shell"`
-	Commands      map[string][]string   `json:"commands"`
+	Signup        bool                    `json:"signup"`
+	CreateUserDir bool                    `json:"createUserDir"`
+	Defaults      settings.UserDefaults   `json:"defaults"`
+	Rules         []rules.Rule            `json:"rules"`
+	Branding      settings.Branding       `json:"branding"`
+	Shell         []string                `json:"shell"`
+	Commands      map[string][]string     `json:"commands"`
+	RequireTOTP   bool                    `json:"requireTOTP"`
+	Password      settings.PasswordPolicy `json:"password"`
+	HookTimeouts  settings.HookTimeouts   `json:"hookTimeouts"`
+	History       settings.CommandHistory `json:"history"`
+	MaxTextSize   int64                   `json:"maxTextSize"`
+
+	ForceHideDotfiles bool `json:"forceHideDotfiles"`
 }
 
 var settingsGetHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
@@ -27,6 +34,13 @@ var settingsGetHandler = withAdmin(func(w http.ResponseWriter, r *http.Request,
 		Branding:      d.settings.Branding,
 		Shell:         d.settings.Shell,
 		Commands:      d.settings.Commands,
+		RequireTOTP:   d.settings.RequireTOTP,
+		Password:      d.settings.Password,
+		HookTimeouts:  d.settings.HookTimeouts,
+		History:       d.settings.History,
+		MaxTextSize:   d.settings.MaxTextSize,
+
+		ForceHideDotfiles: d.settings.ForceHideDotfiles,
 	}
 
 	return renderJSON(w, r, data)
@@ -46,6 +60,12 @@ var settingsPutHandler = withAdmin(func(w http.ResponseWriter, r *http.Request,
 	d.settings.Branding = req.Branding
 	d.settings.Shell = req.Shell
 	d.settings.Commands = req.Commands
+	d.settings.RequireTOTP = req.RequireTOTP
+	d.settings.Password = req.Password
+	d.settings.HookTimeouts = req.HookTimeouts
+	d.settings.History = req.History
+	d.settings.MaxTextSize = req.MaxTextSize
+	d.settings.ForceHideDotfiles = req.ForceHideDotfiles
 
 	err = d.store.Settings.Save(d.settings)
 	return errToStatus(err), err
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/rules"
 	"github.com/filebrowser/filebrowser/v2/share"
 )
 
@@ -44,11 +45,27 @@ var shareDeleteHandler = withPermShare(func(w http.ResponseWriter, r *http.Reque
 		return http.StatusBadRequest, nil
 	}
 
-	err := d.store.Share.Delete(hash)
+	d.entry.Details = hash
+	link, err := d.store.Share.GetByHash(hash)
+	if err != nil {
+		// Deleting a link that doesn't exist isn't an error.
+		err = d.store.Share.Delete(hash)
+		return errToStatus(err), err
+	}
+
+	d.entry.Source = link.Path
+	err = d.RunHookWith(func() error {
+		return d.store.Share.Delete(hash)
+	}, "unshare", link.Path, "", d.user, shareVars(link, ""))
 	return errToStatus(err), err
 })
 
 var sharePostHandler = withPermShare(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	d.entry.Source = r.URL.Path
+	if !d.CheckAction(r.URL.Path, rules.ActionShare) {
+		return http.StatusForbidden, nil
+	}
+
 	var s *share.Link
 	rawExpire := r.URL.Query().Get("expires")
 	unit := r.URL.Query().Get("unit")
@@ -102,9 +119,14 @@ var sharePostHandler = withPermShare(func(w http.ResponseWriter, r *http.Request
 		UserID: d.user.ID,
 	}
 
-	if err := d.store.Share.Save(s); err != nil {
-		return http.StatusInternalServerError, err
+	err = d.RunHookWith(func() error {
+		return d.store.Share.Save(s)
+	}, "share", r.URL.Path, "", d.user, shareVars(s, ""))
+	if err != nil {
+		return errToStatus(err), err
 	}
 
+	d.entry.Details = s.Hash
+
 	return renderJSON(w, r, s)
 })
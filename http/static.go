@@ -16,6 +16,7 @@ import (
 	"github.com/filebrowser/filebrowser/v2/settings"
 	"github.com/filebrowser/filebrowser/v2/storage"
 	"github.com/filebrowser/filebrowser/v2/version"
+	"github.com/filebrowser/filebrowser/v2/webhooks"
 )
 
 func handleWithStaticData(w http.ResponseWriter, _ *http.Request, d *data, box *rice.Box, file, contentType string) (int, error) {
@@ -26,6 +27,8 @@ func handleWithStaticData(w http.ResponseWriter, _ *http.Request, d *data, box *
 		return http.StatusInternalServerError, err
 	}
 
+	_, loginRedirect := auther.(auth.Redirecter)
+
 	data := map[string]interface{}{
 		"Name":            d.settings.Branding.Name,
 		"DisableExternal": d.settings.Branding.DisableExternal,
@@ -36,6 +39,7 @@ func handleWithStaticData(w http.ResponseWriter, _ *http.Request, d *data, box *
 		"NoAuth":          d.settings.AuthMethod == auth.MethodNoAuth,
 		"AuthMethod":      d.settings.AuthMethod,
 		"LoginPage":       auther.LoginPage(),
+		"LoginRedirect":   loginRedirect,
 		"CSS":             false,
 		"ReCaptcha":       false,
 		"Theme":           d.settings.Branding.Theme,
@@ -85,7 +89,8 @@ func handleWithStaticData(w http.ResponseWriter, _ *http.Request, d *data, box *
 	return 0, nil
 }
 
-func getStaticHandlers(store *storage.Storage, server *settings.Server) (index, static http.Handler) {
+func getStaticHandlers(store *storage.Storage, server *settings.Server,
+	dispatcher *webhooks.Dispatcher) (index, static http.Handler) {
 	box := rice.MustFindBox("../frontend/dist")
 	handler := http.FileServer(box.HTTPBox())
 
@@ -96,7 +101,7 @@ func getStaticHandlers(store *storage.Storage, server *settings.Server) (index,
 
 		w.Header().Set("x-xss-protection", "1; mode=block")
 		return handleWithStaticData(w, r, d, box, "index.html", "text/html; charset=utf-8")
-	}, "", store, server)
+	}, "", store, server, dispatcher)
 
 	static = handle(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
 		if r.Method != http.MethodGet {
@@ -122,7 +127,7 @@ func getStaticHandlers(store *storage.Storage, server *settings.Server) (index,
 		}
 
 		return handleWithStaticData(w, r, d, box, r.URL.Path, "application/javascript; charset=utf-8")
-	}, "/static/", store, server)
+	}, "/static/", store, server, dispatcher)
 
 	return index, static
 }
@@ -0,0 +1,347 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spf13/afero"
+
+	"github.com/filebrowser/filebrowser/v2/audit"
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/files"
+	"github.com/filebrowser/filebrowser/v2/rules"
+)
+
+const (
+	// tailDefaultLines and tailMaxLines are the numbers of lines sent
+	// when a file starts to be followed.
+	tailDefaultLines = 100
+	tailMaxLines     = 10000
+	// tailPollInterval is how often the followed files are checked.
+	tailPollInterval = 500 * time.Millisecond
+	// tailPingInterval is how often the idle connections are pinged, and
+	// how often the user is checked to still be allowed to follow a file.
+	tailPingInterval = 30 * time.Second
+	// tailMaxMessage is the size above which the lines appended at once
+	// are sent in several messages.
+	tailMaxMessage = 1024 * 1024
+	// tailMaxLine is the size above which the lines of the followed
+	// files are split.
+	tailMaxLine = 64 * 1024
+)
+
+// tailMessage is a message of the stream of a followed file. The "lines"
+// messages hold the lines appended to the file, without their endings.
+// The "truncated" and "rotated" messages tell that the file was truncated
+// or replaced by a new one, which is then followed from its start.
+type tailMessage struct {
+	Type  string   `json:"type"`
+	Lines []string `json:"lines,omitempty"`
+}
+
+// tailer follows a file, from an offset, sending its new lines.
+type tailer struct {
+	conn    *websocket.Conn
+	fs      afero.Fs
+	path    string
+	file    afero.File
+	offset  int64
+	pending []byte
+}
+
+// tailHandler streams over a websocket the lines appended to a text file,
+// such as a log, starting with its last lines, given by the lines query
+// parameter. The file is polled, to work on any file system, and followed
+// through truncations and rotations until the client goes away, or until
+// the user isn't allowed to follow it anymore.
+var tailHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	d.entry.Source = r.URL.Path
+	if !canTail(d, r.URL.Path) {
+		d.entry.Result = audit.ResultDenied
+		return http.StatusForbidden, nil
+	}
+
+	lines := int64(tailDefaultLines)
+	if raw := r.URL.Query().Get("lines"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v < 0 {
+			return http.StatusBadRequest, errors.ErrInvalidRequestParams
+		}
+
+		lines = v
+		if lines > tailMaxLines {
+			lines = tailMaxLines
+		}
+	}
+
+	info, err := files.NewFileInfo(files.FileOptions{
+		Fs:          d.user.Fs,
+		Path:        r.URL.Path,
+		Expand:      true,
+		MaxTextSize: d.settings.MaxTextSize,
+		Checker:     d,
+	})
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	if info.IsDir {
+		return http.StatusBadRequest, errors.ErrIsDirectory
+	}
+
+	if info.Type != "text" && info.Type != "textImmutable" {
+		return invalid(w, errors.ErrNotText)
+	}
+
+	t := &tailer{fs: d.user.Fs, path: r.URL.Path}
+	t.file, err = d.user.Fs.Open(r.URL.Path)
+	if err != nil {
+		return errToStatus(err), err
+	}
+	defer func() { t.file.Close() }()
+
+	var initial []string
+	if lines > 0 {
+		err = info.ReadContent(files.ContentOptions{Tail: lines, MaxSize: d.settings.MaxTextSize})
+		if err != nil {
+			return errToStatus(err), err
+		}
+
+		// A last line not ended yet is sent once it is.
+		content := []byte(info.Content)
+		end := bytes.LastIndexByte(content, '\n') + 1
+		initial = splitLines(content[:end])
+		t.offset = info.Range.Offset + int64(end)
+	} else {
+		stat, err := t.file.Stat() //nolint:shadow
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		t.offset = stat.Size()
+	}
+
+	_, err = t.file.Seek(t.offset, io.SeekStart)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	t.conn = conn
+
+	// The client only closes the connection: reading is needed to be
+	// told about it.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil { //nolint:shadow
+				return
+			}
+		}
+	}()
+
+	if len(initial) > 0 {
+		if err := t.send(&tailMessage{Type: "lines", Lines: initial}); err != nil { //nolint:shadow
+			return 0, nil
+		}
+	}
+
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+	lastWrite := time.Now()
+	lastCheck := lastWrite
+
+	for {
+		select {
+		case <-done:
+			return 0, nil
+		case <-ticker.C:
+		}
+
+		// The session may have been revoked, or the permissions changed,
+		// since the stream started.
+		if time.Since(lastCheck) > tailPingInterval {
+			lastCheck = time.Now()
+			ok, err := revalidate(d) //nolint:shadow
+			if err != nil {
+				wsErr(conn, r, http.StatusInternalServerError, err)
+				return 0, nil
+			}
+
+			if !ok || !canTail(d, r.URL.Path) {
+				wsErr(conn, r, http.StatusForbidden, nil)
+				return 0, nil
+			}
+		}
+
+		sent, err := t.poll()
+		if err != nil {
+			wsErr(conn, r, http.StatusInternalServerError, err)
+			return 0, nil
+		}
+
+		if sent {
+			lastWrite = time.Now()
+		} else if time.Since(lastWrite) > tailPingInterval {
+			lastWrite = time.Now()
+			if err := conn.WriteControl(websocket.PingMessage, nil, lastWrite.Add(WSWriteDeadline)); err != nil { //nolint:shadow
+				return 0, nil
+			}
+		}
+	}
+})
+
+// canTail checks if the user can follow a file, which is downloading it.
+func canTail(d *data, path string) bool {
+	return d.user.Perm.Download && d.Check(path) && d.CheckAction(path, rules.ActionDownload)
+}
+
+// poll sends the lines appended to the file since the last poll, then
+// checks if it was truncated or rotated. It tells if it sent anything.
+func (t *tailer) poll() (bool, error) {
+	sent, err := t.readAppended()
+	if err != nil {
+		return sent, err
+	}
+
+	// The file may be missing for a while during a rotation.
+	info, err := t.fs.Stat(t.path)
+	if err != nil {
+		return sent, nil
+	}
+
+	current, err := t.file.Stat()
+	if err != nil {
+		return sent, err
+	}
+
+	switch {
+	case !sameFile(info, current):
+		file, err := t.fs.Open(t.path) //nolint:shadow
+		if err != nil {
+			return sent, nil
+		}
+
+		t.flush()
+		t.file.Close()
+		t.file = file
+		t.offset = 0
+		return true, t.send(&tailMessage{Type: "rotated"})
+	case info.Size() < t.offset:
+		_, err = t.file.Seek(0, io.SeekStart)
+		if err != nil {
+			return sent, err
+		}
+
+		t.pending = nil
+		t.offset = 0
+		return true, t.send(&tailMessage{Type: "truncated"})
+	}
+
+	return sent, nil
+}
+
+// readAppended reads the file up to its end and sends the lines that
+// are ended. The lines too long are sent in parts.
+func (t *tailer) readAppended() (bool, error) {
+	buf := make([]byte, 32*1024) //nolint:mnd
+	var (
+		lines []string
+		size  int
+		sent  bool
+	)
+
+	for {
+		n, err := t.file.Read(buf)
+		t.offset += int64(n)
+		t.pending = append(t.pending, buf[:n]...)
+
+		if end := bytes.LastIndexByte(t.pending, '\n') + 1; end > 0 {
+			lines = append(lines, splitLines(t.pending[:end])...)
+			size += end
+			t.pending = append([]byte(nil), t.pending[end:]...)
+		}
+
+		if len(t.pending) >= tailMaxLine {
+			lines = append(lines, string(t.pending))
+			size += len(t.pending)
+			t.pending = nil
+		}
+
+		if size >= tailMaxMessage {
+			if err := t.send(&tailMessage{Type: "lines", Lines: lines}); err != nil { //nolint:shadow
+				return sent, err
+			}
+			lines, size, sent = nil, 0, true
+		}
+
+		if err == io.EOF || n == 0 {
+			break
+		} else if err != nil {
+			return sent, err
+		}
+	}
+
+	if len(lines) == 0 {
+		return sent, nil
+	}
+
+	return true, t.send(&tailMessage{Type: "lines", Lines: lines})
+}
+
+// flush sends the last line of a file that is no longer followed, even
+// if it isn't ended.
+func (t *tailer) flush() {
+	if len(t.pending) > 0 {
+		_ = t.send(&tailMessage{Type: "lines", Lines: []string{string(t.pending)}})
+		t.pending = nil
+	}
+}
+
+func (t *tailer) send(msg *tailMessage) error {
+	err := t.conn.SetWriteDeadline(time.Now().Add(WSWriteDeadline))
+	if err != nil {
+		return err
+	}
+
+	err = t.conn.WriteJSON(msg)
+	if err != nil {
+		log.Print(err)
+	}
+	return err
+}
+
+// splitLines splits ended lines, removing their endings.
+func splitLines(b []byte) []string {
+	if len(b) == 0 {
+		return nil
+	}
+
+	parts := bytes.Split(bytes.TrimSuffix(b, []byte{'\n'}), []byte{'\n'})
+	lines := make([]string, len(parts))
+	for i, p := range parts {
+		lines[i] = string(bytes.TrimSuffix(p, []byte{'\r'}))
+	}
+
+	return lines
+}
+
+// sameFile checks if two infos describe the same file. The files whose
+// file system can't tell are considered to be the same.
+func sameFile(a, b os.FileInfo) bool {
+	if a.Sys() == nil || b.Sys() == nil {
+		return true
+	}
+
+	return os.SameFile(a, b)
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// authenticated gets the data of a request authenticated by the token
+// of the test user.
+func (e *testEnv) authenticated(t *testing.T) *data {
+	var d *data
+	w := e.do(withUser(func(w http.ResponseWriter, r *http.Request, got *data) (int, error) {
+		d = got
+		return http.StatusOK, nil
+	}), "GET", "/", nil)
+	checkStatus(t, w, http.StatusOK)
+
+	return d
+}
+
+func TestRevalidateToken(t *testing.T) {
+	e := newTestEnv(t, users.Permissions{Download: true})
+	e.writeFile(t, "/a.log", "a\n")
+	d := e.authenticated(t)
+
+	if ok, err := revalidate(d); !ok || err != nil || !canTail(d, "/a.log") {
+		t.Fatalf("got %v and error %v", ok, err)
+	}
+
+	// The permissions changed meanwhile.
+	e.user.Perm.Download = false
+	if err := e.store.Users.Update(e.user, "Perm"); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := revalidate(d); !ok || err != nil || canTail(d, "/a.log") {
+		t.Errorf("without download permission: got %v and error %v", ok, err)
+	}
+
+	// The token was revoked meanwhile.
+	if err := e.store.Tokens.Delete(d.token.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := revalidate(d); ok || err != nil {
+		t.Errorf("with a revoked token: got %v and error %v", ok, err)
+	}
+}
+
+func TestRevalidateSession(t *testing.T) {
+	e := newTestEnv(t, users.Permissions{Download: true})
+	d := e.authenticated(t)
+
+	sess, err := e.store.Sessions.Create(e.user.ID, "192.0.2.1", "tests", time.Now().Add(time.Hour).Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.token, d.session = nil, sess
+
+	if ok, err := revalidate(d); !ok || err != nil { //nolint:shadow
+		t.Fatalf("got %v and error %v", ok, err)
+	}
+
+	if err = e.store.Sessions.DeleteByUserID(e.user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := revalidate(d); ok || err != nil { //nolint:shadow
+		t.Errorf("with a revoked session: got %v and error %v", ok, err)
+	}
+}
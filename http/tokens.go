@@ -0,0 +1,94 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/tokens"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+type tokenPostRequest struct {
+	Name   string             `json:"name"`
+	Expire int64              `json:"expire"`
+	Perm   *users.Permissions `json:"perm"`
+}
+
+type tokenPostResponse struct {
+	*tokens.Token
+	Raw string `json:"token"`
+}
+
+var tokensGetHandler = withSession(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	list, err := d.store.Tokens.FindByUserID(d.user.ID)
+	if err == errors.ErrNotExist {
+		return renderJSON(w, r, []*tokens.Token{})
+	}
+
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	for _, t := range list {
+		t.Hash = ""
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	return renderJSON(w, r, list)
+})
+
+var tokenPostHandler = withSession(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	if r.Body == nil {
+		return http.StatusBadRequest, errors.ErrEmptyRequest
+	}
+
+	req := &tokenPostRequest{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if req.Name == "" || (req.Expire != 0 && req.Expire <= time.Now().Unix()) {
+		return http.StatusBadRequest, nil
+	}
+
+	d.entry.Source = req.Name
+	raw, t, err := d.store.Tokens.Create(d.user.ID, req.Name, req.Expire, req.Perm)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	t.Hash = ""
+	return renderJSON(w, r, &tokenPostResponse{Token: t, Raw: raw})
+})
+
+var tokenDeleteHandler = withSession(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 0)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	t, err := d.store.Tokens.Get(uint(id))
+	if err == errors.ErrNotExist {
+		return http.StatusNotFound, nil
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if t.UserID != d.user.ID && !d.user.Perm.Admin {
+		return http.StatusNotFound, nil
+	}
+
+	d.entry.Source = t.Name
+	err = d.store.Tokens.Delete(t.ID)
+	return errToStatus(err), err
+})
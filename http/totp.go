@@ -0,0 +1,132 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"rsc.io/qr"
+
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+const recoveryCodesCount = 10
+
+type totpRequest struct {
+	Code string `json:"code"`
+}
+
+type totpSetupResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QR     string `json:"qr"`
+}
+
+type totpEnableResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+func getTOTPRequest(r *http.Request) (*totpRequest, error) {
+	req := &totpRequest{}
+	if r.Body == nil {
+		return req, nil
+	}
+
+	err := json.NewDecoder(r.Body).Decode(req)
+	return req, err
+}
+
+// totpSetupHandler generates a new secret for the user, which is only
+// used once it has been confirmed with totpEnableHandler.
+var totpSetupHandler = withPendingUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	if d.user.TOTPEnabled {
+		return http.StatusConflict, nil
+	}
+
+	secret, err := users.GenerateTOTPSecret()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	issuer := d.settings.Branding.Name
+	if issuer == "" {
+		issuer = "File Browser"
+	}
+
+	uri := users.TOTPURI(issuer, d.user.Username, secret)
+	code, err := qr.Encode(uri, qr.M)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	d.user.TOTPSecret = secret
+	err = d.store.Users.Update(d.user, "TOTPSecret")
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return renderJSON(w, r, &totpSetupResponse{
+		Secret: secret,
+		URI:    uri,
+		QR:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(code.PNG()),
+	})
+})
+
+var totpEnableHandler = withPendingUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	if d.user.TOTPEnabled {
+		return http.StatusConflict, nil
+	}
+
+	if d.user.TOTPSecret == "" {
+		return http.StatusBadRequest, nil
+	}
+
+	req, err := getTOTPRequest(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	step, ok := users.CheckTOTP(d.user.TOTPSecret, req.Code, time.Now(), 0)
+	if !ok {
+		return http.StatusForbidden, nil
+	}
+
+	codes, hashes, err := users.GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	d.user.TOTPEnabled = true
+	d.user.RecoveryCodes = hashes
+	d.user.TOTPLastStep = step
+	err = d.store.Users.Update(d.user, "TOTPEnabled", "RecoveryCodes", "TOTPLastStep")
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return renderJSON(w, r, &totpEnableResponse{RecoveryCodes: codes})
+})
+
+var totpDeleteHandler = withUser(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	if !d.user.TOTPEnabled {
+		return http.StatusBadRequest, nil
+	}
+
+	if d.settings.RequireTOTP {
+		return http.StatusForbidden, nil
+	}
+
+	req, err := getTOTPRequest(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if ok, _ := d.user.CheckSecondFactor(req.Code); !ok {
+		return http.StatusForbidden, nil
+	}
+
+	d.user.ResetTOTP()
+	err = d.store.Users.Update(d.user, "TOTPEnabled", "TOTPSecret", "RecoveryCodes", "TOTPLastStep")
+	return errToStatus(err), err
+})
@@ -19,6 +19,56 @@ type modifyUserRequest struct {
 	Data *users.User `json:"data"`
 }
 
+// userFields maps the names of the fields of a user, as given in the
+// update requests, to the ones of users.User. The names are case
+// insensitive.
+var userFields = map[string]string{
+	"username":           "Username",
+	"password":           "Password",
+	"scope":              "Scope",
+	"locale":             "Locale",
+	"lockpassword":       "LockPassword",
+	"viewmode":           "ViewMode",
+	"perm":               "Perm",
+	"commands":           "Commands",
+	"sorting":            "Sorting",
+	"hidedotfiles":       "HideDotfiles",
+	"rules":              "Rules",
+	"groups":             "Groups",
+	"mounts":             "Mounts",
+	"commandtimeout":     "CommandTimeout",
+	"sandbox":            "Sandbox",
+	"totpenabled":        "TOTPEnabled",
+	"totpsecret":         "TOTPSecret",
+	"recoverycodes":      "RecoveryCodes",
+	"totplaststep":       "TOTPLastStep",
+	"failedlogins":       "FailedLogins",
+	"lockeduntil":        "LockedUntil",
+	"mustchangepassword": "MustChangePassword",
+	"passwordchanged":    "PasswordChanged",
+	"oidcissuer":         "OIDCIssuer",
+	"oidcsubject":        "OIDCSubject",
+}
+
+// userSelfFields are the fields the users who aren't administrators
+// can change on themselves.
+var userSelfFields = map[string]bool{
+	"Password":     true,
+	"Locale":       true,
+	"ViewMode":     true,
+	"Sorting":      true,
+	"HideDotfiles": true,
+}
+
+// userManagedFields are the fields that can't be changed by an update,
+// as the two-factor authentication is managed apart.
+var userManagedFields = map[string]bool{
+	"TOTPEnabled":   true,
+	"TOTPSecret":    true,
+	"RecoveryCodes": true,
+	"TOTPLastStep":  true,
+}
+
 func getUserID(r *http.Request) (uint, error) {
 	vars := mux.Vars(r)
 	i, err := strconv.ParseUint(vars["id"], 10, 0)
@@ -69,7 +119,7 @@ var usersGetHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *
 	}
 
 	for _, u := range users {
-		u.Password = ""
+		u.HideSecrets()
 	}
 
 	sort.Slice(users, func(i, j int) bool {
@@ -89,19 +139,48 @@ var userGetHandler = withSelfOrAdmin(func(w http.ResponseWriter, r *http.Request
 		return http.StatusInternalServerError, err
 	}
 
-	u.Password = ""
+	u.HideSecrets()
 	return renderJSON(w, r, u)
 })
 
 var userDeleteHandler = withSelfOrAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
-	err := d.store.Users.Delete(d.raw.(uint))
-	if err == errors.ErrNotExist {
-		return http.StatusNotFound, err
+	target, err := d.store.Users.Get(d.server.Root, d.raw.(uint))
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	d.entry.Source = target.Username
+	err = d.RunHookWith(func() error {
+		return d.store.Users.Delete(target.ID)
+	}, "user_delete", "", "", d.user, userVars(target))
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	// The IDs of the deleted users can be reused.
+	err = d.store.Tokens.DeleteByUserID(d.raw.(uint))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	err = d.store.Sessions.DeleteByUserID(d.raw.(uint))
+	if err != nil {
+		return http.StatusInternalServerError, err
 	}
 
 	return http.StatusOK, nil
 })
 
+// userVars describes to the hooks the user an event is about.
+func userVars(user *users.User) map[string]string {
+	return map[string]string{
+		"TARGET_USER_ID":  strconv.FormatUint(uint64(user.ID), 10),
+		"TARGET_USERNAME": user.Username,
+		"TARGET_SCOPE":    user.Scope,
+		"TARGET_ADMIN":    strconv.FormatBool(user.Perm.Admin),
+	}
+}
+
 var userPostHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
 	req, err := getUser(w, r)
 	if err != nil {
@@ -112,13 +191,18 @@ var userPostHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *
 		return http.StatusBadRequest, nil
 	}
 
-	if req.Data.Password == "" {
-		return http.StatusBadRequest, errors.ErrEmptyPassword
+	d.entry.Source = req.Data.Username
+	status, err := setPassword(d, req.Data, req.Data.Password)
+	if status != 0 {
+		return status, err
 	}
 
-	req.Data.Password, err = users.HashPwd(req.Data.Password)
-	if err != nil {
-		return http.StatusInternalServerError, err
+	// Two-factor authentication can only be set up by the user.
+	req.Data.ResetTOTP()
+
+	status, err = checkGroups(d, req.Data.Groups)
+	if status != 0 {
+		return status, err
 	}
 
 	userHome, err := d.settings.MakeUserDir(req.Data.Username, req.Data.Scope, d.server.Root)
@@ -129,9 +213,16 @@ var userPostHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *
 	req.Data.Scope = userHome
 	log.Printf("user: %s, home dir: [%s].", req.Data.Username, userHome)
 
-	err = d.store.Users.Save(req.Data)
-	if err != nil {
-		return http.StatusInternalServerError, err
+	vars := userVars(req.Data)
+	err = d.RunHookWith(func() error {
+		err := d.store.Users.Save(req.Data) //nolint:shadow
+		vars["TARGET_USER_ID"] = strconv.FormatUint(uint64(req.Data.ID), 10)
+		return err
+	}, "user_create", "", "", d.user, vars)
+	if err == errors.ErrInvalidMount || err == errors.ErrInvalidSandbox {
+		return http.StatusBadRequest, err
+	} else if err != nil {
+		return errToStatus(err), err
 	}
 
 	w.Header().Set("Location", "/settings/users/"+strconv.FormatUint(uint64(req.Data.ID), 10))
@@ -148,49 +239,112 @@ var userPutHandler = withSelfOrAdmin(func(w http.ResponseWriter, r *http.Request
 		return http.StatusBadRequest, nil
 	}
 
+	auditUser(d, d.raw.(uint))
+	d.entry.Details = "fields: " + strings.Join(req.Which, ", ")
+
+	// The personal access tokens can't be used to change their own user,
+	// unless it is an administrator.
+	if d.token != nil && !d.user.Perm.Admin {
+		return http.StatusForbidden, nil
+	}
+
+	passwordChanged := false
 	if len(req.Which) == 1 && req.Which[0] == "all" {
 		if !d.user.Perm.Admin {
 			return http.StatusForbidden, err
 		}
 
+		var suser *users.User
+		suser, err = d.store.Users.Get(d.server.Root, d.raw.(uint))
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+
 		if req.Data.Password != "" {
-			req.Data.Password, err = users.HashPwd(req.Data.Password)
+			status, err := setPassword(d, req.Data, req.Data.Password) //nolint:shadow
+			if status != 0 {
+				return status, err
+			}
+			passwordChanged = true
 		} else {
-			var suser *users.User
-			suser, err = d.store.Users.Get(d.server.Root, d.raw.(uint))
 			req.Data.Password = suser.Password
+			req.Data.PasswordChanged = suser.PasswordChanged
 		}
 
-		if err != nil {
-			return http.StatusInternalServerError, err
+		// The two-factor authentication is managed apart.
+		req.Data.TOTPEnabled = suser.TOTPEnabled
+		req.Data.TOTPSecret = suser.TOTPSecret
+		req.Data.RecoveryCodes = suser.RecoveryCodes
+		req.Data.TOTPLastStep = suser.TOTPLastStep
+
+		status, err := checkGroups(d, req.Data.Groups) //nolint:shadow
+		if status != 0 {
+			return status, err
 		}
 
 		req.Which = []string{}
 	}
 
 	for k, v := range req.Which {
-		if v == "password" {
+		field, ok := userFields[strings.ToLower(v)]
+		if !ok {
+			return http.StatusBadRequest, nil
+		}
+
+		if userManagedFields[field] || (!d.user.Perm.Admin && !userSelfFields[field]) {
+			return http.StatusForbidden, nil
+		}
+
+		if field == "Password" {
 			if !d.user.Perm.Admin && d.user.LockPassword {
 				return http.StatusForbidden, nil
 			}
 
-			req.Data.Password, err = users.HashPwd(req.Data.Password)
+			// The policy may forbid passwords based on the username.
+			var suser *users.User
+			suser, err = d.store.Users.Get(d.server.Root, d.raw.(uint))
 			if err != nil {
 				return http.StatusInternalServerError, err
 			}
+
+			status, err := setPassword(d, suser, req.Data.Password) //nolint:shadow
+			if status != 0 {
+				return status, err
+			}
+
+			req.Data.Password = suser.Password
+			req.Data.PasswordChanged = suser.PasswordChanged
+			passwordChanged = true
 		}
 
-		if !d.user.Perm.Admin && (v == "scope" || v == "perm" || v == "username") {
-			return http.StatusForbidden, nil
+		if field == "Groups" {
+			status, err := checkGroups(d, req.Data.Groups) //nolint:shadow
+			if status != 0 {
+				return status, err
+			}
 		}
 
-		req.Which[k] = strings.Title(v)
+		req.Which[k] = field
+	}
+
+	if passwordChanged && len(req.Which) != 0 {
+		req.Which = append(req.Which, "PasswordChanged")
 	}
 
 	err = d.store.Users.Update(req.Data, req.Which...)
-	if err != nil {
+	if err == errors.ErrInvalidMount || err == errors.ErrInvalidSandbox {
+		return http.StatusBadRequest, err
+	} else if err != nil {
 		return http.StatusInternalServerError, err
 	}
 
+	// The other sessions may have been opened with the old password.
+	if passwordChanged {
+		err = revokeOtherSessions(d, req.Data.ID)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+	}
+
 	return http.StatusOK, nil
 })
@@ -11,7 +11,12 @@ import (
 	libErrors "github.com/filebrowser/filebrowser/v2/errors"
 )
 
-func renderJSON(w http.ResponseWriter, _ *http.Request, data interface{}) (int, error) {
+func renderJSON(w http.ResponseWriter, r *http.Request, data interface{}) (int, error) {
+	return renderJSONStatus(w, r, http.StatusOK, data)
+}
+
+// renderJSONStatus is like renderJSON, with another status than 200 OK.
+func renderJSONStatus(w http.ResponseWriter, _ *http.Request, status int, data interface{}) (int, error) {
 	marsh, err := json.Marshal(data)
 
 	if err != nil {
@@ -19,6 +24,7 @@ func renderJSON(w http.ResponseWriter, _ *http.Request, data interface{}) (int,
 	}
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
 	if _, err := w.Write(marsh); err != nil {
 		return http.StatusInternalServerError, err
 	}
@@ -40,6 +46,10 @@ func errToStatus(err error) int {
 		return http.StatusForbidden
 	case errors.Is(err, libErrors.ErrInvalidRequestParams):
 		return http.StatusBadRequest
+	case err == libErrors.ErrPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case err == libErrors.ErrLocked:
+		return http.StatusLocked
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/webhooks"
+)
+
+func getWebhookID(r *http.Request) (uint, error) {
+	i, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 0)
+	if err != nil {
+		return 0, err
+	}
+	return uint(i), err
+}
+
+func getWebhook(r *http.Request) (*webhooks.Webhook, error) {
+	if r.Body == nil {
+		return nil, errors.ErrEmptyRequest
+	}
+
+	w := &webhooks.Webhook{}
+	err := json.NewDecoder(r.Body).Decode(w)
+	return w, err
+}
+
+var webhooksGetHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	hooks, err := d.store.Webhooks.Gets()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return renderJSON(w, r, hooks)
+})
+
+var webhookGetHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getWebhookID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	hook, err := d.store.Webhooks.Get(id)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	return renderJSON(w, r, hook)
+})
+
+var webhookPostHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	hook, err := getWebhook(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	hook.ID = 0
+	hook.Created = 0
+	d.entry.Source = hook.URL
+	err = d.store.Webhooks.Save(hook)
+	if err == errors.ErrInvalidWebhook {
+		return http.StatusBadRequest, err
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	w.Header().Set("Location", "/api/webhooks/"+strconv.FormatUint(uint64(hook.ID), 10))
+	return renderJSONStatus(w, r, http.StatusCreated, hook)
+})
+
+var webhookPutHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getWebhookID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	hook, err := getWebhook(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	d.entry.Source = hook.URL
+	if hook.ID != id {
+		return http.StatusBadRequest, nil
+	}
+
+	old, err := d.store.Webhooks.Get(id)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	hook.Created = old.Created
+	err = d.store.Webhooks.Save(hook)
+	if err == errors.ErrInvalidWebhook {
+		return http.StatusBadRequest, err
+	} else if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+})
+
+var webhookDeleteHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getWebhookID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if hook, err := d.store.Webhooks.Get(id); err == nil { //nolint:shadow
+		d.entry.Source = hook.URL
+	}
+
+	err = d.store.Webhooks.Delete(id)
+	return errToStatus(err), err
+})
+
+var webhookDeliveriesHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getWebhookID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	limit := 100 //nolint:mnd
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return http.StatusBadRequest, err
+		}
+	}
+
+	deliveries, err := d.store.Webhooks.Deliveries(id, limit)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return renderJSON(w, r, deliveries)
+})
+
+// webhookPingHandler sends a ping event to a webhook, without retrying,
+// and returns its delivery.
+var webhookPingHandler = withAdmin(func(w http.ResponseWriter, r *http.Request, d *data) (int, error) {
+	id, err := getWebhookID(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	hook, err := d.store.Webhooks.Get(id)
+	if err != nil {
+		return errToStatus(err), err
+	}
+
+	delivery := d.Webhooks.Try(hook, &webhooks.Payload{
+		Event: webhooks.EventPing,
+		User:  webhooks.User{ID: d.user.ID, Username: d.user.Username},
+	})
+
+	return renderJSON(w, r, delivery)
+})
@@ -0,0 +1,65 @@
+package locks
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/filebrowser/filebrowser/v2/expiry"
+)
+
+// The lock timeouts, in seconds: the one of the locks taken without
+// timeout and the longest one allowed.
+const (
+	DefaultTimeout = 5 * 60
+	MaxTimeout     = 60 * 60
+)
+
+// Lock is an advisory lock on a file, which keeps the other users from
+// changing it until it is released or expires. Its owner refreshes it
+// while editing the file. It is an exclusive write lock identified by
+// an opaque token, as the ones of WebDAV.
+type Lock struct {
+	Token    string `json:"token,omitempty" storm:"id"`
+	Path     string `json:"path,omitempty" storm:"unique"`
+	UserID   uint   `json:"userID" storm:"index"`
+	Username string `json:"username"`
+	Created  int64  `json:"created"`
+	Timeout  int64  `json:"timeout"`
+	Expire   int64  `json:"expire" storm:"index"`
+}
+
+// Expired checks if the lock has already expired at the given
+// unix time.
+func (l *Lock) Expired(now int64) bool {
+	return expiry.Passed(l.Expire, now)
+}
+
+// Covers checks if the lock applies to a path, which is the one of
+// the locked file or of one of its parent directories. Both paths
+// are full paths.
+func (l *Lock) Covers(path string) bool {
+	path = filepath.Clean(path)
+	return l.Path == path || strings.HasPrefix(l.Path, strings.TrimSuffix(path, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// HideSecrets removes the full path of the locked file, which is only
+// shown to the administrators, and the token of the lock, which only its
+// owner, given by its ID, needs.
+func (l *Lock) HideSecrets(userID uint) {
+	l.Path = ""
+	if l.UserID != userID {
+		l.Token = ""
+	}
+}
+
+// cleanTimeout bounds a lock timeout, in seconds.
+func cleanTimeout(timeout int64) int64 {
+	switch {
+	case timeout <= 0:
+		return DefaultTimeout
+	case timeout > MaxTimeout:
+		return MaxTimeout
+	default:
+		return timeout
+	}
+}
@@ -0,0 +1,191 @@
+package locks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+const tokenSize = 16
+
+// StorageBackend is the interface to implement for a locks storage.
+type StorageBackend interface {
+	Get(token string) (*Lock, error)
+	GetByPath(path string) (*Lock, error)
+	Gets() ([]*Lock, error)
+	FindExpired(now int64) ([]*Lock, error)
+	Save(l *Lock) error
+	// Update updates some fields of a lock, if it still exists,
+	// atomically. It returns errors.ErrNotExist otherwise.
+	Update(l *Lock, fields ...string) error
+	Delete(token string) error
+}
+
+// Storage is a locks storage.
+type Storage struct {
+	back StorageBackend
+	mu   sync.Mutex
+}
+
+// NewStorage creates a locks storage from a backend.
+func NewStorage(back StorageBackend) *Storage {
+	return &Storage{back: back}
+}
+
+// Acquire locks a file, given by its full path, for a user. If the user
+// already holds the lock, it is refreshed. If another user does, it
+// returns the lock along with errors.ErrLocked.
+func (s *Storage) Acquire(path string, userID uint, username string, timeout int64) (*Lock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path = filepath.Clean(path)
+	l, err := s.GetByPath(path)
+	switch {
+	case err == errors.ErrNotExist:
+	case err != nil:
+		return nil, err
+	case l.UserID != userID:
+		return l, errors.ErrLocked
+	default:
+		// The lock is taken anew if it was released meanwhile.
+		err = s.Refresh(l, timeout)
+		if err != errors.ErrNotExist {
+			return l, err
+		}
+	}
+
+	b := make([]byte, tokenSize)
+	_, err = rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	timeout = cleanTimeout(timeout)
+	l = &Lock{
+		Token:    "opaquelocktoken:" + hex.EncodeToString(b),
+		Path:     path,
+		UserID:   userID,
+		Username: username,
+		Created:  now,
+		Timeout:  timeout,
+		Expire:   now + timeout,
+	}
+
+	return l, s.back.Save(l)
+}
+
+// Refresh extends a lock by its timeout, or by a new one if set. It
+// returns errors.ErrNotExist if the lock was released meanwhile, which
+// is not restored.
+func (s *Storage) Refresh(l *Lock, timeout int64) error {
+	if timeout != 0 {
+		l.Timeout = cleanTimeout(timeout)
+	}
+
+	l.Expire = time.Now().Unix() + l.Timeout
+	return s.back.Update(l, "Timeout", "Expire")
+}
+
+// Get gets a lock by its token. It returns errors.ErrNotExist if the
+// lock doesn't exist or has expired.
+func (s *Storage) Get(token string) (*Lock, error) {
+	l, err := s.back.Get(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.valid(l)
+}
+
+// GetByPath gets the lock of a file, given by its full path. It returns
+// errors.ErrNotExist if the file isn't locked.
+func (s *Storage) GetByPath(path string) (*Lock, error) {
+	l, err := s.back.GetByPath(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.valid(l)
+}
+
+func (s *Storage) valid(l *Lock) (*Lock, error) {
+	if l.Expired(time.Now().Unix()) {
+		if err := s.back.Delete(l.Token); err != nil {
+			return nil, err
+		}
+		return nil, errors.ErrNotExist
+	}
+
+	return l, nil
+}
+
+// Gets gets the locks that haven't expired.
+func (s *Storage) Gets() ([]*Lock, error) {
+	list, err := s.back.Gets()
+	if err == errors.ErrNotExist {
+		return []*Lock{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	valid := make([]*Lock, 0, len(list))
+	for _, l := range list {
+		if !l.Expired(now) {
+			valid = append(valid, l)
+		}
+	}
+
+	return valid, nil
+}
+
+// Check checks if a user can change a path, which is a full path: it
+// returns the lock along with errors.ErrLocked if the path, or a file
+// inside it, is locked by another user.
+func (s *Storage) Check(path string, userID uint) (*Lock, error) {
+	list, err := s.Gets()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range list {
+		if l.UserID != userID && l.Covers(path) {
+			return l, errors.ErrLocked
+		}
+	}
+
+	return nil, nil
+}
+
+// Delete wraps a StorageBackend.Delete.
+func (s *Storage) Delete(token string) error {
+	return s.back.Delete(token)
+}
+
+// Sweep deletes the expired locks and returns how many were deleted.
+func (s *Storage) Sweep() (int, error) {
+	list, err := s.back.FindExpired(time.Now().Unix())
+	if err == errors.ErrNotExist {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	for i, l := range list {
+		if err := s.back.Delete(l.Token); err != nil {
+			return i, err
+		}
+	}
+
+	return len(list), nil
+}
@@ -4,9 +4,13 @@ import (
 	"runtime"
 
 	"github.com/filebrowser/filebrowser/v2/cmd"
+	"github.com/filebrowser/filebrowser/v2/runner"
 )
 
 func main() {
+	// The sandboxed commands are started through this binary.
+	runner.ExecSandboxed()
+
 	runtime.GOMAXPROCS(runtime.NumCPU())
 	cmd.Execute()
 }
@@ -0,0 +1,208 @@
+package merge
+
+import (
+	"strings"
+)
+
+// Markers surrounding the two sides of a conflict.
+const (
+	MarkerOurs   = "<<<<<<< "
+	MarkerSep    = "======="
+	MarkerTheirs = ">>>>>>> "
+)
+
+// maxEdits is the maximum number of lines inserted or removed on a side
+// for its changes to be merged. The memory used to find them grows with
+// its square.
+const maxEdits = 1000
+
+// Result is the outcome of a three-way merge.
+type Result struct {
+	Content   string `json:"content"`
+	Conflicts int    `json:"conflicts"`
+}
+
+// Merge merges, line by line, the changes made to base by ours and
+// theirs. The regions changed differently on both sides are kept as
+// conflicts, surrounded by markers labelled with oursLabel and
+// theirsLabel, in the style of diff3 and git. If a side changed too
+// many lines, the whole texts are a single conflict.
+func Merge(base, ours, theirs, oursLabel, theirsLabel string) *Result {
+	b, o, t := split(base), split(ours), split(theirs)
+	mo, okOurs := matches(b, o)
+	mt, okTheirs := matches(b, t)
+
+	var (
+		out       strings.Builder
+		conflicts int
+		i, j, k   int
+	)
+
+	resolve := func(bc, oc, tc []string) {
+		switch {
+		case equal(oc, bc):
+			write(&out, tc, false)
+		case equal(tc, bc), equal(oc, tc):
+			write(&out, oc, false)
+		default:
+			conflicts++
+			out.WriteString(MarkerOurs + oursLabel + "\n")
+			write(&out, oc, true)
+			out.WriteString(MarkerSep + "\n")
+			write(&out, tc, true)
+			out.WriteString(MarkerTheirs + theirsLabel + "\n")
+		}
+	}
+
+	if !okOurs || !okTheirs {
+		resolve(b, o, t)
+		return &Result{Content: out.String(), Conflicts: conflicts}
+	}
+
+	for i < len(b) {
+		// The lines unchanged on both sides are kept.
+		if mo[i] == j && mt[i] == k {
+			out.WriteString(b[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+
+		// Otherwise, the chunk runs up to the next line of the base
+		// kept on both sides.
+		next := i
+		for next < len(b) && (mo[next] < 0 || mt[next] < 0) {
+			next++
+		}
+
+		if next == len(b) {
+			break
+		}
+
+		resolve(b[i:next], o[j:mo[next]], t[k:mt[next]])
+		i, j, k = next, mo[next], mt[next]
+	}
+
+	resolve(b[i:], o[j:], t[k:])
+
+	return &Result{Content: out.String(), Conflicts: conflicts}
+}
+
+// write writes lines. The last line of a text may not be ended, which
+// it must be if the markers of a conflict follow it.
+func write(out *strings.Builder, lines []string, end bool) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+
+	if n := len(lines); end && n > 0 && !strings.HasSuffix(lines[n-1], "\n") {
+		out.WriteString("\n")
+	}
+}
+
+// split splits a text in lines, keeping their endings.
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matches returns, for each line of a, the index of the line of b it is
+// kept as in the shortest edit script from a to b, or -1 if it is
+// removed. It is Myers' O(ND) difference algorithm. It returns false if
+// the script has more than maxEdits edits.
+func matches(a, b []string) ([]int, bool) {
+	m := make([]int, len(a))
+	for i := range m {
+		m[i] = -1
+	}
+
+	n, l := len(a), len(b)
+	max := n + l
+	if max == 0 {
+		return m, true
+	}
+
+	// v holds the furthest x reached on each diagonal k = x - y, at
+	// v[k+max]. trace keeps, for each d, v on the diagonals -d to d.
+	v := make([]int, 2*max+2) //nolint:mnd
+	var trace [][]int
+
+	var d int
+search:
+	for d = 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+				x = v[max+k+1]
+			} else {
+				x = v[max+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < l && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+
+			v[max+k] = x
+			if x >= n && y >= l {
+				break search
+			}
+		}
+
+		trace = append(trace, append([]int(nil), v[max-d:max+d+1]...))
+		if d == maxEdits {
+			return nil, false
+		}
+	}
+
+	x, y := n, l
+	for ; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+d-1] }
+
+		k := x - y
+		var pk int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			pk = k + 1
+		} else {
+			pk = k - 1
+		}
+
+		px := at(pk)
+		py := px - pk
+		for x > px && y > py {
+			x, y = x-1, y-1
+			m[x] = y
+		}
+
+		x, y = px, py
+	}
+
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		m[x] = y
+	}
+
+	return m, true
+}
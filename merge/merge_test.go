@@ -0,0 +1,129 @@
+package merge
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		content            string
+		conflicts          int
+	}{
+		{
+			name:    "unchanged",
+			base:    "a\nb\n",
+			ours:    "a\nb\n",
+			theirs:  "a\nb\n",
+			content: "a\nb\n",
+		},
+		{
+			name:    "one side",
+			base:    "a\nb\nc\n",
+			ours:    "a\nB\nc\n",
+			theirs:  "a\nb\nc\n",
+			content: "a\nB\nc\n",
+		},
+		{
+			name:    "both sides apart",
+			base:    "a\nb\nc\nd\ne\n",
+			ours:    "A\nb\nc\nd\ne\n",
+			theirs:  "a\nb\nc\nd\nE\n",
+			content: "A\nb\nc\nd\nE\n",
+		},
+		{
+			name:    "same change",
+			base:    "a\nb\nc\n",
+			ours:    "a\nB\nc\n",
+			theirs:  "a\nB\nc\n",
+			content: "a\nB\nc\n",
+		},
+		{
+			name:    "insertions and deletions",
+			base:    "a\nb\nc\nd\ne\n",
+			ours:    "x\na\nb\nc\nd\ne\n",
+			theirs:  "a\nb\nc\ne\ny\n",
+			content: "x\na\nb\nc\ne\ny\n",
+		},
+		{
+			name:      "conflict",
+			base:      "a\nb\nc\n",
+			ours:      "a\nyours\nc\n",
+			theirs:    "a\nsaved\nc\n",
+			content:   "a\n<<<<<<< yours\nyours\n=======\nsaved\n>>>>>>> saved\nc\n",
+			conflicts: 1,
+		},
+		{
+			name:    "missing final newline",
+			base:    "a\nb\nc",
+			ours:    "A\nb\nc",
+			theirs:  "a\nb\nc\n",
+			content: "A\nb\nc\n",
+		},
+		{
+			name:      "conflict on a missing final newline",
+			base:      "a\nb",
+			ours:      "a\nyours",
+			theirs:    "a\nsaved",
+			content:   "a\n<<<<<<< yours\nyours\n=======\nsaved\n>>>>>>> saved\n",
+			conflicts: 1,
+		},
+		{
+			name:    "empty base",
+			base:    "",
+			ours:    "a\n",
+			theirs:  "",
+			content: "a\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Merge(tt.base, tt.ours, tt.theirs, "yours", "saved")
+			if r.Content != tt.content || r.Conflicts != tt.conflicts {
+				t.Errorf("got %q with %d conflicts, want %q with %d conflicts",
+					r.Content, r.Conflicts, tt.content, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestMergeTooManyEdits(t *testing.T) {
+	lines := func(prefix string, n int) string {
+		var b strings.Builder
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&b, "%s%d\n", prefix, i)
+		}
+		return b.String()
+	}
+
+	base := lines("a", maxEdits)
+	ours := lines("b", maxEdits)
+
+	// The changes of a single side are still taken as they are.
+	r := Merge(base, ours, base, "yours", "saved")
+	if r.Content != ours || r.Conflicts != 0 {
+		t.Errorf("one side: got %d conflicts, want the changes of ours", r.Conflicts)
+	}
+
+	// Otherwise, the whole texts are a conflict, even if the other side
+	// changed a single line.
+	theirs := "changed\n" + base[strings.Index(base, "\n")+1:]
+	want := MarkerOurs + "yours\n" + ours + MarkerSep + "\n" + theirs + MarkerTheirs + "saved\n"
+
+	r = Merge(base, ours, theirs, "yours", "saved")
+	if r.Content != want || r.Conflicts != 1 {
+		t.Errorf("both sides: got %d conflicts, want the whole texts as a conflict", r.Conflicts)
+	}
+
+	// Below the limit, the changes are merged line by line.
+	small := lines("a", maxEdits/4)
+	r = Merge(small, lines("b", maxEdits/4), "changed\n"+small[strings.Index(small, "\n")+1:], "yours", "saved")
+	if r.Conflicts != 1 || strings.Count(r.Content, MarkerSep) != 1 ||
+		!strings.HasSuffix(r.Content, ">>>>>>> saved\n") {
+		t.Errorf("below the limit: got %d conflicts", r.Conflicts)
+	}
+}
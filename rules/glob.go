@@ -0,0 +1,126 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+)
+
+// compileGlob compiles a gitignore-style glob pattern to a regular
+// expression matching the paths, which start with "/", of the files the
+// pattern applies to:
+//
+//   - "*" matches anything but "/", "?" matches any character but "/"
+//     and "[...]" matches a character of the class ("[!...]" negates it);
+//   - "**" matches any number of folders when it is a whole segment;
+//   - a pattern with a "/" at its beginning or in its middle is relative
+//     to the scope, otherwise it matches at any depth;
+//   - a pattern matching a folder matches everything in it too, and a
+//     trailing "/" has no other effect;
+//   - "/" matches the whole scope.
+//
+// The segments are always matched entirely, so "/secret" doesn't match
+// "/secret-not".
+func compileGlob(pattern string) *regexp.Regexp {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if pattern == "" {
+		// The root of the scope.
+		return regexp.MustCompile("^/")
+	}
+
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if anchored {
+		b.WriteString("/")
+	} else {
+		b.WriteString("(?:.*/)?")
+	}
+
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+
+		if segment == "**" {
+			if last {
+				b.WriteString(".*")
+			} else {
+				b.WriteString("(?:.*/)?")
+			}
+			continue
+		}
+
+		writeSegment(&b, segment)
+		if !last {
+			b.WriteString("/")
+		}
+	}
+
+	b.WriteString("(?:/.*)?$")
+	return regexp.MustCompile(b.String())
+}
+
+// writeSegment writes the regular expression matching a segment of
+// a glob pattern. A "[" without a matching "]" is taken literally.
+func writeSegment(b *strings.Builder, segment string) {
+	runes := []rune(segment)
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; c {
+		case '*':
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(string(runes[i])))
+		case '[':
+			end := classEnd(runes, i)
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+
+			class := runes[i+1 : end]
+			b.WriteString("[")
+			if class[0] == '!' || class[0] == '^' {
+				b.WriteString("^/")
+				class = class[1:]
+			}
+			for _, r := range class {
+				if r == '\\' || r == '[' || r == ']' {
+					b.WriteString(`\`)
+				}
+				b.WriteRune(r)
+			}
+			b.WriteString("]")
+			i = end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+}
+
+// classEnd gets the index of the "]" closing the character class
+// starting at start, or -1 if there is none.
+func classEnd(runes []rune, start int) int {
+	i := start + 1
+	if i < len(runes) && (runes[i] == '!' || runes[i] == '^') {
+		i++
+	}
+
+	// A "]" right after the opening is part of the class.
+	if i < len(runes) && runes[i] == ']' {
+		i++
+	}
+
+	for ; i < len(runes); i++ {
+		if runes[i] == ']' {
+			return i
+		}
+	}
+
+	return -1
+}
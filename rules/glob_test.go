@@ -0,0 +1,74 @@
+package rules
+
+import "testing"
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		// "*" stays in a segment, "**" spans folders.
+		{"/docs/*.md", "/docs/a.md", true},
+		{"/docs/*.md", "/docs/sub/a.md", false},
+		{"/docs/**/*.md", "/docs/a.md", true},
+		{"/docs/**/*.md", "/docs/sub/deep/a.md", true},
+		{"/docs/**", "/docs/sub/a.md", true},
+		{"/docs/**", "/other/a.md", false},
+		{"**/secret", "/a/b/secret", true},
+		{"*", "/a/b", true},
+		{"a?c", "/abc", true},
+		{"a?c", "/a/c", false},
+		{"[ab].txt", "/b.txt", true},
+		{"[!ab].txt", "/c.txt", true},
+		{"[!ab].txt", "/a.txt", false},
+
+		// The patterns with a "/" are relative to the scope.
+		{"/secret", "/secret", true},
+		{"/secret", "/a/secret", false},
+		{"a/secret", "/a/secret", true},
+		{"a/secret", "/b/a/secret", false},
+		{"secret", "/secret", true},
+		{"secret", "/a/b/secret", true},
+		{"*.log", "/a/b.log", true},
+
+		// The segments are matched entirely, and the folders with
+		// everything in them.
+		{"/secret", "/secret-not", false},
+		{"secret", "/a/not-secret", false},
+		{"/secret", "/secret/a.txt", true},
+		{"secret", "/a/secret/b/c.txt", true},
+
+		// A trailing "/" has no other effect.
+		{"/secret/", "/secret", true},
+		{"/secret/", "/secret/a.txt", true},
+		{"secret/", "/a/secret", true},
+		{"secret/", "/a/secret-not", false},
+
+		// "/" is the whole scope.
+		{"/", "/", true},
+		{"/", "/a/b.txt", true},
+
+		{`\*.txt`, "/*.txt", true},
+		{`\*.txt`, "/a.txt", false},
+	}
+
+	for _, tt := range tests {
+		rule := &Rule{Glob: true, Path: tt.pattern}
+		if got := rule.Matches(tt.path); got != tt.want {
+			t.Errorf("%q matching %q: got %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGlobPathChange(t *testing.T) {
+	rule := &Rule{Glob: true, Path: "/a"}
+	if !rule.Matches("/a/b") {
+		t.Fatal("/a doesn't match /a/b")
+	}
+
+	rule.Path = "/b"
+	if rule.Matches("/a/b") || !rule.Matches("/b") {
+		t.Error("the pattern wasn't compiled again")
+	}
+}
@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// IgnoreFile is the name of the files listing, with the gitignore
+// syntax, the files to hide in the folder they are in.
+const IgnoreFile = ".fbignore"
+
+// ParseIgnore parses the content of the ignore file of a folder into
+// glob rules. A pattern hides the matching files and a pattern starting
+// with "!" shows them again. Like in gitignore, the patterns with a "/"
+// at their beginning or in their middle are relative to the folder and
+// the others match at any depth under it.
+func ParseIgnore(dir string, content []byte) []Rule {
+	dir = strings.TrimSuffix(dir, "/")
+	rules := []Rule{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		allow := false
+		if strings.HasPrefix(line, "!") {
+			allow = true
+			line = line[1:]
+		}
+
+		// A leading backslash escapes a leading "#" or "!".
+		if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+
+		pattern := strings.TrimSuffix(line, "/")
+		if pattern == "" {
+			continue
+		}
+
+		if !strings.Contains(pattern, "/") {
+			pattern = "**/" + pattern
+		}
+
+		rules = append(rules, Rule{
+			Glob:  true,
+			Allow: allow,
+			Path:  escapeGlob(dir) + "/" + strings.TrimPrefix(pattern, "/"),
+		})
+	}
+
+	return rules
+}
+
+// IsDotfile checks if a path is, or is in, a file or a folder whose
+// name starts with a dot.
+func IsDotfile(path string) bool {
+	for _, name := range strings.Split(path, "/") {
+		if strings.HasPrefix(name, ".") && name != "." && name != ".." {
+			return true
+		}
+	}
+
+	return false
+}
+
+// escapeGlob escapes the characters of a path that have a
+// meaning in a glob pattern.
+func escapeGlob(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -5,17 +5,84 @@ import (
 	"strings"
 )
 
-// Checker is a Rules checker.
+// Checker is a Rules checker. Check tells if a path is visible and
+// CheckAction if an action can be done on it.
 type Checker interface {
 	Check(path string) bool
+	CheckAction(path string, action Action) bool
 }
 
-// Rule is a allow/disallow rule.
+// Action is an action that can be restricted by a rule.
+type Action string
+
+// The actions that can be restricted by a rule. Each of them
+// corresponds to a permission of the users.
+const (
+	ActionCreate   Action = "create"
+	ActionRename   Action = "rename"
+	ActionModify   Action = "modify"
+	ActionDelete   Action = "delete"
+	ActionDownload Action = "download"
+	ActionShare    Action = "share"
+	ActionExecute  Action = "execute"
+)
+
+// Actions are all the actions that can be restricted by a rule.
+var Actions = []Action{
+	ActionCreate,
+	ActionRename,
+	ActionModify,
+	ActionDelete,
+	ActionDownload,
+	ActionShare,
+	ActionExecute,
+}
+
+// Rule is a allow/disallow rule. Without actions, it decides whether
+// the matching paths are visible. With actions, it only decides whether
+// these actions can be done on the matching paths, which stay visible.
+// An action rule can't grant more than the permissions of the user.
+//
+// Path is either a prefix of the paths or, if Glob is set, a
+// gitignore-style glob pattern.
 type Rule struct {
-	Regex  bool    `json:"regex"`
-	Allow  bool    `json:"allow"`
-	Path   string  `json:"path"`
-	Regexp *Regexp `json:"regexp"`
+	Regex   bool     `json:"regex"`
+	Glob    bool     `json:"glob"`
+	Allow   bool     `json:"allow"`
+	Path    string   `json:"path"`
+	Regexp  *Regexp  `json:"regexp"`
+	Actions []Action `json:"actions,omitempty"`
+
+	glob       *regexp.Regexp
+	globSource string
+}
+
+// IsActionRule checks if the rule restricts actions instead
+// of the visibility.
+func (r *Rule) IsActionRule() bool {
+	return len(r.Actions) > 0
+}
+
+// HasAction checks if the rule applies to the action.
+func (r *Rule) HasAction(action Action) bool {
+	for _, a := range r.Actions {
+		if a == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseAction parses the name of an action.
+func ParseAction(name string) (Action, bool) {
+	for _, a := range Actions {
+		if string(a) == name {
+			return a, true
+		}
+	}
+
+	return "", false
 }
 
 // Matches matches a path against a rule.
@@ -24,9 +91,28 @@ func (r *Rule) Matches(path string) bool {
 		return r.Regexp.MatchString(path)
 	}
 
+	if r.Glob {
+		if r.glob == nil || r.globSource != r.Path {
+			r.glob = compileGlob(r.Path)
+			r.globSource = r.Path
+		}
+
+		return r.glob.MatchString(path)
+	}
+
 	return strings.HasPrefix(path, r.Path)
 }
 
+// Decides checks if the rule decides the outcome of the action on the
+// path. An empty action stands for the visibility of the path.
+func (r *Rule) Decides(path string, action Action) bool {
+	if action == "" {
+		return !r.IsActionRule() && r.Matches(path)
+	}
+
+	return r.HasAction(action) && r.Matches(path)
+}
+
 // Regexp is a wrapper to the native regexp type where we
 // save the raw expression.
 type Regexp struct {
@@ -0,0 +1,17 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package runner
+
+import (
+	"os/exec"
+)
+
+// setGroup does nothing, the process groups not being supported
+// on this platform.
+func setGroup(cmd *exec.Cmd) {}
+
+// killGroup kills a command.
+func killGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,24 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setGroup runs a command in its own process group, for it to be
+// killed along with the processes it starts.
+func setGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// killGroup kills a command started with setGroup and the processes
+// of its group.
+func killGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
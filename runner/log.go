@@ -0,0 +1,51 @@
+package runner
+
+// Execution is a record of the hook execution log. The paths are
+// relative to the scope of the user. Duration is in milliseconds and
+// the outputs are truncated to MaxOutput bytes.
+type Execution struct {
+	ID          uint   `json:"id" storm:"id,increment"`
+	Time        int64  `json:"time" storm:"index"`
+	Trigger     string `json:"trigger" storm:"index"`
+	Command     string `json:"command"`
+	Username    string `json:"username" storm:"index"`
+	Path        string `json:"path"`
+	Destination string `json:"destination,omitempty"`
+	Blocking    bool   `json:"blocking"`
+	Duration    int64  `json:"duration"`
+	ExitCode    int    `json:"exitCode"`
+	TimedOut    bool   `json:"timedOut"`
+	Stdout      string `json:"stdout"`
+	Stderr      string `json:"stderr"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Failed checks if the hook failed to run, timed out or exited
+// with a non zero code.
+func (e *Execution) Failed() bool {
+	return e.Error != "" || e.TimedOut || e.ExitCode != 0
+}
+
+// LogQuery filters the hook execution log. The empty fields
+// don't filter anything.
+type LogQuery struct {
+	Trigger  string
+	Username string
+	Failed   bool
+	Limit    int
+	Offset   int
+}
+
+// Matches checks if an execution matches the query, its limit
+// and offset apart.
+func (q *LogQuery) Matches(e *Execution) bool {
+	if q.Trigger != "" && e.Trigger != q.Trigger {
+		return false
+	}
+
+	if q.Username != "" && e.Username != q.Username {
+		return false
+	}
+
+	return !q.Failed || e.Failed()
+}
@@ -0,0 +1,124 @@
+//go:build linux
+// +build linux
+
+package runner
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// StartPTY starts a command in a new session, with a pseudo terminal of
+// the given size as its controlling terminal and standard streams. It
+// returns the master side of the terminal, which the caller must close.
+func StartPTY(cmd *exec.Cmd, rows, cols uint16) (*os.File, error) {
+	ptm, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	tty, err := openTTY(ptm)
+	if err != nil {
+		ptm.Close()
+		return nil, err
+	}
+	defer tty.Close()
+
+	err = ResizePTY(ptm, rows, cols)
+	if err != nil {
+		ptm.Close()
+		return nil, err
+	}
+
+	cmd.Stdin = tty
+	cmd.Stdout = tty
+	cmd.Stderr = tty
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+
+	err = cmd.Start()
+	if err != nil {
+		ptm.Close()
+		return nil, err
+	}
+
+	return ptm, nil
+}
+
+// openTTY unlocks the slave side of a pseudo terminal and opens it.
+func openTTY(ptm *os.File) (*os.File, error) {
+	fd := int(ptm.Fd())
+
+	err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := unix.IoctlGetInt(fd, unix.TIOCGPTN)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.OpenFile("/dev/pts/"+strconv.Itoa(n), os.O_RDWR|syscall.O_NOCTTY, 0)
+}
+
+// ResizePTY sets the window size of a pseudo terminal.
+func ResizePTY(ptm *os.File, rows, cols uint16) error {
+	return unix.IoctlSetWinsize(int(ptm.Fd()), unix.TIOCSWINSZ, &unix.Winsize{
+		Row: rows,
+		Col: cols,
+	})
+}
+
+// KillPTY kills a command started by StartPTY along with the
+// processes it started in its session. The shells run the jobs
+// in their own process groups, so the whole session is killed.
+func KillPTY(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+
+	sid := cmd.Process.Pid
+	for _, pid := range sessionProcesses(sid) {
+		_ = syscall.Kill(pid, syscall.SIGKILL)
+	}
+
+	return syscall.Kill(-sid, syscall.SIGKILL)
+}
+
+// sessionProcesses lists the processes of a session, other than its leader.
+func sessionProcesses(sid int) []int {
+	paths, _ := filepath.Glob("/proc/[0-9]*/stat")
+
+	var pids []int
+	for _, path := range paths {
+		pid, err := strconv.Atoi(filepath.Base(filepath.Dir(path)))
+		if err != nil || pid == sid {
+			continue
+		}
+
+		stat, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		// The name of the command may contain spaces and parentheses.
+		fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+		if len(fields) > 3 && fields[3] == strconv.Itoa(sid) {
+			pids = append(pids, pid)
+		}
+	}
+
+	return pids
+}
@@ -0,0 +1,30 @@
+//go:build !linux
+// +build !linux
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+// StartPTY isn't supported on this platform.
+func StartPTY(cmd *exec.Cmd, rows, cols uint16) (*os.File, error) {
+	return nil, errors.ErrPTYUnsupported
+}
+
+// ResizePTY isn't supported on this platform.
+func ResizePTY(ptm *os.File, rows, cols uint16) error {
+	return errors.ErrPTYUnsupported
+}
+
+// KillPTY kills a command.
+func KillPTY(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+
+	return cmd.Process.Kill()
+}
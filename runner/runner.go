@@ -1,53 +1,224 @@
 package runner
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
-	"os"
+	"mime"
+	"net/http"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/filebrowser/filebrowser/v2/errors"
 	"github.com/filebrowser/filebrowser/v2/settings"
 	"github.com/filebrowser/filebrowser/v2/users"
+	"github.com/filebrowser/filebrowser/v2/webhooks"
 )
 
-// Runner is a commands runner.
+// MaxOutput is the number of bytes of each output of the
+// hooks that is kept in the execution log.
+const MaxOutput = 64 * 1024
+
+// killWait is how long a hook killed for running too long is waited for.
+const killWait = time.Second
+
+// Runner is a commands runner. It also sends the events to the
+// webhooks, if it has a dispatcher, and records the executions of
+// the hooks in Log, if set. IP and ContentLength are the client IP
+// and the body size of the request the events happen in, if any.
 type Runner struct {
 	*settings.Settings
+	Webhooks      *webhooks.Dispatcher
+	Log           *Storage
+	IP            string
+	ContentLength int64
+}
+
+// Event describes the event a hook is run for. It is given to the
+// hooks, as JSON, in the EVENT_JSON environment variable. The paths
+// are relative to the scope of the user. Size and MIME are the ones
+// of the file, or of the upload for the before hooks of the uploads.
+// Vars are the environment variables specific to the event.
+type Event struct {
+	Trigger     string            `json:"trigger"`
+	Event       string            `json:"event"`
+	Time        int64             `json:"time"`
+	UserID      uint              `json:"userId"`
+	Username    string            `json:"username"`
+	Scope       string            `json:"scope"`
+	Path        string            `json:"path"`
+	Destination string            `json:"destination,omitempty"`
+	Size        int64             `json:"size"`
+	MIME        string            `json:"mime"`
+	IP          string            `json:"ip"`
+	Vars        map[string]string `json:"vars,omitempty"`
+}
+
+// Rejection is the error of a before hook that exited with a non zero
+// code, rejecting the action. Message is the last line the hook printed
+// on its standard output, and is meant to be shown to the user.
+type Rejection struct {
+	Trigger string
+	Message string
+}
+
+func (e *Rejection) Error() string {
+	if e.Message == "" {
+		return "rejected by the " + e.Trigger + " hook"
+	}
+
+	return "rejected by the " + e.Trigger + " hook: " + e.Message
+}
+
+// Is makes the rejections permission errors.
+func (e *Rejection) Is(target error) bool {
+	return target == errors.ErrPermissionDenied
 }
 
 // RunHook runs the hooks for the before and after event.
 func (r *Runner) RunHook(fn func() error, evt, path, dst string, user *users.User) error {
-	path = user.FullPath(path)
-	dst = user.FullPath(dst)
-
-	if val, ok := r.Commands["before_"+evt]; ok {
-		for _, command := range val {
-			err := r.exec(command, "before_"+evt, path, dst, user)
-			if err != nil {
-				return err
-			}
-		}
+	return r.RunHookWith(fn, evt, path, dst, user, nil)
+}
+
+// RunHookWith runs the hooks for the before and after event, giving
+// them vars as additional environment variables.
+func (r *Runner) RunHookWith(fn func() error, evt, path, dst string, user *users.User, vars map[string]string) error {
+	err := r.Before(evt, path, dst, user, vars)
+	if err != nil {
+		return err
 	}
 
-	err := fn()
+	err = fn()
 	if err != nil {
 		return err
 	}
 
-	if val, ok := r.Commands["after_"+evt]; ok {
-		for _, command := range val {
-			err := r.exec(command, "after_"+evt, path, dst, user)
-			if err != nil {
-				return err
-			}
+	return r.After(evt, path, dst, user, vars)
+}
+
+// Before runs the hooks for the before event, any of which can reject
+// the event. It is meant for the events that can't be wrapped in a
+// function given to RunHook.
+func (r *Runner) Before(evt, path, dst string, user *users.User, vars map[string]string) error {
+	return r.run("before_"+evt, evt, path, dst, user, vars)
+}
+
+// After sends an event that happened to the webhooks and runs the
+// hooks for the after event.
+func (r *Runner) After(evt, path, dst string, user *users.User, vars map[string]string) error {
+	r.dispatch(evt, path, dst, user)
+	return r.run("after_"+evt, evt, path, dst, user, vars)
+}
+
+func (r *Runner) run(trigger, evt, path, dst string, user *users.User, vars map[string]string) error {
+	commands := r.Commands[trigger]
+	if len(commands) == 0 {
+		return nil
+	}
+
+	event := r.describe(trigger, evt, path, dst, user)
+	event.Vars = vars
+	for _, command := range commands {
+		err := r.exec(command, event, user)
+		if err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
-func (r *Runner) exec(raw, evt, path, dst string, user *users.User) error {
+// dispatch sends an event that happened to the webhooks.
+func (r *Runner) dispatch(evt, path, dst string, user *users.User) {
+	if r.Webhooks == nil {
+		return
+	}
+
+	p := &webhooks.Payload{
+		Event:       evt,
+		User:        webhooks.User{ID: user.ID, Username: user.Username},
+		Path:        path,
+		Destination: dst,
+	}
+
+	target := path
+	if dst != "" {
+		target = dst
+	}
+
+	if user.Fs != nil && target != "" {
+		if info, err := user.Fs.Stat(target); err == nil && !info.IsDir() {
+			p.Size = info.Size()
+		}
+	}
+
+	r.Webhooks.Dispatch(p)
+}
+
+// describe gathers the information given to the hooks of a trigger.
+func (r *Runner) describe(trigger, evt, path, dst string, user *users.User) *Event {
+	event := &Event{
+		Trigger:     trigger,
+		Event:       evt,
+		Time:        time.Now().Unix(),
+		UserID:      user.ID,
+		Username:    user.Username,
+		Scope:       user.Scope,
+		Path:        path,
+		Destination: dst,
+		IP:          r.IP,
+	}
+
+	target := path
+	if dst != "" {
+		target = dst
+	}
+
+	if target == "" || user.Fs == nil {
+		return event
+	}
+
+	event.MIME = mime.TypeByExtension(filepath.Ext(target))
+
+	info, err := user.Fs.Stat(target)
+	if err == nil && !info.IsDir() {
+		event.Size = info.Size()
+		if event.MIME == "" {
+			event.MIME = sniff(user, target)
+		}
+	}
+
+	upload := evt == "upload" || evt == "save"
+	if upload && strings.HasPrefix(trigger, "before_") && r.ContentLength > 0 {
+		event.Size = r.ContentLength
+	}
+
+	return event
+}
+
+// sniff detects the MIME type of a file from its first bytes.
+func sniff(user *users.User, path string) string {
+	file, err := user.Fs.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	buffer := make([]byte, 512) //nolint:mnd
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return ""
+	}
+
+	return http.DetectContentType(buffer[:n])
+}
+
+func (r *Runner) exec(raw string, event *Event, user *users.User) error {
 	blocking := true
 
 	if strings.HasSuffix(raw, "&") {
@@ -55,27 +226,202 @@ func (r *Runner) exec(raw, evt, path, dst string, user *users.User) error {
 		raw = strings.TrimSpace(strings.TrimSuffix(raw, "&"))
 	}
 
+	execution := &Execution{
+		Trigger:     event.Trigger,
+		Command:     raw,
+		Username:    event.Username,
+		Path:        event.Path,
+		Destination: event.Destination,
+		Blocking:    blocking,
+	}
+
 	command, err := ParseCommand(r.Settings, raw)
+	if err != nil {
+		execution.Error = err.Error()
+		r.record(execution)
+		return err
+	}
+
+	blob, err := json.Marshal(event)
 	if err != nil {
 		return err
 	}
 
+	// The events of the users, such as the logins, have no paths.
+	path, dst := "", ""
+	if user.Fs != nil {
+		path = user.FullPath(event.Path)
+		dst = user.FullPath(event.Destination)
+	}
+
 	cmd := exec.Command(command[0], command[1:]...) //nolint:gosec
-	cmd.Env = append(os.Environ(), fmt.Sprintf("FILE=%s", path))
+	cmd.Env = append(Environ(user.Sandbox), fmt.Sprintf("FILE=%s", path))
 	cmd.Env = append(cmd.Env, fmt.Sprintf("SCOPE=%s", user.Scope)) //nolint:gocritic
-	cmd.Env = append(cmd.Env, fmt.Sprintf("TRIGGER=%s", evt))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("TRIGGER=%s", event.Trigger))
 	cmd.Env = append(cmd.Env, fmt.Sprintf("USERNAME=%s", user.Username))
 	cmd.Env = append(cmd.Env, fmt.Sprintf("DESTINATION=%s", dst))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("EVENT=%s", event.Event))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("USER_ID=%d", user.ID))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("FILE_PATH=%s", event.Path))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("DESTINATION_PATH=%s", event.Destination))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("FILE_SIZE=%d", event.Size))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("MIME_TYPE=%s", event.MIME))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("CLIENT_IP=%s", event.IP))
+	cmd.Env = append(cmd.Env, fmt.Sprintf("EVENT_JSON=%s", blob))
+	for name, value := range event.Vars {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", name, value))
+	}
 
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	err = Sandbox(cmd, user.Sandbox)
+	if err != nil {
+		execution.Error = err.Error()
+		r.record(execution)
+		return err
+	}
+	setGroup(cmd)
+
+	stdout := &limitedBuffer{max: MaxOutput}
+	stderr := &limitedBuffer{max: MaxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	execution.Time = start.Unix()
 
 	if !blocking {
 		log.Printf("[INFO] Nonblocking Command: \"%s\"", strings.Join(command, " "))
-		return cmd.Start()
+		err = cmd.Start()
+		if err != nil {
+			execution.Error = err.Error()
+			r.record(execution)
+			return err
+		}
+
+		go r.finish(execution, cmd, r.HookTimeouts.ForNonBlocking(event.Trigger), start, stdout, stderr)
+		return nil
 	}
 
 	log.Printf("[INFO] Blocking Command: \"%s\"", strings.Join(command, " "))
-	return cmd.Run()
+	err = cmd.Start()
+	if err != nil {
+		execution.Error = err.Error()
+		r.record(execution)
+		return err
+	}
+
+	err = r.finish(execution, cmd, r.HookTimeouts.For(event.Trigger), start, stdout, stderr)
+	if err == nil {
+		return nil
+	}
+
+	if execution.TimedOut || execution.ExitCode <= 0 || !strings.HasPrefix(event.Trigger, "before_") {
+		return err
+	}
+
+	return &Rejection{
+		Trigger: event.Trigger,
+		Message: lastLine(execution.Stdout),
+	}
+}
+
+// finish waits for a started hook to exit, killing it if it runs for
+// longer than the timeout, if any, and records its execution.
+func (r *Runner) finish(execution *Execution, cmd *exec.Cmd, timeout time.Duration,
+	start time.Time, stdout, stderr *limitedBuffer) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	// A nil channel never fires, for the hooks without timeout.
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	var err error
+	select {
+	case err = <-done:
+	case <-expired:
+		// The processes the hook started are killed with it. The ones
+		// that left its group may still hold its outputs open, so it
+		// is only waited for a while.
+		_ = killGroup(cmd)
+		select {
+		case <-done:
+		case <-time.After(killWait):
+		}
+		execution.TimedOut = true
+		err = fmt.Errorf("%s hook timed out after %s", execution.Trigger, timeout)
+	}
+
+	execution.Duration = time.Since(start).Milliseconds()
+	execution.Stdout = stdout.String()
+	execution.Stderr = stderr.String()
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		execution.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		execution.ExitCode = -1
+		execution.Error = err.Error()
+	}
+
+	if execution.Failed() {
+		log.Printf("[INFO] %s hook %q failed: %v", execution.Trigger, execution.Command, err)
+	}
+
+	r.record(execution)
+	return err
+}
+
+func (r *Runner) record(execution *Execution) {
+	if r.Log == nil {
+		return
+	}
+
+	if execution.Time == 0 {
+		execution.Time = time.Now().Unix()
+	}
+
+	if err := r.Log.Record(execution); err != nil {
+		log.Printf("hooks: %v", err)
+	}
+}
+
+// lastLine returns the last non empty line of an output.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// limitedBuffer is a buffer keeping the first max bytes written to it.
+// It is safe for concurrent use, for the outputs to be read while the
+// killed hooks are still writing to them.
+type limitedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if room := b.max - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
 }
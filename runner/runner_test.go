@@ -0,0 +1,72 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/filebrowser/filebrowser/v2/settings"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+func TestHookTimeoutKillsChildren(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "filebrowser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	pidFile := filepath.Join(dir, "pid")
+	r := &Runner{Settings: &settings.Settings{
+		Shell: []string{"sh", "-c"},
+		Commands: map[string][]string{
+			"before_save": {"sleep 60 & echo $! > " + pidFile + "; wait"},
+		},
+		HookTimeouts: settings.HookTimeouts{Default: 1},
+	}}
+
+	start := time.Now()
+	err = r.Before("save", "/a.txt", "", &users.User{Username: "user"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("got error %v, want a timeout", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("the hook was waited for %v", elapsed)
+	}
+
+	raw, err := ioutil.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The child may still be a zombie for a moment.
+	for i := 0; i < 100; i++ {
+		if syscall.Kill(pid, 0) != nil || zombie(pid) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	_ = syscall.Kill(pid, syscall.SIGKILL)
+	t.Error("the child of the hook is still running")
+}
+
+// zombie checks if a process has exited but wasn't waited for.
+func zombie(pid int) bool {
+	stat, err := ioutil.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	return err == nil && strings.Contains(string(stat), ") Z ")
+}
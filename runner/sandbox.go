@@ -0,0 +1,148 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// sandboxVar is the environment variable the sandbox of a command is
+// given in to the process that starts it.
+const sandboxVar = "FILEBROWSER_SANDBOX"
+
+// sandboxConfig is what it takes to start a sandboxed command. Dir
+// is its working directory, inside the root of the sandbox.
+type sandboxConfig struct {
+	*users.Sandbox
+	Dir string `json:"dir"`
+}
+
+// Environ returns the environment of the server the commands inherit,
+// restricted to the allowlist of the sandbox if it is enabled.
+func Environ(sandbox *users.Sandbox) []string {
+	env := os.Environ()
+	if sandbox == nil || !sandbox.Enabled {
+		return env
+	}
+
+	allowed := sandbox.Env
+	if allowed == nil {
+		allowed = users.DefaultSandboxEnv
+	}
+
+	var kept []string
+	for _, v := range env {
+		name := strings.SplitN(v, "=", 2)[0] //nolint:mnd
+		for _, a := range allowed {
+			if name == a {
+				kept = append(kept, v)
+				break
+			}
+		}
+	}
+
+	return kept
+}
+
+// Sandbox prepares a command, whose environment and working directory
+// are set, to run in a sandbox if it is enabled. The command is then
+// started through this same binary, which limits itself before running
+// it: see ExecSandboxed.
+func Sandbox(cmd *exec.Cmd, sandbox *users.Sandbox) error {
+	if sandbox == nil || !sandbox.Enabled {
+		return nil
+	}
+
+	if err := sandbox.Check(); err != nil {
+		return err
+	}
+
+	err := isolate(cmd, sandbox)
+	if err != nil {
+		return err
+	}
+
+	// The hooks have no working directory: they are run in the one of
+	// the server, or in the root of the sandbox.
+	dir := cmd.Dir
+	switch {
+	case sandbox.Root == "" && dir == "":
+		dir = "."
+	case sandbox.Root == "":
+	case dir == "":
+		dir = string(filepath.Separator)
+	default:
+		rel, err := filepath.Rel(sandbox.Root, dir) //nolint:shadow
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return errors.ErrOutsideSandbox
+		}
+		dir = filepath.Join(string(filepath.Separator), rel)
+	}
+
+	blob, err := json.Marshal(&sandboxConfig{Sandbox: sandbox, Dir: dir})
+	if err != nil {
+		return err
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	if cmd.Env == nil {
+		cmd.Env = Environ(sandbox)
+	}
+
+	// The command is looked up again once in the root of the sandbox,
+	// from the name it was given with.
+	cmd.Env = append(cmd.Env, sandboxVar+"="+string(blob))
+	cmd.Path = self
+	return nil
+}
+
+// ExecSandboxed runs, in the process started by Sandbox, the command it
+// was started for, once the limits of the sandbox are applied, and exits
+// with its exit code. It returns right away in the other processes and
+// must be called before anything else in main.
+func ExecSandboxed() {
+	raw, ok := os.LookupEnv(sandboxVar)
+	if !ok {
+		return
+	}
+
+	code, err := execSandboxed(raw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+		os.Exit(126) //nolint:mnd
+	}
+
+	os.Exit(code)
+}
+
+func execSandboxed(raw string) (int, error) {
+	config := &sandboxConfig{}
+	err := json.Unmarshal([]byte(raw), config)
+	if err != nil {
+		return 0, err
+	}
+
+	var env []string
+	for _, v := range os.Environ() {
+		if !strings.HasPrefix(v, sandboxVar+"=") {
+			env = append(env, v)
+		}
+	}
+
+	err = restrict(config)
+	if err != nil {
+		return 0, err
+	}
+
+	return runSandboxed(config, os.Args, env)
+}
@@ -0,0 +1,24 @@
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// isolate fails if the sandbox asks for namespaces, which this
+// platform doesn't have.
+func isolate(cmd *exec.Cmd, sandbox *users.Sandbox) error {
+	if sandbox.Isolate {
+		return errors.ErrSandboxUnsupported
+	}
+
+	return nil
+}
+
+// sysProcAttr returns the attributes of the sandboxed commands.
+func sysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{}
+}
@@ -0,0 +1,32 @@
+//go:build linux
+// +build linux
+
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// isolate runs a command in new namespaces if the sandbox asks for it.
+func isolate(cmd *exec.Cmd, sandbox *users.Sandbox) error {
+	if !sandbox.Isolate {
+		return nil
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	cmd.SysProcAttr.Cloneflags |= syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+		syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS | syscall.CLONE_NEWNET
+	return nil
+}
+
+// sysProcAttr returns the attributes of the sandboxed commands, which are
+// killed along with the process waiting for them.
+func sysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Pdeathsig: syscall.SIGKILL}
+}
@@ -0,0 +1,24 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package runner
+
+import (
+	"os/exec"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// isolate fails, the sandbox not being supported on this platform.
+func isolate(cmd *exec.Cmd, sandbox *users.Sandbox) error {
+	return errors.ErrSandboxUnsupported
+}
+
+func restrict(config *sandboxConfig) error {
+	return errors.ErrSandboxUnsupported
+}
+
+func runSandboxed(config *sandboxConfig, args, env []string) (int, error) {
+	return 0, errors.ErrSandboxUnsupported
+}
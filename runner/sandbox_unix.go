@@ -0,0 +1,134 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// restrict applies the limits of a sandbox to the current process,
+// which the command inherits.
+func restrict(config *sandboxConfig) error {
+	limits := []struct {
+		resource int
+		value    uint64
+	}{
+		{syscall.RLIMIT_CPU, config.CPUTime},
+		{syscall.RLIMIT_AS, config.Memory},
+		{syscall.RLIMIT_NOFILE, config.OpenFiles},
+	}
+
+	for _, l := range limits {
+		if l.value == 0 {
+			continue
+		}
+
+		err := syscall.Setrlimit(l.resource, &syscall.Rlimit{Cur: l.value, Max: l.value})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runSandboxed runs the command in the root of the sandbox, with its
+// credentials, which are both applied to the child by the kernel when
+// it is started, and returns its exit code. The termination requests
+// are passed on to the command, the terminal signals already reaching
+// it.
+func runSandboxed(config *sandboxConfig, args, env []string) (int, error) {
+	path, err := lookPath(config, args[0], env)
+	if err != nil {
+		return 0, err
+	}
+
+	attr := sysProcAttr()
+	attr.Chroot = config.Root
+	if config.UID != 0 || config.GID != 0 {
+		attr.Credential = &syscall.Credential{
+			Uid:    config.UID,
+			Gid:    config.GID,
+			Groups: []uint32{},
+		}
+	}
+
+	cmd := &exec.Cmd{
+		Path:        path,
+		Args:        args,
+		Env:         env,
+		Dir:         config.Dir,
+		Stdin:       os.Stdin,
+		Stdout:      os.Stdout,
+		Stderr:      os.Stderr,
+		SysProcAttr: attr,
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGHUP)
+
+	err = cmd.Start()
+	if err != nil {
+		return 0, err
+	}
+
+	go func() {
+		for s := range signals {
+			if s == syscall.SIGTERM {
+				_ = cmd.Process.Signal(s)
+			}
+		}
+	}()
+
+	err = cmd.Wait()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		return 0, err
+	}
+
+	if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal()), nil //nolint:mnd
+	}
+
+	return cmd.ProcessState.ExitCode(), nil
+}
+
+// lookPath looks for a command in the PATH of its environment, as it
+// will be seen from the root of the sandbox and its working directory.
+func lookPath(config *sandboxConfig, file string, env []string) (string, error) {
+	if strings.Contains(file, "/") {
+		return file, nil
+	}
+
+	var dirs string
+	for _, v := range env {
+		if strings.HasPrefix(v, "PATH=") {
+			dirs = strings.TrimPrefix(v, "PATH=")
+		}
+	}
+
+	for _, dir := range filepath.SplitList(dirs) {
+		if dir == "" {
+			dir = "."
+		}
+
+		path := filepath.Join(dir, file)
+		host := filepath.Join(config.Root, path)
+		if !filepath.IsAbs(path) {
+			host = filepath.Join(config.Root, config.Dir, path)
+			path = "./" + path
+		}
+
+		info, err := os.Stat(host)
+		if err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0 {
+			return path, nil
+		}
+	}
+
+	return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+}
@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"time"
+)
+
+// StorageBackend is the interface to implement for a hook
+// execution log storage.
+type StorageBackend interface {
+	Save(e *Execution) error
+	Find(q *LogQuery) ([]*Execution, error)
+	DeleteBefore(time int64) (int, error)
+}
+
+// Storage is a hook execution log storage.
+type Storage struct {
+	back StorageBackend
+}
+
+// NewStorage creates a hook execution log storage from a backend.
+func NewStorage(back StorageBackend) *Storage {
+	return &Storage{back: back}
+}
+
+// Record saves an execution in the log, timestamping it
+// if it has no time.
+func (s *Storage) Record(e *Execution) error {
+	if e.Time == 0 {
+		e.Time = time.Now().Unix()
+	}
+
+	return s.back.Save(e)
+}
+
+// Find gets the executions matching the query, the newest first.
+func (s *Storage) Find(q *LogQuery) ([]*Execution, error) {
+	return s.back.Find(q)
+}
+
+// Prune deletes the executions older than the given unix time
+// and returns how many were deleted.
+func (s *Storage) Prune(before int64) (int, error) {
+	return s.back.DeleteBefore(before)
+}
@@ -0,0 +1,21 @@
+package session
+
+import "github.com/filebrowser/filebrowser/v2/expiry"
+
+// Session is a login of a user. The tokens issued to a user are only
+// valid while their session exists.
+type Session struct {
+	ID        string `json:"id" storm:"id"`
+	UserID    uint   `json:"userID" storm:"index"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"userAgent"`
+	Created   int64  `json:"created"`
+	LastSeen  int64  `json:"lastSeen"`
+	Expire    int64  `json:"expire" storm:"index"`
+}
+
+// Expired checks if the session has already expired at
+// the given unix time.
+func (s *Session) Expired(now int64) bool {
+	return expiry.Passed(s.Expire, now)
+}
@@ -0,0 +1,179 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+const (
+	idSize = 32
+
+	// lastSeenResolution is the minimum time between two updates of
+	// the last time a session was seen, to avoid a write per request.
+	lastSeenResolution = time.Minute
+)
+
+// StorageBackend is the interface to implement for a sessions storage.
+type StorageBackend interface {
+	Get(id string) (*Session, error)
+	FindByUserID(id uint) ([]*Session, error)
+	FindExpired(now int64) ([]*Session, error)
+	Save(s *Session) error
+	// Update updates some fields of a session, if it still exists,
+	// atomically. It returns errors.ErrNotExist otherwise.
+	Update(s *Session, fields ...string) error
+	Delete(id string) error
+}
+
+// Storage is a sessions storage.
+type Storage struct {
+	back StorageBackend
+}
+
+// NewStorage creates a sessions storage from a backend.
+func NewStorage(back StorageBackend) *Storage {
+	return &Storage{back: back}
+}
+
+// Create creates and saves a new session for the user.
+func (s *Storage) Create(userID uint, ip, userAgent string, expire int64) (*Session, error) {
+	b := make([]byte, idSize)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	sess := &Session{
+		ID:        base64.RawURLEncoding.EncodeToString(b),
+		UserID:    userID,
+		IP:        ip,
+		UserAgent: userAgent,
+		Created:   now,
+		LastSeen:  now,
+		Expire:    expire,
+	}
+
+	return sess, s.back.Save(sess)
+}
+
+// Get gets a session. It returns errors.ErrNotExist if the session
+// doesn't exist or has expired.
+func (s *Storage) Get(id string) (*Session, error) {
+	sess, err := s.back.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if sess.Expired(time.Now().Unix()) {
+		if err := s.back.Delete(id); err != nil {
+			return nil, err
+		}
+		return nil, errors.ErrNotExist
+	}
+
+	return sess, nil
+}
+
+// Touch records that the session was used from the given IP. It returns
+// errors.ErrNotExist if the session was deleted meanwhile, which must
+// then be considered as revoked.
+func (s *Storage) Touch(sess *Session, ip string) error {
+	now := time.Now()
+	if sess.IP == ip && now.Sub(time.Unix(sess.LastSeen, 0)) < lastSeenResolution {
+		return nil
+	}
+
+	sess.IP = ip
+	sess.LastSeen = now.Unix()
+	return s.back.Update(sess, "IP", "LastSeen")
+}
+
+// Extend sets a new expiration date to the session. Like Touch, it
+// returns errors.ErrNotExist if the session was deleted meanwhile.
+func (s *Storage) Extend(sess *Session, expire int64) error {
+	sess.Expire = expire
+	return s.back.Update(sess, "Expire")
+}
+
+// FindByUserID gets the sessions of a user that haven't expired.
+func (s *Storage) FindByUserID(id uint) ([]*Session, error) {
+	list, err := s.back.FindByUserID(id)
+	if err == errors.ErrNotExist {
+		return []*Session{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	valid := make([]*Session, 0, len(list))
+	for _, sess := range list {
+		if !sess.Expired(now) {
+			valid = append(valid, sess)
+		}
+	}
+
+	return valid, nil
+}
+
+// Delete wraps a StorageBackend.Delete.
+func (s *Storage) Delete(id string) error {
+	return s.back.Delete(id)
+}
+
+// DeleteByUserID deletes all the sessions of a user, logging
+// it out everywhere.
+func (s *Storage) DeleteByUserID(id uint) error {
+	return s.DeleteOthers(id, "")
+}
+
+// DeleteOthers deletes all the sessions of a user but the one
+// with the given ID, logging it out everywhere else.
+func (s *Storage) DeleteOthers(userID uint, keep string) error {
+	list, err := s.back.FindByUserID(userID)
+	if err == errors.ErrNotExist {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, sess := range list {
+		if sess.ID == keep {
+			continue
+		}
+
+		if err := s.back.Delete(sess.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Sweep deletes all the sessions that have already expired and
+// returns how many were deleted.
+func (s *Storage) Sweep() (int, error) {
+	list, err := s.back.FindExpired(time.Now().Unix())
+	if err == errors.ErrNotExist {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	for i, sess := range list {
+		if err := s.back.Delete(sess.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(list), nil
+}
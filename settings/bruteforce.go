@@ -0,0 +1,78 @@
+package settings
+
+import (
+	"net"
+	"net/http"
+)
+
+// BruteForce contains the brute force protection settings of the login.
+// After FreeAttempts failures, an IP or a username has to wait before
+// trying again, twice longer after each failure, up to MaxDelay seconds.
+// After LockoutAttempts consecutive failures, an account is locked for
+// LockoutDuration seconds. The IP of a client is the address it connects
+// from, unless it connects from one of the TrustedProxies, which are IPs
+// or CIDR ranges: the X-Forwarded-For and X-Real-IP headers they set are
+// used then, as well as X-Forwarded-Proto.
+type BruteForce struct {
+	Disabled        bool     `json:"disabled"`
+	FreeAttempts    int      `json:"freeAttempts"`
+	MaxDelay        int64    `json:"maxDelay"`
+	LockoutAttempts int      `json:"lockoutAttempts"`
+	LockoutDuration int64    `json:"lockoutDuration"`
+	TrustedProxies  []string `json:"trustedProxies"`
+}
+
+// DefaultBruteForce is the brute force protection used for
+// the settings that aren't set.
+var DefaultBruteForce = BruteForce{
+	FreeAttempts:    5,
+	MaxDelay:        15 * 60,
+	LockoutAttempts: 10,
+	LockoutDuration: 15 * 60,
+}
+
+// Clean sets the default values to the settings that aren't set.
+func (b *BruteForce) Clean() {
+	if b.FreeAttempts <= 0 {
+		b.FreeAttempts = DefaultBruteForce.FreeAttempts
+	}
+
+	if b.MaxDelay <= 0 {
+		b.MaxDelay = DefaultBruteForce.MaxDelay
+	}
+
+	if b.LockoutAttempts <= 0 {
+		b.LockoutAttempts = DefaultBruteForce.LockoutAttempts
+	}
+
+	if b.LockoutDuration <= 0 {
+		b.LockoutDuration = DefaultBruteForce.LockoutDuration
+	}
+}
+
+// Trusted checks if the given IP is the one of a trusted proxy.
+func (b *BruteForce) Trusted(ip net.IP) bool {
+	for _, proxy := range b.TrustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if net.ParseIP(proxy).Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TrustedRequest checks if a request comes from a trusted proxy, whose
+// headers can be used.
+func (b *BruteForce) TrustedRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && b.Trusted(ip)
+}
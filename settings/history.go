@@ -0,0 +1,35 @@
+package settings
+
+// CommandHistory contains the retention settings of the history of the
+// commands run by the users. The output of each run is truncated to
+// MaxOutput bytes, the runs older than MaxAge seconds are deleted and
+// only the MaxRuns newest runs of each user are kept.
+type CommandHistory struct {
+	Disabled  bool  `json:"disabled"`
+	MaxOutput int64 `json:"maxOutput"`
+	MaxAge    int64 `json:"maxAge"`
+	MaxRuns   int   `json:"maxRuns"`
+}
+
+// DefaultCommandHistory is the retention used for the
+// settings that aren't set.
+var DefaultCommandHistory = CommandHistory{
+	MaxOutput: 256 * 1024,
+	MaxAge:    30 * 24 * 60 * 60,
+	MaxRuns:   100,
+}
+
+// Clean sets the default values to the settings that aren't set.
+func (h *CommandHistory) Clean() {
+	if h.MaxOutput <= 0 {
+		h.MaxOutput = DefaultCommandHistory.MaxOutput
+	}
+
+	if h.MaxAge <= 0 {
+		h.MaxAge = DefaultCommandHistory.MaxAge
+	}
+
+	if h.MaxRuns <= 0 {
+		h.MaxRuns = DefaultCommandHistory.MaxRuns
+	}
+}
@@ -0,0 +1,52 @@
+package settings
+
+import (
+	"time"
+)
+
+// HookTimeouts contains the number of seconds the hooks can run for
+// before being killed, zero meaning no limit. Triggers overrides Default
+// for the hooks of some triggers, such as before_upload. The non-blocking
+// hooks, run in the background, are only limited by the timeout of their
+// trigger.
+type HookTimeouts struct {
+	Default  int64            `json:"default"`
+	Triggers map[string]int64 `json:"triggers"`
+}
+
+// Clean sets the default values to the settings that aren't set.
+func (h *HookTimeouts) Clean() {
+	if h.Default < 0 {
+		h.Default = 0
+	}
+
+	if h.Triggers == nil {
+		h.Triggers = map[string]int64{}
+	}
+}
+
+// For returns the timeout of the blocking hooks of a trigger, zero
+// meaning no timeout.
+func (h *HookTimeouts) For(trigger string) time.Duration {
+	seconds, ok := h.Triggers[trigger]
+	if !ok || seconds <= 0 {
+		seconds = h.Default
+	}
+
+	if seconds <= 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// ForNonBlocking returns the timeout of the non-blocking hooks of a
+// trigger, zero meaning no timeout.
+func (h *HookTimeouts) ForNonBlocking(trigger string) time.Duration {
+	seconds := h.Triggers[trigger]
+	if seconds <= 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
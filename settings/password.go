@@ -0,0 +1,93 @@
+package settings
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+// PasswordPolicy contains the rules a new password must follow. MaxAge
+// is the number of seconds after which a password expires and has to
+// be changed, zero meaning it never expires.
+type PasswordPolicy struct {
+	MinLength     int      `json:"minLength"`
+	RequireUpper  bool     `json:"requireUpper"`
+	RequireLower  bool     `json:"requireLower"`
+	RequireDigit  bool     `json:"requireDigit"`
+	RequireSymbol bool     `json:"requireSymbol"`
+	DenyCommon    bool     `json:"denyCommon"`
+	Denylist      []string `json:"denylist"`
+	MaxAge        int64    `json:"maxAge"`
+}
+
+// commonPasswords is a list of the most used passwords, which are
+// refused when DenyCommon is set.
+var commonPasswords = []string{
+	"123456", "123456789", "12345678", "12345", "1234567", "1234567890",
+	"123123", "111111", "000000", "654321", "666666", "121212", "112233",
+	"123321", "1q2w3e4r", "1q2w3e", "qwerty", "qwerty123", "qwertyuiop",
+	"asdfgh", "asdfghjkl", "zxcvbnm", "password", "password1", "password123",
+	"passw0rd", "p@ssw0rd", "abc123", "admin", "admin123", "administrator",
+	"root", "toor", "letmein", "welcome", "welcome1", "monkey", "dragon",
+	"master", "login", "princess", "sunshine", "football", "baseball",
+	"iloveyou", "trustno1", "superman", "batman", "shadow", "michael",
+	"secret", "changeme", "default", "guest", "test", "test123", "filebrowser",
+}
+
+// Check returns an error if the password of the given user
+// doesn't comply with the policy.
+func (p *PasswordPolicy) Check(username, password string) error {
+	if password == "" {
+		return errors.ErrEmptyPassword
+	}
+
+	if len([]rune(password)) < p.MinLength {
+		return errors.ErrPasswordTooShort
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	if (p.RequireUpper && !upper) || (p.RequireLower && !lower) ||
+		(p.RequireDigit && !digit) || (p.RequireSymbol && !symbol) {
+		return errors.ErrPasswordTooWeak
+	}
+
+	if username != "" && strings.EqualFold(password, username) {
+		return errors.ErrPasswordDenied
+	}
+
+	for _, denied := range p.Denylist {
+		if strings.EqualFold(password, denied) {
+			return errors.ErrPasswordDenied
+		}
+	}
+
+	if p.DenyCommon {
+		for _, denied := range commonPasswords {
+			if strings.EqualFold(password, denied) {
+				return errors.ErrPasswordDenied
+			}
+		}
+	}
+
+	return nil
+}
+
+// Expired returns whether a password changed at the given
+// unix time has expired.
+func (p *PasswordPolicy) Expired(changed, now int64) bool {
+	return p.MaxAge > 0 && changed+p.MaxAge <= now
+}
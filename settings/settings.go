@@ -7,6 +7,10 @@ import (
 	"github.com/filebrowser/filebrowser/v2/rules"
 )
 
+// DefaultMaxTextSize is the size, in bytes, above which the text files
+// are only read by parts when it isn't set.
+const DefaultMaxTextSize = 10 * 1024 * 1024
+
 // AuthMethod describes an authentication method.
 type AuthMethod string
 
@@ -21,6 +25,17 @@ type Settings struct {
 	Commands      map[string][]string `json:"commands"`
 	Shell         []string            `json:"shell"`
 	Rules         []rules.Rule        `json:"rules"`
+	RequireTOTP   bool                `json:"requireTOTP"`
+	BruteForce    BruteForce          `json:"bruteForce"`
+	Password      PasswordPolicy      `json:"password"`
+	HookTimeouts  HookTimeouts        `json:"hookTimeouts"`
+	History       CommandHistory      `json:"history"`
+	// MaxTextSize is the size, in bytes, above which the text files can't
+	// be edited and are only read by parts.
+	MaxTextSize int64 `json:"maxTextSize"`
+	// ForceHideDotfiles hides the dotfiles to every user,
+	// whatever their preference.
+	ForceHideDotfiles bool `json:"forceHideDotfiles"`
 }
 
 // GetRules implements rules.Provider.
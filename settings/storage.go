@@ -35,6 +35,15 @@ var defaultEvents = []string{
 	"rename",
 	"upload",
 	"delete",
+	"mkdir",
+	"download",
+	"share",
+	"unshare",
+	"share_access",
+	"login",
+	"login_failed",
+	"user_create",
+	"user_delete",
 }
 
 // Save saves the settings for the current instance.
@@ -63,6 +72,18 @@ func (s *Storage) Save(set *Settings) error {
 		set.Shell = []string{}
 	}
 
+	set.BruteForce.Clean()
+	set.HookTimeouts.Clean()
+	set.History.Clean()
+
+	if set.MaxTextSize <= 0 {
+		set.MaxTextSize = DefaultMaxTextSize
+	}
+
+	if set.Password.Denylist == nil {
+		set.Password.Denylist = []string{}
+	}
+
 	if set.Commands == nil {
 		set.Commands = map[string][]string{}
 	}
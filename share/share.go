@@ -1,9 +1,17 @@
 package share
 
+import "github.com/filebrowser/filebrowser/v2/expiry"
+
 // Link is the information needed to build a shareable link.
 type Link struct {
 	Hash   string `json:"hash" storm:"id,index"`
 	Path   string `json:"path" storm:"index"`
-	UserID uint   `json:"userID"`
-	Expire int64  `json:"expire"`
+	UserID uint   `json:"userID" storm:"index"`
+	Expire int64  `json:"expire" storm:"index"`
+}
+
+// Expired checks if the link has an expiration date that has
+// already passed at the given unix time.
+func (l *Link) Expired(now int64) bool {
+	return expiry.Passed(l.Expire, now)
 }
@@ -11,6 +11,8 @@ type StorageBackend interface {
 	GetByHash(hash string) (*Link, error)
 	GetPermanent(path string, id uint) (*Link, error)
 	Gets(path string, id uint) ([]*Link, error)
+	FindByUserID(id uint) ([]*Link, error)
+	FindExpired(now int64) ([]*Link, error)
 	Save(s *Link) error
 	Delete(hash string) error
 }
@@ -32,7 +34,7 @@ func (s *Storage) GetByHash(hash string) (*Link, error) {
 		return nil, err
 	}
 
-	if link.Expire != 0 && link.Expire <= time.Now().Unix() {
+	if link.Expired(time.Now().Unix()) {
 		if err := s.Delete(link.Hash); err != nil {
 			return nil, err
 		}
@@ -50,21 +52,62 @@ func (s *Storage) GetPermanent(path string, id uint) (*Link, error) {
 // Gets wraps a StorageBackend.Gets
 func (s *Storage) Gets(path string, id uint) ([]*Link, error) {
 	links, err := s.back.Gets(path, id)
+	if err != nil {
+		return nil, err
+	}
 
+	return s.removeExpired(links)
+}
+
+// FindByUserID wraps a StorageBackend.FindByUserID
+func (s *Storage) FindByUserID(id uint) ([]*Link, error) {
+	links, err := s.back.FindByUserID(id)
 	if err != nil {
 		return nil, err
 	}
 
+	return s.removeExpired(links)
+}
+
+// removeExpired deletes the expired links from the storage and returns
+// the ones that are still valid.
+func (s *Storage) removeExpired(links []*Link) ([]*Link, error) {
+	now := time.Now().Unix()
+	valid := make([]*Link, 0, len(links))
+
+	for _, link := range links {
+		if !link.Expired(now) {
+			valid = append(valid, link)
+			continue
+		}
+
+		if err := s.Delete(link.Hash); err != nil {
+			return nil, err
+		}
+	}
+
+	return valid, nil
+}
+
+// Sweep deletes all the links that have already expired and
+// returns how many were deleted.
+func (s *Storage) Sweep() (int, error) {
+	links, err := s.back.FindExpired(time.Now().Unix())
+	if err == errors.ErrNotExist {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
 	for i, link := range links {
-		if link.Expire != 0 && link.Expire <= time.Now().Unix() {
-			if err := s.Delete(link.Hash); err != nil {
-				return nil, err
-			}
-			links = append(links[:i], links[i+1:]...)
+		if err := s.Delete(link.Hash); err != nil {
+			return i, err
 		}
 	}
 
-	return links, nil
+	return len(links), nil
 }
 
 // Save wraps a StorageBackend.Save
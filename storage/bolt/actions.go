@@ -0,0 +1,58 @@
+package bolt
+
+import (
+	"github.com/asdine/storm"
+
+	"github.com/filebrowser/filebrowser/v2/actions"
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+type actionsBackend struct {
+	db *storm.DB
+}
+
+func (st actionsBackend) GetBy(i interface{}) (*actions.Action, error) {
+	var arg string
+	switch i.(type) {
+	case uint:
+		arg = "ID"
+	case string:
+		arg = "Name"
+	default:
+		return nil, errors.ErrInvalidDataType
+	}
+
+	a := &actions.Action{}
+	err := st.db.One(arg, i, a)
+	if err == storm.ErrNotFound {
+		return nil, errors.ErrNotExist
+	}
+
+	return a, err
+}
+
+func (st actionsBackend) Gets() ([]*actions.Action, error) {
+	var v []*actions.Action
+	err := st.db.All(&v)
+	if err == storm.ErrNotFound {
+		return v, errors.ErrNotExist
+	}
+
+	return v, err
+}
+
+func (st actionsBackend) Save(a *actions.Action) error {
+	err := st.db.Save(a)
+	if err == storm.ErrAlreadyExists {
+		return errors.ErrExist
+	}
+	return err
+}
+
+func (st actionsBackend) DeleteByID(id uint) error {
+	err := st.db.DeleteStruct(&actions.Action{ID: id})
+	if err == storm.ErrNotFound {
+		return errors.ErrNotExist
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package bolt
+
+import (
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	"github.com/filebrowser/filebrowser/v2/audit"
+)
+
+type auditBackend struct {
+	db *storm.DB
+}
+
+func (s auditBackend) Save(e *audit.Entry) error {
+	return s.db.Save(e)
+}
+
+func (s auditBackend) Find(query *audit.Query) ([]*audit.Entry, error) {
+	sel := s.db.Select(queryMatcher{query}).OrderBy("ID").Reverse()
+	if query.Offset > 0 {
+		sel = sel.Skip(query.Offset)
+	}
+
+	if query.Limit > 0 {
+		sel = sel.Limit(query.Limit)
+	}
+
+	v := []*audit.Entry{}
+	err := sel.Find(&v)
+	if err == storm.ErrNotFound {
+		return []*audit.Entry{}, nil
+	}
+
+	return v, err
+}
+
+func (s auditBackend) DeleteBefore(time int64) (int, error) {
+	sel := s.db.Select(q.Lt("Time", time))
+
+	count, err := sel.Count(&audit.Entry{})
+	if err != nil || count == 0 {
+		return 0, err
+	}
+
+	return count, sel.Delete(&audit.Entry{})
+}
+
+// queryMatcher matches the entries of the audit log against a query.
+type queryMatcher struct {
+	query *audit.Query
+}
+
+// Match implements q.Matcher. Storm gives the entries
+// by value to the matchers.
+func (m queryMatcher) Match(i interface{}) (bool, error) {
+	switch e := i.(type) {
+	case audit.Entry:
+		return m.query.Matches(&e), nil
+	case *audit.Entry:
+		return m.query.Matches(e), nil
+	default:
+		return false, nil
+	}
+}
@@ -1,23 +1,47 @@
 package bolt
 
 import (
+	"time"
+
 	"github.com/asdine/storm"
 
+	"github.com/filebrowser/filebrowser/v2/actions"
+	"github.com/filebrowser/filebrowser/v2/audit"
 	"github.com/filebrowser/filebrowser/v2/auth"
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/groups"
+	"github.com/filebrowser/filebrowser/v2/history"
+	"github.com/filebrowser/filebrowser/v2/locks"
+	"github.com/filebrowser/filebrowser/v2/runner"
+	"github.com/filebrowser/filebrowser/v2/session"
 	"github.com/filebrowser/filebrowser/v2/settings"
 	"github.com/filebrowser/filebrowser/v2/share"
 	"github.com/filebrowser/filebrowser/v2/storage"
+	"github.com/filebrowser/filebrowser/v2/tokens"
 	"github.com/filebrowser/filebrowser/v2/users"
+	"github.com/filebrowser/filebrowser/v2/webhooks"
 )
 
+// version is the current version of the database layout.
+const version = 9
+
 // NewStorage creates a storage.Storage based on Bolt DB.
 func NewStorage(db *storm.DB) (*storage.Storage, error) {
 	userStore := users.NewStorage(usersBackend{db: db})
 	shareStore := share.NewStorage(shareBackend{db: db})
 	settingsStore := settings.NewStorage(settingsBackend{db: db})
 	authStore := auth.NewStorage(authBackend{db: db}, userStore)
+	tokensStore := tokens.NewStorage(tokensBackend{db: db})
+	sessionStore := session.NewStorage(sessionBackend{db: db})
+	auditStore := audit.NewStorage(auditBackend{db: db})
+	groupsStore := groups.NewStorage(groupsBackend{db: db})
+	webhooksStore := webhooks.NewStorage(webhooksBackend{db: db})
+	hooksStore := runner.NewStorage(hooksBackend{db: db})
+	actionsStore := actions.NewStorage(actionsBackend{db: db})
+	historyStore := history.NewStorage(historyBackend{db: db})
+	locksStore := locks.NewStorage(locksBackend{db: db})
 
-	err := save(db, "version", 2)
+	err := upgrade(db, settingsStore, userStore)
 	if err != nil {
 		return nil, err
 	}
@@ -27,5 +51,101 @@ func NewStorage(db *storm.DB) (*storage.Storage, error) {
 		Users:    userStore,
 		Share:    shareStore,
 		Settings: settingsStore,
+		Tokens:   tokensStore,
+		Sessions: sessionStore,
+		Audit:    auditStore,
+		Groups:   groupsStore,
+		Webhooks: webhooksStore,
+		Hooks:    hooksStore,
+		Actions:  actionsStore,
+		History:  historyStore,
+		Locks:    locksStore,
 	}, nil
 }
+
+// upgrade brings databases created by older versions up to date.
+func upgrade(db *storm.DB, settingsStore *settings.Storage, userStore *users.Storage) error {
+	var current int
+	err := get(db, "version", &current)
+	if err != nil && err != errors.ErrNotExist {
+		return err
+	}
+
+	if current == version {
+		return nil
+	}
+
+	// Version 3 added indexes to the share links.
+	if current < 3 { //nolint:mnd
+		err = db.Init(&share.Link{})
+		if err != nil {
+			return err
+		}
+
+		err = db.ReIndex(&share.Link{})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Version 4 added the brute force protection settings, whose
+	// defaults are set when saving the settings.
+	if current < 4 { //nolint:mnd
+		set, err := settingsStore.Get() //nolint:shadow
+		if err == nil {
+			err = settingsStore.Save(set)
+		}
+
+		if err != nil && err != errors.ErrNotExist {
+			return err
+		}
+	}
+
+	// Version 5 added the password policy and expiry. The age of the
+	// existing passwords is unknown, so they are considered as new.
+	if current < 5 { //nolint:mnd
+		set, err := settingsStore.Get() //nolint:shadow
+		if err == nil {
+			err = settingsStore.Save(set)
+		}
+
+		if err != nil && err != errors.ErrNotExist {
+			return err
+		}
+
+		all, err := userStore.Gets("")
+		if err != nil && err != errors.ErrNotExist {
+			return err
+		}
+
+		now := time.Now().Unix()
+		for _, user := range all {
+			if user.PasswordChanged != 0 {
+				continue
+			}
+
+			user.PasswordChanged = now
+			err = userStore.Update(user, "PasswordChanged")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Version 6 added the timeouts of the hooks, version 7 the events
+	// of the hooks, version 8 the retention of the command history and
+	// version 9 the maximum size of the text files, which are set when
+	// saving the settings.
+	if current < 9 { //nolint:mnd
+		set, err := settingsStore.Get() //nolint:shadow
+		if err == nil {
+			err = settingsStore.Save(set)
+		}
+
+		if err != nil && err != errors.ErrNotExist {
+			return err
+		}
+	}
+
+	return save(db, "version", version)
+}
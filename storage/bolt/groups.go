@@ -0,0 +1,58 @@
+package bolt
+
+import (
+	"github.com/asdine/storm"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/groups"
+)
+
+type groupsBackend struct {
+	db *storm.DB
+}
+
+func (st groupsBackend) GetBy(i interface{}) (*groups.Group, error) {
+	var arg string
+	switch i.(type) {
+	case uint:
+		arg = "ID"
+	case string:
+		arg = "Name"
+	default:
+		return nil, errors.ErrInvalidDataType
+	}
+
+	g := &groups.Group{}
+	err := st.db.One(arg, i, g)
+	if err == storm.ErrNotFound {
+		return nil, errors.ErrNotExist
+	}
+
+	return g, err
+}
+
+func (st groupsBackend) Gets() ([]*groups.Group, error) {
+	var v []*groups.Group
+	err := st.db.All(&v)
+	if err == storm.ErrNotFound {
+		return v, errors.ErrNotExist
+	}
+
+	return v, err
+}
+
+func (st groupsBackend) Save(g *groups.Group) error {
+	err := st.db.Save(g)
+	if err == storm.ErrAlreadyExists {
+		return errors.ErrExist
+	}
+	return err
+}
+
+func (st groupsBackend) DeleteByID(id uint) error {
+	err := st.db.DeleteStruct(&groups.Group{ID: id})
+	if err == storm.ErrNotFound {
+		return errors.ErrNotExist
+	}
+	return err
+}
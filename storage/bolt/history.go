@@ -0,0 +1,90 @@
+package bolt
+
+import (
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/history"
+)
+
+type historyBackend struct {
+	db *storm.DB
+}
+
+func (s historyBackend) Save(r *history.Run) error {
+	return s.db.Save(r)
+}
+
+func (s historyBackend) Get(id uint) (*history.Run, error) {
+	r := &history.Run{}
+	err := s.db.One("ID", id, r)
+	if err == storm.ErrNotFound {
+		return nil, errors.ErrNotExist
+	}
+
+	return r, err
+}
+
+func (s historyBackend) Find(query *history.Query) ([]*history.Run, error) {
+	var matchers []q.Matcher
+	if query.UserID != 0 {
+		matchers = append(matchers, q.Eq("UserID", query.UserID))
+	}
+
+	sel := s.db.Select(matchers...).OrderBy("ID").Reverse()
+	if query.Offset > 0 {
+		sel = sel.Skip(query.Offset)
+	}
+
+	if query.Limit > 0 {
+		sel = sel.Limit(query.Limit)
+	}
+
+	v := []*history.Run{}
+	err := sel.Find(&v)
+	if err == storm.ErrNotFound {
+		return []*history.Run{}, nil
+	}
+
+	return v, err
+}
+
+func (s historyBackend) Delete(id uint) error {
+	err := s.db.DeleteStruct(&history.Run{ID: id})
+	if err == storm.ErrNotFound {
+		return errors.ErrNotExist
+	}
+
+	return err
+}
+
+func (s historyBackend) DeleteBefore(time int64) (int, error) {
+	sel := s.db.Select(q.Lt("Start", time))
+
+	count, err := sel.Count(&history.Run{})
+	if err != nil || count == 0 {
+		return 0, err
+	}
+
+	return count, sel.Delete(&history.Run{})
+}
+
+func (s historyBackend) Trim(userID uint, keep int) error {
+	var old []*history.Run
+	err := s.db.Select(q.Eq("UserID", userID)).OrderBy("ID").Reverse().Skip(keep).Find(&old)
+	if err == storm.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, r := range old {
+		err = s.db.DeleteStruct(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
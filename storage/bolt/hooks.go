@@ -0,0 +1,63 @@
+package bolt
+
+import (
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	"github.com/filebrowser/filebrowser/v2/runner"
+)
+
+type hooksBackend struct {
+	db *storm.DB
+}
+
+func (s hooksBackend) Save(e *runner.Execution) error {
+	return s.db.Save(e)
+}
+
+func (s hooksBackend) Find(query *runner.LogQuery) ([]*runner.Execution, error) {
+	sel := s.db.Select(logQueryMatcher{query}).OrderBy("ID").Reverse()
+	if query.Offset > 0 {
+		sel = sel.Skip(query.Offset)
+	}
+
+	if query.Limit > 0 {
+		sel = sel.Limit(query.Limit)
+	}
+
+	v := []*runner.Execution{}
+	err := sel.Find(&v)
+	if err == storm.ErrNotFound {
+		return []*runner.Execution{}, nil
+	}
+
+	return v, err
+}
+
+func (s hooksBackend) DeleteBefore(time int64) (int, error) {
+	sel := s.db.Select(q.Lt("Time", time))
+
+	count, err := sel.Count(&runner.Execution{})
+	if err != nil || count == 0 {
+		return 0, err
+	}
+
+	return count, sel.Delete(&runner.Execution{})
+}
+
+// logQueryMatcher matches the hook executions against a query.
+type logQueryMatcher struct {
+	query *runner.LogQuery
+}
+
+// Match implements q.Matcher.
+func (m logQueryMatcher) Match(i interface{}) (bool, error) {
+	switch e := i.(type) {
+	case runner.Execution:
+		return m.query.Matches(&e), nil
+	case *runner.Execution:
+		return m.query.Matches(e), nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,94 @@
+package bolt
+
+import (
+	"reflect"
+
+	"github.com/asdine/storm"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/locks"
+)
+
+type locksBackend struct {
+	db *storm.DB
+}
+
+func (s locksBackend) Get(token string) (*locks.Lock, error) {
+	var v locks.Lock
+	err := s.db.One("Token", token, &v)
+	if err == storm.ErrNotFound {
+		return nil, errors.ErrNotExist
+	}
+
+	return &v, err
+}
+
+func (s locksBackend) GetByPath(path string) (*locks.Lock, error) {
+	var v locks.Lock
+	err := s.db.One("Path", path, &v)
+	if err == storm.ErrNotFound {
+		return nil, errors.ErrNotExist
+	}
+
+	return &v, err
+}
+
+func (s locksBackend) Gets() ([]*locks.Lock, error) {
+	var v []*locks.Lock
+	err := s.db.All(&v)
+	if err == storm.ErrNotFound {
+		return v, errors.ErrNotExist
+	}
+
+	return v, err
+}
+
+func (s locksBackend) FindExpired(now int64) ([]*locks.Lock, error) {
+	var v []*locks.Lock
+	err := s.db.Range("Expire", int64(0), now, &v)
+	if err == storm.ErrNotFound {
+		return v, errors.ErrNotExist
+	}
+
+	return v, err
+}
+
+func (s locksBackend) Save(l *locks.Lock) error {
+	return s.db.Save(l)
+}
+
+func (s locksBackend) Update(l *locks.Lock, fields ...string) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var current locks.Lock
+	err = tx.One("Token", l.Token, &current)
+	if err == storm.ErrNotFound {
+		return errors.ErrNotExist
+	} else if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		val := reflect.ValueOf(l).Elem().FieldByName(field)
+		reflect.ValueOf(&current).Elem().FieldByName(field).Set(val)
+	}
+
+	err = tx.Save(&current)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s locksBackend) Delete(token string) error {
+	err := s.db.DeleteStruct(&locks.Lock{Token: token})
+	if err == storm.ErrNotFound {
+		return nil
+	}
+	return err
+}
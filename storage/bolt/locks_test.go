@@ -0,0 +1,79 @@
+package bolt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asdine/storm"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/locks"
+)
+
+func newTestLocks(t *testing.T) *locks.Storage {
+	dir, err := ioutil.TempDir(os.TempDir(), "filebrowser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	db, err := storm.Open(filepath.Join(dir, "database.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return locks.NewStorage(locksBackend{db: db})
+}
+
+func TestLockRefreshAfterDelete(t *testing.T) {
+	store := newTestLocks(t)
+
+	l, err := store.Acquire("/srv/a.txt", 1, "user", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A refresh read the lock before an administrator released it.
+	read, err := store.GetByPath("/srv/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.Delete(l.Token); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.Refresh(read, 120); err != errors.ErrNotExist {
+		t.Errorf("refresh: got error %v, want %v", err, errors.ErrNotExist)
+	}
+
+	if _, err = store.GetByPath("/srv/a.txt"); err != errors.ErrNotExist {
+		t.Errorf("the released lock is back: got error %v", err)
+	}
+}
+
+func TestLockAcquireAgain(t *testing.T) {
+	store := newTestLocks(t)
+
+	l, err := store.Acquire("/srv/a.txt", 1, "user", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := store.Acquire("/srv/a.txt", 1, "user", 120)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again.Token != l.Token || again.Timeout != 120 {
+		t.Errorf("the lock wasn't refreshed: %+v", again)
+	}
+
+	other, err := store.Acquire("/srv/a.txt", 2, "other", 60)
+	if err != errors.ErrLocked || other.Token != l.Token {
+		t.Errorf("got lock %+v and error %v, want %v", other, err, errors.ErrLocked)
+	}
+}
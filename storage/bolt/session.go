@@ -0,0 +1,84 @@
+package bolt
+
+import (
+	"reflect"
+
+	"github.com/asdine/storm"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/session"
+)
+
+type sessionBackend struct {
+	db *storm.DB
+}
+
+func (s sessionBackend) Get(id string) (*session.Session, error) {
+	var v session.Session
+	err := s.db.One("ID", id, &v)
+	if err == storm.ErrNotFound {
+		return nil, errors.ErrNotExist
+	}
+
+	return &v, err
+}
+
+func (s sessionBackend) FindByUserID(id uint) ([]*session.Session, error) {
+	var v []*session.Session
+	err := s.db.Find("UserID", id, &v)
+	if err == storm.ErrNotFound {
+		return v, errors.ErrNotExist
+	}
+
+	return v, err
+}
+
+func (s sessionBackend) FindExpired(now int64) ([]*session.Session, error) {
+	var v []*session.Session
+	err := s.db.Range("Expire", int64(0), now, &v)
+	if err == storm.ErrNotFound {
+		return v, errors.ErrNotExist
+	}
+
+	return v, err
+}
+
+func (s sessionBackend) Save(sess *session.Session) error {
+	return s.db.Save(sess)
+}
+
+func (s sessionBackend) Update(sess *session.Session, fields ...string) error {
+	tx, err := s.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var current session.Session
+	err = tx.One("ID", sess.ID, &current)
+	if err == storm.ErrNotFound {
+		return errors.ErrNotExist
+	} else if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		val := reflect.ValueOf(sess).Elem().FieldByName(field)
+		reflect.ValueOf(&current).Elem().FieldByName(field).Set(val)
+	}
+
+	err = tx.Save(&current)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s sessionBackend) Delete(id string) error {
+	err := s.db.DeleteStruct(&session.Session{ID: id})
+	if err == storm.ErrNotFound {
+		return nil
+	}
+	return err
+}
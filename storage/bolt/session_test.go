@@ -0,0 +1,84 @@
+package bolt
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/asdine/storm"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/session"
+)
+
+func newTestSessions(t *testing.T) *session.Storage {
+	dir, err := ioutil.TempDir(os.TempDir(), "filebrowser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	db, err := storm.Open(filepath.Join(dir, "database.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return session.NewStorage(sessionBackend{db: db})
+}
+
+func TestSessionTouchAfterDelete(t *testing.T) {
+	store := newTestSessions(t)
+	expire := time.Now().Add(time.Hour).Unix()
+
+	sess, err := store.Create(1, "10.0.0.1", "tests", expire)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A request read the session before it was revoked.
+	read, err := store.Get(sess.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.DeleteByUserID(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.Touch(read, "10.0.0.2"); err != errors.ErrNotExist {
+		t.Errorf("touch: got error %v, want %v", err, errors.ErrNotExist)
+	}
+
+	if err = store.Extend(read, expire+60); err != errors.ErrNotExist {
+		t.Errorf("extend: got error %v, want %v", err, errors.ErrNotExist)
+	}
+
+	if _, err = store.Get(sess.ID); err != errors.ErrNotExist {
+		t.Errorf("the revoked session is back: got error %v", err)
+	}
+}
+
+func TestSessionTouch(t *testing.T) {
+	store := newTestSessions(t)
+
+	sess, err := store.Create(1, "10.0.0.1", "tests", time.Now().Add(time.Hour).Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = store.Touch(sess, "10.0.0.2"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Get(sess.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.IP != "10.0.0.2" || got.UserAgent != "tests" || got.Expire != sess.Expire {
+		t.Errorf("unexpected session %+v", got)
+	}
+}
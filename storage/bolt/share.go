@@ -2,7 +2,6 @@ package bolt
 
 import (
 	"github.com/asdine/storm"
-	"github.com/asdine/storm/q"
 
 	"github.com/filebrowser/filebrowser/v2/errors"
 	"github.com/filebrowser/filebrowser/v2/share"
@@ -23,18 +22,54 @@ func (s shareBackend) GetByHash(hash string) (*share.Link, error) {
 }
 
 func (s shareBackend) GetPermanent(path string, id uint) (*share.Link, error) {
-	var v share.Link
-	err := s.db.Select(q.Eq("Path", path), q.Eq("Expire", 0), q.Eq("UserID", id)).First(&v)
-	if err == storm.ErrNotFound {
-		return nil, errors.ErrNotExist
+	links, err := s.Gets(path, id)
+	if err != nil {
+		return nil, err
 	}
 
-	return &v, err
+	for _, link := range links {
+		if link.Expire == 0 {
+			return link, nil
+		}
+	}
+
+	return nil, errors.ErrNotExist
 }
 
 func (s shareBackend) Gets(path string, id uint) ([]*share.Link, error) {
+	links, err := s.FindByUserID(id)
+	if err != nil {
+		return links, err
+	}
+
+	var v []*share.Link
+	for _, link := range links {
+		if link.Path == path {
+			v = append(v, link)
+		}
+	}
+
+	if len(v) == 0 {
+		return v, errors.ErrNotExist
+	}
+
+	return v, nil
+}
+
+func (s shareBackend) FindByUserID(id uint) ([]*share.Link, error) {
+	var v []*share.Link
+	err := s.db.Find("UserID", id, &v)
+	if err == storm.ErrNotFound {
+		return v, errors.ErrNotExist
+	}
+
+	return v, err
+}
+
+func (s shareBackend) FindExpired(now int64) ([]*share.Link, error) {
 	var v []*share.Link
-	err := s.db.Select(q.Eq("Path", path), q.Eq("UserID", id)).Find(&v)
+	// Links with Expire == 0 never expire, so the range starts at 1.
+	err := s.db.Range("Expire", int64(1), now, &v)
 	if err == storm.ErrNotFound {
 		return v, errors.ErrNotExist
 	}
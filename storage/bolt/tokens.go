@@ -0,0 +1,58 @@
+package bolt
+
+import (
+	"github.com/asdine/storm"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/tokens"
+)
+
+type tokensBackend struct {
+	db *storm.DB
+}
+
+func (s tokensBackend) GetByID(id uint) (*tokens.Token, error) {
+	var v tokens.Token
+	err := s.db.One("ID", id, &v)
+	if err == storm.ErrNotFound {
+		return nil, errors.ErrNotExist
+	}
+
+	return &v, err
+}
+
+func (s tokensBackend) GetByHash(hash string) (*tokens.Token, error) {
+	var v tokens.Token
+	err := s.db.One("Hash", hash, &v)
+	if err == storm.ErrNotFound {
+		return nil, errors.ErrNotExist
+	}
+
+	return &v, err
+}
+
+func (s tokensBackend) FindByUserID(id uint) ([]*tokens.Token, error) {
+	var v []*tokens.Token
+	err := s.db.Find("UserID", id, &v)
+	if err == storm.ErrNotFound {
+		return v, errors.ErrNotExist
+	}
+
+	return v, err
+}
+
+func (s tokensBackend) Save(t *tokens.Token) error {
+	return s.db.Save(t)
+}
+
+func (s tokensBackend) UpdateLastUsed(t *tokens.Token) error {
+	return s.db.UpdateField(t, "LastUsed", t.LastUsed)
+}
+
+func (s tokensBackend) Delete(id uint) error {
+	err := s.db.DeleteStruct(&tokens.Token{ID: id})
+	if err == storm.ErrNotFound {
+		return errors.ErrNotExist
+	}
+	return err
+}
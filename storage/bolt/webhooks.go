@@ -0,0 +1,74 @@
+package bolt
+
+import (
+	"github.com/asdine/storm"
+	"github.com/asdine/storm/q"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/webhooks"
+)
+
+type webhooksBackend struct {
+	db *storm.DB
+}
+
+func (s webhooksBackend) Get(id uint) (*webhooks.Webhook, error) {
+	var v webhooks.Webhook
+	err := s.db.One("ID", id, &v)
+	if err == storm.ErrNotFound {
+		return nil, errors.ErrNotExist
+	}
+
+	return &v, err
+}
+
+func (s webhooksBackend) Gets() ([]*webhooks.Webhook, error) {
+	v := []*webhooks.Webhook{}
+	err := s.db.All(&v)
+	if err == storm.ErrNotFound {
+		return v, nil
+	}
+
+	return v, err
+}
+
+func (s webhooksBackend) Save(w *webhooks.Webhook) error {
+	return s.db.Save(w)
+}
+
+func (s webhooksBackend) Delete(id uint) error {
+	err := s.db.DeleteStruct(&webhooks.Webhook{ID: id})
+	if err == storm.ErrNotFound {
+		return errors.ErrNotExist
+	}
+
+	return err
+}
+
+func (s webhooksBackend) SaveDelivery(d *webhooks.Delivery) error {
+	return s.db.Save(d)
+}
+
+func (s webhooksBackend) Deliveries(webhookID uint, limit int) ([]*webhooks.Delivery, error) {
+	sel := s.db.Select(q.Eq("WebhookID", webhookID)).OrderBy("ID").Reverse()
+	if limit > 0 {
+		sel = sel.Limit(limit)
+	}
+
+	v := []*webhooks.Delivery{}
+	err := sel.Find(&v)
+	if err == storm.ErrNotFound {
+		return []*webhooks.Delivery{}, nil
+	}
+
+	return v, err
+}
+
+func (s webhooksBackend) DeleteDeliveries(webhookID uint) error {
+	err := s.db.Select(q.Eq("WebhookID", webhookID)).Delete(&webhooks.Delivery{})
+	if err == storm.ErrNotFound {
+		return nil
+	}
+
+	return err
+}
@@ -1,10 +1,19 @@
 package storage
 
 import (
+	"github.com/filebrowser/filebrowser/v2/actions"
+	"github.com/filebrowser/filebrowser/v2/audit"
 	"github.com/filebrowser/filebrowser/v2/auth"
+	"github.com/filebrowser/filebrowser/v2/groups"
+	"github.com/filebrowser/filebrowser/v2/history"
+	"github.com/filebrowser/filebrowser/v2/locks"
+	"github.com/filebrowser/filebrowser/v2/runner"
+	"github.com/filebrowser/filebrowser/v2/session"
 	"github.com/filebrowser/filebrowser/v2/settings"
 	"github.com/filebrowser/filebrowser/v2/share"
+	"github.com/filebrowser/filebrowser/v2/tokens"
 	"github.com/filebrowser/filebrowser/v2/users"
+	"github.com/filebrowser/filebrowser/v2/webhooks"
 )
 
 // Storage is a storage powered by a Backend which makes the necessary
@@ -14,4 +23,13 @@ type Storage struct {
 	Share    *share.Storage
 	Auth     *auth.Storage
 	Settings *settings.Storage
+	Tokens   *tokens.Storage
+	Sessions *session.Storage
+	Audit    *audit.Storage
+	Groups   *groups.Storage
+	Webhooks *webhooks.Storage
+	Hooks    *runner.Storage
+	Actions  *actions.Storage
+	History  *history.Storage
+	Locks    *locks.Storage
 }
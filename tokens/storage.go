@@ -0,0 +1,116 @@
+package tokens
+
+import (
+	"time"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// lastUsedResolution is the minimum time between two updates of
+// the last time a token was used, to avoid a write per request.
+const lastUsedResolution = time.Minute
+
+// StorageBackend is the interface to implement for a tokens storage.
+type StorageBackend interface {
+	GetByID(id uint) (*Token, error)
+	GetByHash(hash string) (*Token, error)
+	FindByUserID(id uint) ([]*Token, error)
+	Save(t *Token) error
+	UpdateLastUsed(t *Token) error
+	Delete(id uint) error
+}
+
+// Storage is a tokens storage.
+type Storage struct {
+	back StorageBackend
+}
+
+// NewStorage creates a tokens storage from a backend.
+func NewStorage(back StorageBackend) *Storage {
+	return &Storage{back: back}
+}
+
+// Create generates and saves a new token for the user. It returns the
+// token itself, which can't be recovered afterwards.
+func (s *Storage) Create(userID uint, name string, expire int64, perm *users.Permissions) (string, *Token, error) {
+	raw, hash, err := Generate()
+	if err != nil {
+		return "", nil, err
+	}
+
+	t := &Token{
+		Hash:    hash,
+		UserID:  userID,
+		Name:    name,
+		Created: time.Now().Unix(),
+		Expire:  expire,
+		Perm:    perm,
+	}
+
+	err = s.back.Save(t)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return raw, t, nil
+}
+
+// Authenticate gets the token matching raw and records its use. It
+// returns errors.ErrNotExist if the token is unknown or expired.
+func (s *Storage) Authenticate(raw string) (*Token, error) {
+	t, err := s.back.GetByHash(Hash(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if t.Expired(now.Unix()) {
+		return nil, errors.ErrNotExist
+	}
+
+	if now.Sub(time.Unix(t.LastUsed, 0)) >= lastUsedResolution {
+		t.LastUsed = now.Unix()
+		err = s.back.UpdateLastUsed(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// Get wraps a StorageBackend.GetByID.
+func (s *Storage) Get(id uint) (*Token, error) {
+	return s.back.GetByID(id)
+}
+
+// FindByUserID wraps a StorageBackend.FindByUserID.
+func (s *Storage) FindByUserID(id uint) ([]*Token, error) {
+	return s.back.FindByUserID(id)
+}
+
+// Delete wraps a StorageBackend.Delete.
+func (s *Storage) Delete(id uint) error {
+	return s.back.Delete(id)
+}
+
+// DeleteByUserID deletes all the tokens of a user.
+func (s *Storage) DeleteByUserID(id uint) error {
+	list, err := s.back.FindByUserID(id)
+	if err == errors.ErrNotExist {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, t := range list {
+		if err := s.back.Delete(t.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
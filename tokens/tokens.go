@@ -0,0 +1,81 @@
+package tokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	"github.com/filebrowser/filebrowser/v2/expiry"
+	"github.com/filebrowser/filebrowser/v2/users"
+)
+
+// Prefix is prepended to every personal access token so they can be
+// told apart from the JWTs.
+const Prefix = "fbpat_"
+
+const tokenSize = 32
+
+// Token is a long lived personal access token. Only the hash of the
+// token is stored.
+type Token struct {
+	ID       uint               `json:"id" storm:"id,increment"`
+	Hash     string             `json:"hash" storm:"unique"`
+	UserID   uint               `json:"userID" storm:"index"`
+	Name     string             `json:"name"`
+	Created  int64              `json:"created"`
+	LastUsed int64              `json:"lastUsed"`
+	Expire   int64              `json:"expire"`
+	Perm     *users.Permissions `json:"perm"`
+}
+
+// Expired checks if the token has an expiration date that has
+// already passed at the given unix time.
+func (t *Token) Expired(now int64) bool {
+	return expiry.Passed(t.Expire, now)
+}
+
+// Permissions returns the permissions granted by the token, which are
+// never broader than the permissions of its user.
+func (t *Token) Permissions(p users.Permissions) users.Permissions {
+	if t.Perm == nil {
+		return p
+	}
+
+	return users.Permissions{
+		Admin:    p.Admin && t.Perm.Admin,
+		Execute:  p.Execute && t.Perm.Execute,
+		Create:   p.Create && t.Perm.Create,
+		Rename:   p.Rename && t.Perm.Rename,
+		Modify:   p.Modify && t.Perm.Modify,
+		Delete:   p.Delete && t.Perm.Delete,
+		Share:    p.Share && t.Perm.Share,
+		Download: p.Download && t.Perm.Download,
+	}
+}
+
+// Generate generates a new random token. It returns the token, which
+// must be given to the user, and its hash, which must be stored.
+func Generate() (raw, hash string, err error) {
+	b := make([]byte, tokenSize)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", "", err
+	}
+
+	raw = Prefix + base64.RawURLEncoding.EncodeToString(b)
+	return raw, Hash(raw), nil
+}
+
+// Hash hashes a token. The tokens are random enough for a plain
+// SHA-256 to be used, which allows looking them up by hash.
+func Hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsToken checks if a string looks like a personal access token.
+func IsToken(s string) bool {
+	return strings.HasPrefix(s, Prefix)
+}
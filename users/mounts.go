@@ -0,0 +1,284 @@
+package users
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+	"github.com/filebrowser/filebrowser/v2/fileutils"
+)
+
+// Mount is a folder of the server shown to the user as a top-level
+// folder of its scope. Path can be relative to the root of the server,
+// the same way as the scope.
+type Mount struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	ReadOnly bool   `json:"readOnly"`
+}
+
+// CheckMounts verifies that the mounts have distinct names that can be
+// used as folder names.
+func CheckMounts(mounts []Mount) error {
+	names := map[string]bool{}
+	for _, m := range mounts {
+		if m.Name == "" || m.Name == "." || m.Name == ".." ||
+			strings.ContainsAny(m.Name, `/\`) || m.Path == "" || names[m.Name] {
+			return errors.ErrInvalidMount
+		}
+
+		names[m.Name] = true
+	}
+
+	return nil
+}
+
+func basePathFs(baseScope, scope string) *afero.BasePathFs {
+	if !filepath.IsAbs(scope) {
+		scope = filepath.Join(baseScope, scope)
+	}
+
+	return afero.NewBasePathFs(afero.NewOsFs(), scope).(*afero.BasePathFs)
+}
+
+type mountPoint struct {
+	fs       afero.Fs
+	base     *afero.BasePathFs
+	readOnly bool
+}
+
+// mountFs is a file system made of the scope of the user, in which the
+// mounts are added as top-level folders. They hide the folders of the
+// scope that have the same name.
+type mountFs struct {
+	root   *afero.BasePathFs
+	mounts map[string]*mountPoint
+	names  []string
+}
+
+func newMountFs(baseScope, scope string, mounts []Mount) *mountFs {
+	m := &mountFs{
+		root:   basePathFs(baseScope, scope),
+		mounts: map[string]*mountPoint{},
+	}
+
+	for _, mount := range mounts {
+		base := basePathFs(baseScope, mount.Path)
+		point := &mountPoint{fs: base, base: base, readOnly: mount.ReadOnly}
+		if mount.ReadOnly {
+			point.fs = afero.NewReadOnlyFs(base)
+		}
+
+		m.mounts[mount.Name] = point
+		m.names = append(m.names, mount.Name)
+	}
+
+	sort.Strings(m.names)
+	return m
+}
+
+// route gets the file system a path belongs to, the path in that file
+// system and the name of the mount, if it belongs to one.
+func (m *mountFs) route(name string) (afero.Fs, string, string) {
+	name = path.Clean("/" + filepath.ToSlash(name))
+	parts := strings.SplitN(strings.TrimPrefix(name, "/"), "/", 2) //nolint:mnd
+
+	point, ok := m.mounts[parts[0]]
+	if !ok {
+		return m.root, name, ""
+	}
+
+	rel := "/"
+	if len(parts) == 2 { //nolint:mnd
+		rel += parts[1]
+	}
+
+	return point.fs, rel, parts[0]
+}
+
+func (m *mountFs) isMountPoint(name string) bool {
+	_, rel, mount := m.route(name)
+	return mount != "" && rel == "/"
+}
+
+// fullPath gets the path of a file on the server.
+func (m *mountFs) fullPath(name string) string {
+	_, rel, mount := m.route(name)
+	if mount == "" {
+		return afero.FullBaseFsPath(m.root, rel)
+	}
+
+	return afero.FullBaseFsPath(m.mounts[mount].base, rel)
+}
+
+func (m *mountFs) Name() string {
+	return "MountFs"
+}
+
+func (m *mountFs) Create(name string) (afero.File, error) {
+	fs, rel, _ := m.route(name)
+	return fs.Create(rel)
+}
+
+func (m *mountFs) Mkdir(name string, perm os.FileMode) error {
+	fs, rel, _ := m.route(name)
+	return fs.Mkdir(rel, perm)
+}
+
+func (m *mountFs) MkdirAll(name string, perm os.FileMode) error {
+	fs, rel, _ := m.route(name)
+	return fs.MkdirAll(rel, perm)
+}
+
+func (m *mountFs) Open(name string) (afero.File, error) {
+	return m.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (m *mountFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	fs, rel, mount := m.route(name)
+	file, err := fs.OpenFile(rel, flag, perm)
+	if err != nil || mount != "" || rel != "/" {
+		return file, err
+	}
+
+	return &rootDir{File: file, fs: m}, nil
+}
+
+func (m *mountFs) Remove(name string) error {
+	if m.isMountPoint(name) {
+		return syscall.EPERM
+	}
+
+	fs, rel, _ := m.route(name)
+	return fs.Remove(rel)
+}
+
+func (m *mountFs) RemoveAll(name string) error {
+	if m.isMountPoint(name) {
+		return syscall.EPERM
+	}
+
+	fs, rel, _ := m.route(name)
+	return fs.RemoveAll(rel)
+}
+
+// Rename renames a file. Since a file can't be renamed from a mount
+// to another, it is copied and then removed, which can't be done from
+// the read-only mounts.
+func (m *mountFs) Rename(oldname, newname string) error {
+	if m.isMountPoint(oldname) || m.isMountPoint(newname) {
+		return syscall.EPERM
+	}
+
+	oldfs, oldrel, oldmount := m.route(oldname)
+	_, newrel, newmount := m.route(newname)
+	if oldmount == newmount {
+		return oldfs.Rename(oldrel, newrel)
+	}
+
+	if point, ok := m.mounts[oldmount]; ok && point.readOnly {
+		return syscall.EPERM
+	}
+
+	err := fileutils.Copy(m, oldname, newname)
+	if err != nil {
+		return err
+	}
+
+	return m.RemoveAll(oldname)
+}
+
+func (m *mountFs) Stat(name string) (os.FileInfo, error) {
+	fs, rel, mount := m.route(name)
+	info, err := fs.Stat(rel)
+	if err != nil || mount == "" || rel != "/" {
+		return info, err
+	}
+
+	return &mountInfo{FileInfo: info, name: mount}, nil
+}
+
+func (m *mountFs) Chmod(name string, mode os.FileMode) error {
+	fs, rel, _ := m.route(name)
+	return fs.Chmod(rel, mode)
+}
+
+func (m *mountFs) Chtimes(name string, atime, mtime time.Time) error {
+	fs, rel, _ := m.route(name)
+	return fs.Chtimes(rel, atime, mtime)
+}
+
+// mountInfo is the information of the root of a mount, which is named
+// after the mount.
+type mountInfo struct {
+	os.FileInfo
+	name string
+}
+
+func (i *mountInfo) Name() string {
+	return i.name
+}
+
+// rootDir is the root folder of a mountFs. Its content is the one of the
+// scope, plus the mounts, which are all listed by the first read.
+type rootDir struct {
+	afero.File
+	fs   *mountFs
+	read bool
+}
+
+func (d *rootDir) Readdir(count int) ([]os.FileInfo, error) {
+	if d.read {
+		if count > 0 {
+			return nil, io.EOF
+		}
+		return []os.FileInfo{}, nil
+	}
+
+	infos, err := d.File.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	d.read = true
+	list := []os.FileInfo{}
+	for _, info := range infos {
+		if _, ok := d.fs.mounts[info.Name()]; !ok {
+			list = append(list, info)
+		}
+	}
+
+	for _, name := range d.fs.names {
+		info, err := d.fs.Stat("/" + name)
+		if err != nil {
+			// The folder of the mount doesn't exist (anymore).
+			continue
+		}
+
+		list = append(list, info)
+	}
+
+	return list, nil
+}
+
+func (d *rootDir) Readdirnames(count int) ([]string, error) {
+	infos, err := d.Readdir(count)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+
+	return names, nil
+}
@@ -0,0 +1,144 @@
+package users
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+// newTestMountFs creates a mountFs whose scope, "scope", has the mounts
+// "docs" and "archive", which is read-only, in a temporary folder.
+func newTestMountFs(t *testing.T) (*mountFs, string) {
+	dir, err := ioutil.TempDir(os.TempDir(), "filebrowser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for _, name := range []string{"scope/docs", "docs", "archive"} {
+		if err = os.MkdirAll(filepath.Join(dir, name), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return newMountFs(dir, "scope", []Mount{
+		{Name: "docs", Path: "docs"},
+		{Name: "archive", Path: filepath.Join(dir, "archive"), ReadOnly: true},
+	}), dir
+}
+
+func TestMountFsPaths(t *testing.T) {
+	m, dir := newTestMountFs(t)
+
+	tests := []struct {
+		name, full string
+	}{
+		{"/", filepath.Join(dir, "scope")},
+		{"/a.txt", filepath.Join(dir, "scope", "a.txt")},
+		{"/docs", filepath.Join(dir, "docs")},
+		{"/docs/a/b.txt", filepath.Join(dir, "docs", "a", "b.txt")},
+		{"docs/../archive/a.txt", filepath.Join(dir, "archive", "a.txt")},
+		{"/docsx/a.txt", filepath.Join(dir, "scope", "docsx", "a.txt")},
+		{"/../../docs", filepath.Join(dir, "docs")},
+		{"/archive/../../etc", filepath.Join(dir, "scope", "etc")},
+	}
+
+	for _, tt := range tests {
+		if got := m.fullPath(tt.name); got != tt.full {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.full)
+		}
+	}
+
+	for name, want := range map[string]bool{"/docs": true, "/docs/": true, "/docs/a": false, "/": false} {
+		if got := m.isMountPoint(name); got != want {
+			t.Errorf("%s: mount point is %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestMountFsRootDir(t *testing.T) {
+	m, dir := newTestMountFs(t)
+	err := ioutil.WriteFile(filepath.Join(dir, "scope", "a.txt"), []byte("a"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := afero.ReadDir(m, "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, info := range names {
+		got = append(got, info.Name())
+	}
+	sort.Strings(got)
+
+	// The docs folder of the scope is hidden by the mount.
+	want := []string{"a.txt", "archive", "docs"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestMountFsReadOnly(t *testing.T) {
+	m, dir := newTestMountFs(t)
+	err := ioutil.WriteFile(filepath.Join(dir, "archive", "a.txt"), []byte("a"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = afero.WriteFile(m, "/archive/b.txt", []byte("b"), 0600); err == nil {
+		t.Error("wrote in the read-only mount")
+	}
+
+	if err = m.Remove("/archive/a.txt"); err == nil {
+		t.Error("removed a file of the read-only mount")
+	}
+
+	if err = m.Rename("/archive/a.txt", "/archive/b.txt"); err == nil {
+		t.Error("renamed a file of the read-only mount")
+	}
+
+	// The file would be copied, but not removed.
+	if err = m.Rename("/archive/a.txt", "/docs/a.txt"); !os.IsPermission(err) {
+		t.Errorf("rename out of the read-only mount: got error %v", err)
+	}
+
+	if ok, _ := afero.Exists(m, "/docs/a.txt"); ok {
+		t.Error("the file was copied out of the read-only mount")
+	}
+
+	if ok, _ := afero.Exists(m, "/archive/a.txt"); !ok {
+		t.Error("the file of the read-only mount was removed")
+	}
+}
+
+func TestMountFsRenameAcross(t *testing.T) {
+	m, _ := newTestMountFs(t)
+	err := afero.WriteFile(m, "/docs/a.txt", []byte("a"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = m.Rename("/docs/a.txt", "/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, _ := afero.Exists(m, "/docs/a.txt"); ok {
+		t.Error("the file wasn't removed from the mount")
+	}
+
+	content, err := afero.ReadFile(m, "/a.txt")
+	if err != nil || string(content) != "a" {
+		t.Errorf("got content %q and error %v", content, err)
+	}
+
+	if err = m.Rename("/docs", "/other"); !os.IsPermission(err) {
+		t.Errorf("rename of the mount point: got error %v", err)
+	}
+}
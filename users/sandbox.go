@@ -0,0 +1,60 @@
+package users
+
+import (
+	"path/filepath"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+// DefaultSandboxEnv are the environment variables of the server the
+// sandboxed commands inherit when no allowlist is set.
+var DefaultSandboxEnv = []string{"PATH", "LANG", "TZ"}
+
+// Sandbox restricts the commands and the hooks run for a user. The
+// zero values leave the matching restriction unset.
+type Sandbox struct {
+	Enabled bool `json:"enabled"`
+	// UID and GID are the credentials the commands are run with,
+	// instead of the ones of the server, which must then be root. The
+	// commands have no supplementary groups. A UID needs a GID, else
+	// the commands would keep the group of the server.
+	UID uint32 `json:"uid"`
+	GID uint32 `json:"gid"`
+	// Env is the allowlist of the environment variables inherited from
+	// the server. The variables set by File Browser are always given.
+	Env []string `json:"env"`
+	// Root is the directory the commands are chrooted in. It must
+	// contain the scope of the user and the binaries of the commands.
+	Root string `json:"root"`
+	// CPUTime is the limit of CPU time, in seconds, Memory the limit
+	// of address space, in bytes, and OpenFiles the limit of open
+	// file descriptors of each process.
+	CPUTime   uint64 `json:"cpuTime"`
+	Memory    uint64 `json:"memory"`
+	OpenFiles uint64 `json:"openFiles"`
+	// Isolate runs the commands in new mount, PID, IPC, UTS and network
+	// namespaces, leaving them without network access. Linux only.
+	Isolate bool `json:"isolate"`
+}
+
+// Clean cleans up a sandbox before it is saved and checks it.
+func (s *Sandbox) Clean() error {
+	if s.Env == nil {
+		s.Env = append([]string{}, DefaultSandboxEnv...)
+	}
+
+	if s.Root != "" {
+		s.Root = filepath.Clean(s.Root)
+	}
+
+	return s.Check()
+}
+
+// Check verifies that the sandbox doesn't set a UID without a GID.
+func (s *Sandbox) Check() error {
+	if s.UID != 0 && s.GID == 0 {
+		return errors.ErrInvalidSandbox
+	}
+
+	return nil
+}
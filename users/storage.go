@@ -46,6 +46,29 @@ func (s *Storage) Get(baseScope string, id interface{}) (user *User, err error)
 	return
 }
 
+// GetByOIDC gets the user linked to the OpenID Connect account with the
+// given issuer and subject. It returns errors.ErrNotExist if there's none.
+func (s *Storage) GetByOIDC(baseScope, issuer, subject string) (*User, error) {
+	users, err := s.back.Gets()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		if user.OIDCIssuer != issuer || user.OIDCSubject != subject {
+			continue
+		}
+
+		if err := user.Clean(baseScope); err != nil { //nolint:shadow
+			return nil, err
+		}
+
+		return user, nil
+	}
+
+	return nil, errors.ErrNotExist
+}
+
 // Gets gets a list of all users.
 func (s *Storage) Gets(baseScope string) ([]*User, error) {
 	users, err := s.back.Gets()
@@ -86,6 +109,10 @@ func (s *Storage) Save(user *User) error {
 		return err
 	}
 
+	if user.PasswordChanged == 0 {
+		user.PasswordChanged = time.Now().Unix()
+	}
+
 	return s.back.Save(user)
 }
 
@@ -105,6 +132,35 @@ func (s *Storage) Delete(id interface{}) (err error) {
 	return
 }
 
+// RemoveGroup removes the group from the groups of every user,
+// so that its ID can be reused.
+func (s *Storage) RemoveGroup(id uint) error {
+	users, err := s.back.Gets()
+	if err != nil && err != errors.ErrNotExist {
+		return err
+	}
+
+	for _, user := range users {
+		if !user.InGroup(id) {
+			continue
+		}
+
+		groups := []uint{}
+		for _, g := range user.Groups {
+			if g != id {
+				groups = append(groups, g)
+			}
+		}
+
+		user.Groups = groups
+		if err := s.Update(user, "Groups"); err != nil { //nolint:shadow
+			return err
+		}
+	}
+
+	return nil
+}
+
 // LastUpdate gets the timestamp for the last update of an user.
 func (s *Storage) LastUpdate(id uint) int64 {
 	s.mux.RLock()
@@ -0,0 +1,153 @@
+package users
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	totpPeriod      = 30
+	totpDigits      = 6
+	totpSkew        = 1
+	totpSecretSize  = 20
+	recoveryCodeLen = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret generates a new base32 encoded TOTP secret.
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, totpSecretSize)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI returns the otpauth URI used by the authenticator apps
+// to enrol a secret.
+func TOTPURI(issuer, account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("period", strconv.Itoa(totpPeriod))
+	query.Set("digits", strconv.Itoa(totpDigits))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// CheckTOTP checks if a code is valid for the secret at the given time,
+// as described in RFC 6238. One step of clock skew is tolerated. The
+// codes of the time steps up to last are rejected, so that a code can
+// only be used once. It returns the time step of the code.
+func CheckTOTP(secret, code string, t time.Time, last int64) (int64, bool) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	step := t.Unix() / totpPeriod
+	for i := int64(-totpSkew); i <= totpSkew; i++ {
+		if step+i <= last {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, uint64(step+i))), []byte(code)) == 1 {
+			return step + i, true
+		}
+	}
+
+	return 0, false
+}
+
+func totpCode(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:]) //nolint:errcheck
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, see RFC 4226 section 5.3.
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000) //nolint:mnd
+}
+
+// GenerateRecoveryCodes generates n recovery codes. It returns the
+// codes to show to the user and their hashes to store.
+func GenerateRecoveryCodes(n int) (codes, hashes []string, err error) {
+	codes = make([]string, n)
+	hashes = make([]string, n)
+
+	for i := range codes {
+		b := make([]byte, recoveryCodeLen)
+		_, err = rand.Read(b)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(totpEncoding.EncodeToString(b))[:recoveryCodeLen]
+		codes[i] = code[:5] + "-" + code[5:]
+
+		hashes[i], err = HashPwd(codes[i])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return codes, hashes, nil
+}
+
+// CheckSecondFactor checks a TOTP code or, failing that, a recovery code.
+// The codes can only be used once: the time step of the TOTP code is
+// recorded in TOTPLastStep and the recovery code is removed from the user,
+// who must then be saved by the caller. It returns whether the code was
+// valid and whether it was a recovery code.
+func (u *User) CheckSecondFactor(code string) (ok, recovery bool) {
+	code = strings.TrimSpace(code)
+
+	if step, ok := CheckTOTP(u.TOTPSecret, code, time.Now(), u.TOTPLastStep); ok {
+		u.TOTPLastStep = step
+		return true, false
+	}
+
+	code = strings.ToLower(code)
+	for i, hash := range u.RecoveryCodes {
+		if CheckPwd(code, hash) {
+			u.RecoveryCodes = append(u.RecoveryCodes[:i:i], u.RecoveryCodes[i+1:]...)
+			return true, true
+		}
+	}
+
+	return false, false
+}
+
+// ResetTOTP disables the two-factor authentication of the user.
+func (u *User) ResetTOTP() {
+	u.TOTPEnabled = false
+	u.TOTPSecret = ""
+	u.RecoveryCodes = []string{}
+	u.TOTPLastStep = 0
+}
+
+// HideSecrets blanks the secrets of the user so it can be
+// sent to the client.
+func (u *User) HideSecrets() {
+	u.Password = ""
+	u.TOTPSecret = ""
+	u.RecoveryCodes = nil
+}
@@ -0,0 +1,140 @@
+package users
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the secret of the test vectors of RFC 6238,
+// "12345678901234567890", encoded in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCheckTOTP(t *testing.T) {
+	// The codes are the last 6 digits of the ones of RFC 6238.
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		step, ok := CheckTOTP(rfcSecret, tt.code, time.Unix(tt.unix, 0), 0)
+		if !ok || step != tt.unix/totpPeriod {
+			t.Errorf("%d: got step %d and %v, want step %d", tt.unix, step, ok, tt.unix/totpPeriod)
+		}
+	}
+
+	if _, ok := CheckTOTP(strings.ToLower(rfcSecret), "287082", time.Unix(59, 0), 0); !ok {
+		t.Error("the lower case secret is rejected")
+	}
+
+	for _, code := range []string{"287083", "28708", "2870820", ""} {
+		if _, ok := CheckTOTP(rfcSecret, code, time.Unix(59, 0), 0); ok {
+			t.Errorf("the code %q is accepted", code)
+		}
+	}
+
+	if _, ok := CheckTOTP("not base32!", "287082", time.Unix(59, 0), 0); ok {
+		t.Error("the code of an invalid secret is accepted")
+	}
+}
+
+func TestCheckTOTPSkew(t *testing.T) {
+	// The code of the step 1, from 30 to 59.
+	const code = "287082"
+
+	tests := []struct {
+		unix int64
+		ok   bool
+	}{
+		{-31, false},
+		{0, true},
+		{30, true},
+		{59, true},
+		{89, true},
+		{90, false},
+	}
+
+	for _, tt := range tests {
+		step, ok := CheckTOTP(rfcSecret, code, time.Unix(tt.unix, 0), 0)
+		if ok != tt.ok || (ok && step != 1) {
+			t.Errorf("at %d: got step %d and %v, want %v", tt.unix, step, ok, tt.ok)
+		}
+	}
+}
+
+func TestCheckTOTPReuse(t *testing.T) {
+	now := time.Unix(59, 0)
+	step, ok := CheckTOTP(rfcSecret, "287082", now, 0)
+	if !ok {
+		t.Fatal("the code is rejected")
+	}
+
+	// The code can't be used again, even later within the skew.
+	if _, ok = CheckTOTP(rfcSecret, "287082", now, step); ok {
+		t.Error("the code is accepted twice")
+	}
+
+	if _, ok = CheckTOTP(rfcSecret, "287082", now.Add(totpPeriod*time.Second), step); ok {
+		t.Error("the code is accepted twice in the next step")
+	}
+
+	// Nor can the code of an earlier step, within the skew.
+	if _, ok = CheckTOTP(rfcSecret, totpCode([]byte("12345678901234567890"), 0), now, step); ok {
+		t.Error("the code of an earlier step is accepted")
+	}
+
+	next := totpCode([]byte("12345678901234567890"), uint64(step+1))
+	if got, ok := CheckTOTP(rfcSecret, next, now, step); !ok || got != step+1 {
+		t.Errorf("the code of the next step: got step %d and %v", got, ok)
+	}
+}
+
+func TestCheckSecondFactor(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u := &User{TOTPSecret: secret, RecoveryCodes: hashes}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := totpCode(key, uint64(time.Now().Unix()/totpPeriod))
+	if ok, recovery := u.CheckSecondFactor(" " + code + " "); !ok || recovery {
+		t.Fatalf("the TOTP code: got %v and %v", ok, recovery)
+	}
+
+	if u.TOTPLastStep == 0 {
+		t.Error("the time step of the code isn't recorded")
+	}
+
+	if ok, _ := u.CheckSecondFactor(code); ok {
+		t.Error("the TOTP code is accepted twice")
+	}
+
+	if ok, recovery := u.CheckSecondFactor(strings.ToUpper(codes[1])); !ok || !recovery {
+		t.Fatalf("the recovery code: got %v and %v", ok, recovery)
+	}
+
+	if len(u.RecoveryCodes) != 1 {
+		t.Fatalf("%d recovery codes are left, want 1", len(u.RecoveryCodes))
+	}
+
+	if ok, _ := u.CheckSecondFactor(codes[1]); ok {
+		t.Error("the recovery code is accepted twice")
+	}
+}
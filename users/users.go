@@ -1,7 +1,6 @@
 package users
 
 import (
-	"path/filepath"
 	"regexp"
 
 	"github.com/spf13/afero"
@@ -31,8 +30,37 @@ type User struct {
 	Perm         Permissions   `json:"perm"`
 	Commands     []string      `json:"commands"`
 	Sorting      files.Sorting `json:"sorting"`
+	HideDotfiles bool          `json:"hideDotfiles"`
 	Fs           afero.Fs      `json:"-" yaml:"-"`
 	Rules        []rules.Rule  `json:"rules"`
+	Groups       []uint        `json:"groups"`
+	Mounts       []Mount       `json:"mounts"`
+
+	// CommandTimeout is the number of seconds the commands of the
+	// user, interactive or not, can run for, zero meaning no limit.
+	CommandTimeout int64 `json:"commandTimeout"`
+	// Sandbox restricts the commands of the user, and the hooks run
+	// for its actions. When nil, the one of its groups, if any, is used.
+	Sandbox *Sandbox `json:"sandbox"`
+
+	TOTPEnabled   bool     `json:"totpEnabled"`
+	TOTPSecret    string   `json:"totpSecret"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+	// TOTPLastStep is the time step of the last accepted TOTP code,
+	// which can't be used again.
+	TOTPLastStep int64 `json:"totpLastStep"`
+
+	FailedLogins int   `json:"failedLogins"`
+	LockedUntil  int64 `json:"lockedUntil"`
+
+	MustChangePassword bool  `json:"mustChangePassword"`
+	PasswordChanged    int64 `json:"passwordChanged"`
+
+	// OIDCIssuer and OIDCSubject identify the OpenID Connect account the
+	// user logs in with. They are set when the user is provisioned, or by
+	// an administrator to link an existing user to an account.
+	OIDCIssuer  string `json:"oidcIssuer"`
+	OIDCSubject string `json:"oidcSubject"`
 }
 
 // GetRules implements rules.Provider.
@@ -48,6 +76,9 @@ var checkableFields = []string{
 	"Commands",
 	"Sorting",
 	"Rules",
+	"Groups",
+	"Mounts",
+	"Sandbox",
 }
 
 // Clean cleans up a user and verifies if all its fields
@@ -84,24 +115,61 @@ func (u *User) Clean(baseScope string, fields ...string) error {
 			if u.Rules == nil {
 				u.Rules = []rules.Rule{}
 			}
+		case "Groups":
+			if u.Groups == nil {
+				u.Groups = []uint{}
+			}
+		case "Mounts":
+			if u.Mounts == nil {
+				u.Mounts = []Mount{}
+			}
+
+			if err := CheckMounts(u.Mounts); err != nil {
+				return err
+			}
+		case "Sandbox":
+			if u.Sandbox != nil {
+				if err := u.Sandbox.Clean(); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
 	if u.Fs == nil {
-		scope := u.Scope
+		u.ResetFs(baseScope)
+	}
 
-		if !filepath.IsAbs(scope) {
-			scope = filepath.Join(baseScope, scope)
-		}
+	return nil
+}
 
-		u.Fs = afero.NewBasePathFs(afero.NewOsFs(), scope)
+// ResetFs builds the file system of the user from its scope and its mounts.
+func (u *User) ResetFs(baseScope string) {
+	if len(u.Mounts) == 0 {
+		u.Fs = basePathFs(baseScope, u.Scope)
+		return
 	}
 
-	return nil
+	u.Fs = newMountFs(baseScope, u.Scope, u.Mounts)
+}
+
+// InGroup checks if the user is member of the group.
+func (u *User) InGroup(id uint) bool {
+	for _, g := range u.Groups {
+		if g == id {
+			return true
+		}
+	}
+
+	return false
 }
 
 // FullPath gets the full path for a user's relative path.
 func (u *User) FullPath(path string) string {
+	if fs, ok := u.Fs.(*mountFs); ok {
+		return fs.fullPath(path)
+	}
+
 	return afero.FullBaseFsPath(u.Fs.(*afero.BasePathFs), path)
 }
 
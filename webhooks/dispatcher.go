@@ -0,0 +1,257 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultBackoff     = 2 * time.Second
+	defaultTimeout     = 10 * time.Second
+
+	// workers is the number of deliveries made at once and queueSize
+	// the number of deliveries that can wait for a worker.
+	workers   = 4
+	queueSize = 1024
+)
+
+var defaultClient = &http.Client{Timeout: defaultTimeout}
+
+// Dispatcher sends the events to the webhooks in the background. The
+// failed deliveries are retried with an exponential backoff, starting
+// from Backoff, until MaxAttempts attempts were made, or until the
+// dispatcher is closed. A single dispatcher should be used: it makes a
+// bounded number of deliveries at once and queues the other ones, which
+// are dropped if the queue is full.
+type Dispatcher struct {
+	Client      *http.Client
+	MaxAttempts int
+	Backoff     time.Duration
+
+	store   *Storage
+	queue   chan delivery
+	wg      sync.WaitGroup
+	mux     sync.RWMutex
+	closed  bool
+	closing chan struct{}
+	// ctx is canceled once closing takes too long, aborting the
+	// deliveries in progress.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+type delivery struct {
+	hook    *Webhook
+	payload *Payload
+}
+
+// NewDispatcher creates a dispatcher sending the events to the
+// webhooks of the storage. It must be closed once done with.
+func NewDispatcher(store *Storage) *Dispatcher {
+	d := &Dispatcher{
+		Client:      defaultClient,
+		MaxAttempts: defaultMaxAttempts,
+		Backoff:     defaultBackoff,
+		store:       store,
+		queue:       make(chan delivery, queueSize),
+		closing:     make(chan struct{}),
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+
+	d.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.work()
+	}
+
+	return d
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	for j := range d.queue {
+		if d.ctx.Err() != nil {
+			d.record(&Delivery{
+				WebhookID: j.hook.ID,
+				Event:     j.payload.Event,
+				Time:      time.Now().Unix(),
+				Error:     "dispatcher closed",
+			})
+			continue
+		}
+
+		d.Deliver(j.hook, j.payload)
+	}
+}
+
+// Dispatch queues the payload to be sent to the webhooks that
+// want its event.
+func (d *Dispatcher) Dispatch(p *Payload) {
+	hooks, err := d.store.ForEvent(p.Event)
+	if err != nil {
+		log.Printf("webhooks: %v", err)
+		return
+	}
+
+	// The payload is shared by the deliveries.
+	if p.Time == 0 {
+		p.Time = time.Now().Unix()
+	}
+
+	d.mux.RLock()
+	defer d.mux.RUnlock()
+
+	if d.closed {
+		log.Printf("webhooks: %s event not sent: dispatcher closed", p.Event)
+		return
+	}
+
+	for _, w := range hooks {
+		select {
+		case d.queue <- delivery{hook: w, payload: p}:
+		default:
+			d.record(&Delivery{
+				WebhookID: w.ID,
+				Event:     p.Event,
+				Time:      p.Time,
+				Error:     "delivery queue full",
+			})
+		}
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be
+// sent, without retrying the failed ones. Once ctx is done, the
+// deliveries in progress are aborted and the ones still queued are
+// recorded as failed.
+func (d *Dispatcher) Close(ctx context.Context) {
+	d.mux.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+		close(d.closing)
+	}
+	d.mux.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+	}
+
+	d.cancel()
+}
+
+// Try sends the payload to a webhook once, without retrying, and
+// records it in the delivery log.
+func (d *Dispatcher) Try(w *Webhook, p *Payload) *Delivery {
+	return d.deliver(w, p, 1)
+}
+
+// Deliver sends the payload to a webhook, retrying until it succeeds or
+// runs out of attempts. Every attempt is recorded in the delivery log.
+func (d *Dispatcher) Deliver(w *Webhook, p *Payload) *Delivery {
+	return d.deliver(w, p, d.MaxAttempts)
+}
+
+func (d *Dispatcher) deliver(w *Webhook, p *Payload, attempts int) *Delivery {
+	delivery := &Delivery{
+		WebhookID: w.ID,
+		Event:     p.Event,
+		Time:      time.Now().Unix(),
+	}
+
+	if p.Time == 0 {
+		p.Time = delivery.Time
+	}
+
+	// Saving it first gives it an id to send.
+	d.record(delivery)
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		delivery.Error = err.Error()
+		d.record(delivery)
+		return delivery
+	}
+
+	backoff := d.Backoff
+	for delivery.Attempts < attempts {
+		if delivery.Attempts > 0 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-timer.C:
+			case <-d.closing:
+				timer.Stop()
+				return delivery
+			}
+			backoff *= 2
+		}
+
+		delivery.Attempts++
+		delivery.StatusCode, err = d.send(w, delivery, body)
+		delivery.Delivered = err == nil
+		delivery.Error = ""
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+
+		d.record(delivery)
+		if delivery.Delivered {
+			break
+		}
+	}
+
+	return delivery
+}
+
+func (d *Dispatcher) send(w *Webhook, delivery *Delivery, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "File Browser")
+	req.Header.Set("X-Filebrowser-Event", delivery.Event)
+	req.Header.Set("X-Filebrowser-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+
+	res, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// The body is read for the connection to be reused.
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(res.Body, 64*1024)) //nolint:mnd
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	return res.StatusCode, nil
+}
+
+func (d *Dispatcher) record(delivery *Delivery) {
+	if err := d.store.RecordDelivery(delivery); err != nil {
+		log.Printf("webhooks: %v", err)
+	}
+}
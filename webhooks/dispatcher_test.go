@@ -0,0 +1,324 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+// memBackend is an in memory StorageBackend for the tests.
+type memBackend struct {
+	mux        sync.Mutex
+	hooks      []*Webhook
+	deliveries map[uint]*Delivery
+}
+
+func newTestStorage(hooks ...*Webhook) *Storage {
+	for i, w := range hooks {
+		w.ID = uint(i + 1)
+	}
+
+	return NewStorage(&memBackend{hooks: hooks, deliveries: map[uint]*Delivery{}})
+}
+
+func (m *memBackend) Get(id uint) (*Webhook, error) {
+	for _, w := range m.hooks {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+
+	return nil, errors.ErrNotExist
+}
+
+func (m *memBackend) Gets() ([]*Webhook, error) {
+	return m.hooks, nil
+}
+
+func (m *memBackend) Save(w *Webhook) error {
+	m.hooks = append(m.hooks, w)
+	return nil
+}
+
+func (m *memBackend) Delete(id uint) error {
+	return nil
+}
+
+func (m *memBackend) SaveDelivery(d *Delivery) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if d.ID == 0 {
+		d.ID = uint(len(m.deliveries) + 1)
+	}
+
+	c := *d
+	m.deliveries[d.ID] = &c
+	return nil
+}
+
+func (m *memBackend) Deliveries(webhookID uint, limit int) ([]*Delivery, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	list := []*Delivery{}
+	for _, d := range m.deliveries {
+		if d.WebhookID == webhookID {
+			list = append(list, d)
+		}
+	}
+
+	return list, nil
+}
+
+func (m *memBackend) DeleteDeliveries(webhookID uint) error {
+	return nil
+}
+
+// receiver is a webhook endpoint answering with the given statuses,
+// then with 200 OK.
+type receiver struct {
+	*httptest.Server
+
+	mux      sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	rec := &receiver{statuses: statuses}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		rec.mux.Lock()
+		defer rec.mux.Unlock()
+
+		rec.requests = append(rec.requests, r)
+		rec.bodies = append(rec.bodies, body)
+
+		if len(rec.statuses) > 0 {
+			w.WriteHeader(rec.statuses[0])
+			rec.statuses = rec.statuses[1:]
+		}
+	}))
+
+	t.Cleanup(rec.Close)
+	return rec
+}
+
+func (rec *receiver) count() int {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	return len(rec.requests)
+}
+
+func (rec *receiver) received() ([]*http.Request, [][]byte) {
+	rec.mux.Lock()
+	defer rec.mux.Unlock()
+	return rec.requests, rec.bodies
+}
+
+func newTestDispatcher(t *testing.T, store *Storage) *Dispatcher {
+	d := NewDispatcher(store)
+	d.Backoff = time.Millisecond
+	t.Cleanup(func() { d.Close(context.Background()) })
+	return d
+}
+
+func TestDispatcherSignature(t *testing.T) {
+	rec := newReceiver(t)
+	hook := &Webhook{URL: rec.URL, Secret: "secret"}
+	d := newTestDispatcher(t, newTestStorage(hook))
+
+	delivery := d.Deliver(hook, &Payload{Event: "upload", Path: "/a.txt"})
+	if !delivery.Delivered || delivery.Attempts != 1 || delivery.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected delivery %+v", delivery)
+	}
+
+	requests, bodies := rec.received()
+	r, body := requests[0], bodies[0]
+	if got, want := r.Header.Get(SignatureHeader), Sign("secret", body); got != want {
+		t.Errorf("got signature %q, want %q", got, want)
+	}
+
+	if got := r.Header.Get("X-Filebrowser-Event"); got != "upload" {
+		t.Errorf("got event header %q, want %q", got, "upload")
+	}
+
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Event != "upload" || p.Path != "/a.txt" || p.Time == 0 {
+		t.Errorf("unexpected payload %+v", p)
+	}
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	rec := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+	hook := &Webhook{URL: rec.URL, Secret: "secret"}
+	store := newTestStorage(hook)
+	d := newTestDispatcher(t, store)
+
+	delivery := d.Deliver(hook, &Payload{Event: "upload"})
+	if !delivery.Delivered || delivery.Attempts != 3 || delivery.Error != "" {
+		t.Fatalf("unexpected delivery %+v", delivery)
+	}
+
+	// Every attempt is sent with the same delivery id and signature.
+	requests, _ := rec.received()
+	for _, r := range requests[1:] {
+		if r.Header.Get("X-Filebrowser-Delivery") != requests[0].Header.Get("X-Filebrowser-Delivery") ||
+			r.Header.Get(SignatureHeader) != requests[0].Header.Get(SignatureHeader) {
+			t.Error("the retries differ from the first attempt")
+		}
+	}
+
+	deliveries, err := store.Deliveries(hook.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliveries) != 1 || !deliveries[0].Delivered || deliveries[0].Attempts != 3 {
+		t.Errorf("unexpected recorded deliveries %+v", deliveries)
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	rec := newReceiver(t, 500, 500, 500, 500, 500, 500)
+	hook := &Webhook{URL: rec.URL, Secret: "secret"}
+	d := newTestDispatcher(t, newTestStorage(hook))
+	d.MaxAttempts = 3
+
+	delivery := d.Deliver(hook, &Payload{Event: "upload"})
+	if delivery.Delivered || delivery.Attempts != 3 || delivery.StatusCode != 500 || delivery.Error == "" {
+		t.Fatalf("unexpected delivery %+v", delivery)
+	}
+
+	delivery = d.Try(hook, &Payload{Event: EventPing})
+	if delivery.Delivered || delivery.Attempts != 1 {
+		t.Fatalf("unexpected ping delivery %+v", delivery)
+	}
+
+	if n := rec.count(); n != 4 {
+		t.Errorf("got %d requests, want 4", n)
+	}
+}
+
+func TestDispatcherCloseDrains(t *testing.T) {
+	rec := newReceiver(t)
+	hooks := []*Webhook{
+		{URL: rec.URL, Secret: "a"},
+		{URL: rec.URL, Secret: "b", Events: []string{"delete"}},
+	}
+	d := NewDispatcher(newTestStorage(hooks...))
+
+	const events = 20
+	for i := 0; i < events; i++ {
+		d.Dispatch(&Payload{Event: "upload"})
+	}
+
+	d.Close(context.Background())
+
+	// The second webhook doesn't want the event.
+	if n := rec.count(); n != events {
+		t.Errorf("got %d requests, want %d", n, events)
+	}
+
+	// The events dispatched once closed are dropped.
+	d.Dispatch(&Payload{Event: "upload"})
+	if n := rec.count(); n != events {
+		t.Errorf("got %d requests after closing, want %d", n, events)
+	}
+}
+
+func TestDispatcherCloseStopsRetries(t *testing.T) {
+	rec := newReceiver(t, 500, 500, 500, 500, 500)
+	hook := &Webhook{URL: rec.URL, Secret: "secret"}
+	store := newTestStorage(hook)
+	d := NewDispatcher(store)
+	d.Backoff = time.Hour
+
+	d.Dispatch(&Payload{Event: "upload"})
+	for rec.count() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		d.Close(context.Background())
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("closing waited for the retries")
+	}
+
+	deliveries, err := store.Deliveries(hook.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliveries) != 1 || deliveries[0].Delivered || deliveries[0].Attempts != 1 {
+		t.Errorf("unexpected recorded deliveries %+v", deliveries)
+	}
+}
+
+func TestDispatcherCloseDeadline(t *testing.T) {
+	unblock := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-unblock:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(unblock) })
+
+	hook := &Webhook{URL: srv.URL, Secret: "secret"}
+	store := newTestStorage(hook)
+	d := NewDispatcher(store)
+
+	const events = 10
+	for i := 0; i < events; i++ {
+		d.Dispatch(&Payload{Event: "upload"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	d.Close(ctx)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("closing took %v", elapsed)
+	}
+
+	deliveries, err := store.Deliveries(hook.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deliveries) != events {
+		t.Fatalf("got %d recorded deliveries, want %d", len(deliveries), events)
+	}
+
+	for _, delivery := range deliveries {
+		if delivery.Delivered || delivery.Error == "" {
+			t.Errorf("unexpected delivery %+v", delivery)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package webhooks
+
+import (
+	"time"
+)
+
+// StorageBackend is the interface to implement for a webhooks storage.
+type StorageBackend interface {
+	Get(id uint) (*Webhook, error)
+	Gets() ([]*Webhook, error)
+	Save(w *Webhook) error
+	Delete(id uint) error
+	SaveDelivery(d *Delivery) error
+	Deliveries(webhookID uint, limit int) ([]*Delivery, error)
+	DeleteDeliveries(webhookID uint) error
+}
+
+// Storage is a webhooks storage.
+type Storage struct {
+	back StorageBackend
+}
+
+// NewStorage creates a webhooks storage from a backend.
+func NewStorage(back StorageBackend) *Storage {
+	return &Storage{back: back}
+}
+
+// Get gets a webhook by its id.
+func (s *Storage) Get(id uint) (*Webhook, error) {
+	return s.back.Get(id)
+}
+
+// Gets gets all the webhooks.
+func (s *Storage) Gets() ([]*Webhook, error) {
+	return s.back.Gets()
+}
+
+// ForEvent gets the webhooks the event has to be sent to.
+func (s *Storage) ForEvent(event string) ([]*Webhook, error) {
+	all, err := s.back.Gets()
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := []*Webhook{}
+	for _, w := range all {
+		if w.Wants(event) {
+			hooks = append(hooks, w)
+		}
+	}
+
+	return hooks, nil
+}
+
+// Save saves a webhook.
+func (s *Storage) Save(w *Webhook) error {
+	if err := w.Clean(); err != nil {
+		return err
+	}
+
+	if w.Created == 0 {
+		w.Created = time.Now().Unix()
+	}
+
+	return s.back.Save(w)
+}
+
+// Delete deletes a webhook and its deliveries.
+func (s *Storage) Delete(id uint) error {
+	if err := s.back.Delete(id); err != nil {
+		return err
+	}
+
+	return s.back.DeleteDeliveries(id)
+}
+
+// RecordDelivery saves the delivery of an event.
+func (s *Storage) RecordDelivery(d *Delivery) error {
+	return s.back.SaveDelivery(d)
+}
+
+// Deliveries gets the last deliveries to a webhook, the newest first.
+// A limit of zero gets all of them.
+func (s *Storage) Deliveries(webhookID uint, limit int) ([]*Delivery, error) {
+	return s.back.Deliveries(webhookID, limit)
+}
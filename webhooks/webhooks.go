@@ -0,0 +1,103 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+
+	"github.com/filebrowser/filebrowser/v2/errors"
+)
+
+// SignatureHeader is the header of the requests of the webhooks holding
+// the HMAC-SHA256 of their body, keyed with the secret of the webhook.
+const SignatureHeader = "X-Filebrowser-Signature"
+
+// EventPing is the event sent to test a webhook.
+const EventPing = "ping"
+
+// Webhook is an URL to which the events are sent. A webhook without
+// events is sent all of them.
+type Webhook struct {
+	ID      uint     `storm:"id,increment" json:"id"`
+	URL     string   `json:"url"`
+	Secret  string   `json:"secret"`
+	Events  []string `json:"events"`
+	Created int64    `json:"created"`
+}
+
+// User is the user that triggered an event.
+type User struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+// Payload is the JSON body sent to the webhooks. The paths are relative
+// to the scope of the user and Size is the size of the file, if any,
+// after the event.
+type Payload struct {
+	Event       string `json:"event"`
+	Time        int64  `json:"time"`
+	User        User   `json:"user"`
+	Path        string `json:"path"`
+	Destination string `json:"destination,omitempty"`
+	Size        int64  `json:"size"`
+}
+
+// Delivery is a record of the delivery of an event to a webhook.
+type Delivery struct {
+	ID         uint   `storm:"id,increment" json:"id"`
+	WebhookID  uint   `storm:"index" json:"webhookId"`
+	Event      string `json:"event"`
+	Time       int64  `json:"time"`
+	Attempts   int    `json:"attempts"`
+	StatusCode int    `json:"statusCode"`
+	Error      string `json:"error,omitempty"`
+	Delivered  bool   `json:"delivered"`
+}
+
+// Clean verifies the webhook and generates its secret if it has none.
+func (w *Webhook) Clean() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.ErrInvalidWebhook
+	}
+
+	if w.Secret == "" {
+		secret := make([]byte, 32) //nolint:mnd
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+
+		w.Secret = hex.EncodeToString(secret)
+	}
+
+	if w.Events == nil {
+		w.Events = []string{}
+	}
+
+	return nil
+}
+
+// Wants checks if the event has to be sent to the webhook.
+func (w *Webhook) Wants(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sign computes the signature of a body sent with a secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}